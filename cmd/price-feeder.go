@@ -24,6 +24,7 @@ import (
 
 	"price-feeder/config"
 	"price-feeder/oracle"
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/client"
 	"price-feeder/oracle/derivative"
 	"price-feeder/oracle/history"
@@ -122,41 +123,55 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 	// listen for and trap any OS signal to gracefully shutdown and exit
 	trapSignal(cancel, logger)
 
-	rpcTimeout, err := time.ParseDuration(cfg.RPC.RPCTimeout)
-	if err != nil {
-		return fmt.Errorf("failed to parse RPC timeout: %w", err)
-	}
-
-	// Gather pass via env variable || std input
-	keyringPass, err := getKeyringPassword()
-	if err != nil {
-		return err
-	}
-
 	heightPollInterval, err := time.ParseDuration(cfg.HeightPollInterval)
 	if err != nil {
 		return fmt.Errorf("failed to parse height poll interval: %w", err)
 	}
 
-	oracleClient, err := client.NewOracleClient(
-		ctx,
-		logger,
-		cfg.Account.ChainID,
-		cfg.Keyring.Backend,
-		cfg.Keyring.Dir,
-		keyringPass,
-		cfg.RPC.TMRPCEndpoint,
-		rpcTimeout,
-		cfg.Account.Address,
-		cfg.Account.Validator,
-		cfg.Account.FeeGranter,
-		cfg.RPC.GRPCEndpoint,
-		cfg.GasAdjustment,
-		cfg.GasPrices,
-		heightPollInterval,
-	)
-	if err != nil {
-		return err
+	// keyring passwords by keyring dir
+	keyringPasses := map[string]string{}
+
+	oracleClients := make([]client.OracleClient, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		rpcTimeout, err := time.ParseDuration(account.RPC.RPCTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse RPC timeout: %w", err)
+		}
+
+		// Gather pass via env variable || std input, the keyring isn't used
+		// in dry run mode
+		keyringPass, found := keyringPasses[account.Keyring.Dir]
+		if !found && !cfg.DryRun {
+			keyringPass, err = getKeyringPassword(account.Keyring.Dir)
+			if err != nil {
+				return err
+			}
+			keyringPasses[account.Keyring.Dir] = keyringPass
+		}
+
+		oracleClient, err := client.NewOracleClient(
+			ctx,
+			logger,
+			account.ChainID,
+			account.Keyring.Backend,
+			account.Keyring.Dir,
+			keyringPass,
+			account.RPC.TMRPCEndpoint,
+			rpcTimeout,
+			account.Address,
+			account.Validator,
+			account.FeeGranter,
+			account.RPC.GRPCEndpoint,
+			account.GasAdjustment,
+			account.GasPrices,
+			heightPollInterval,
+			cfg.DryRun,
+		)
+		if err != nil {
+			return err
+		}
+
+		oracleClients = append(oracleClients, oracleClient)
 	}
 
 	providerTimeout, err := time.ParseDuration(cfg.ProviderTimeout)
@@ -164,13 +179,13 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse provider timeout: %w", err)
 	}
 
-	deviations := make(map[string]sdk.Dec, len(cfg.Deviations))
+	deviations := make(map[string]types.Deviation, len(cfg.Deviations))
 	for _, deviation := range cfg.Deviations {
-		threshold, err := sdk.NewDecFromStr(deviation.Threshold)
+		filter, err := deviation.NewDeviation()
 		if err != nil {
 			return err
 		}
-		deviations[deviation.Base] = threshold
+		deviations[deviation.Base] = filter
 	}
 
 	providerMinOverrides := make(map[string]int, len(cfg.ProviderMinOverrides))
@@ -186,6 +201,116 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	aggregators := map[string]aggregator.Aggregator{}
+	for _, aggregation := range cfg.Aggregations {
+		agg, err := aggregation.NewAggregator()
+		if err != nil {
+			return err
+		}
+		for _, denom := range aggregation.Denoms {
+			_, found := aggregators[denom]
+			if found {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("aggregation already set")
+			}
+			aggregators[denom] = agg
+		}
+	}
+
+	volumeCaps := map[string]types.VolumeCap{}
+	genericProviders := cfg.GenericProviders()
+	for _, volumeCap := range cfg.VolumeCaps {
+		limit, err := volumeCap.NewVolumeCap(genericProviders)
+		if err != nil {
+			return err
+		}
+		for _, denom := range volumeCap.Denoms {
+			_, found := volumeCaps[denom]
+			if found {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("volume cap already set")
+			}
+			volumeCaps[denom] = limit
+		}
+	}
+
+	pegs := map[string]types.Peg{}
+	for _, pegConfig := range cfg.Pegs {
+		peg, err := pegConfig.NewPeg()
+		if err != nil {
+			return err
+		}
+		for _, denom := range pegConfig.Denoms {
+			_, found := pegs[denom]
+			if found {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("peg already set")
+			}
+			pegs[denom] = peg
+		}
+	}
+
+	fallbacks := map[string]types.Fallback{}
+	for _, fallbackConfig := range cfg.Fallbacks {
+		fallback, err := fallbackConfig.NewFallback()
+		if err != nil {
+			return err
+		}
+		for _, denom := range fallbackConfig.Denoms {
+			_, found := fallbacks[denom]
+			if found {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("fallback already set")
+			}
+			fallbacks[denom] = fallback
+		}
+	}
+
+	breakers := map[string]types.CircuitBreaker{}
+	for _, circuitBreaker := range cfg.CircuitBreakers {
+		breaker, err := circuitBreaker.NewCircuitBreaker()
+		if err != nil {
+			return err
+		}
+		for _, denom := range circuitBreaker.Denoms {
+			_, found := breakers[denom]
+			if found {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("circuit breaker already set")
+			}
+			breakers[denom] = breaker
+		}
+	}
+
+	var reputation types.Reputation
+	if cfg.Reputation.Enabled {
+		reputation, err = cfg.Reputation.NewReputation()
+		if err != nil {
+			return err
+		}
+	}
+
+	var health types.Health
+	if cfg.Health.Enabled {
+		health, err = cfg.Health.NewHealth()
+		if err != nil {
+			return err
+		}
+	}
+
+	var consistency types.Consistency
+	if cfg.Consistency.Enabled {
+		consistency, err = cfg.Consistency.NewConsistency()
+		if err != nil {
+			return err
+		}
+	}
+
 	endpoints := make(map[provider.Name]provider.Endpoint, len(cfg.ProviderEndpoints))
 	for _, e := range cfg.ProviderEndpoints {
 		endpoint, err := e.ToEndpoint(cfg.UrlSets)
@@ -262,26 +387,69 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 	}
 	volumeDatabase.SetMaxOpenConns(1)
 
+	var missMonitor oracle.MissMonitor
+	if cfg.MissMonitor.Enabled {
+		interval, err := time.ParseDuration(cfg.MissMonitor.Interval)
+		if err != nil {
+			return fmt.Errorf("failed to parse miss monitor interval: %w", err)
+		}
+		missMonitor = oracle.MissMonitor{
+			Interval:       interval,
+			AlertThreshold: cfg.MissMonitor.AlertThreshold,
+			AlertWebhook:   cfg.MissMonitor.AlertWebhook,
+		}
+	}
+
+	var balanceMonitor oracle.BalanceMonitor
+	if cfg.BalanceMonitor.Enabled {
+		interval, err := time.ParseDuration(cfg.BalanceMonitor.Interval)
+		if err != nil {
+			return fmt.Errorf("failed to parse balance monitor interval: %w", err)
+		}
+		balanceMonitor = oracle.BalanceMonitor{
+			Interval: interval,
+			WarnDays: cfg.BalanceMonitor.WarnDays,
+		}
+	}
+
 	oracle := oracle.New(
 		logger,
-		oracleClient,
 		providerPairs,
-		providerTimeout,
-		deviations,
-		providerMinOverrides,
-		endpoints,
-		derivatives,
-		derivativePairs,
-		derivativeSymbols,
-		cfg.Healthchecks,
-		history,
-		cfg.ContractAdresses,
-		providerWeights,
-		cfg.Decimals,
-		cfg.Periods,
-		volumeDatabase,
+		oracle.Options{
+			ProviderTimeout:      providerTimeout,
+			Deviations:           deviations,
+			ProviderMinOverrides: providerMinOverrides,
+			Endpoints:            endpoints,
+			Derivatives:          derivatives,
+			DerivativePairs:      derivativePairs,
+			DerivativeDenoms:     derivativeSymbols,
+			History:              history,
+			ContractAddresses:    cfg.ContractAdresses,
+			ProviderWeights:      providerWeights,
+			Aggregators:          aggregators,
+			VolumeCaps:           volumeCaps,
+			AuditRetention:       cfg.AuditRetention,
+			Breakers:             breakers,
+			Reputation:           reputation,
+			Consistency:          consistency,
+			Pegs:                 pegs,
+			Fallbacks:            fallbacks,
+			Health:               health,
+			Decimals:             cfg.Decimals,
+			Periods:              cfg.Periods,
+			VolumeDatabase:       volumeDatabase,
+		},
 	)
 
+	for i, oracleClient := range oracleClients {
+		oracle.NewVoter(
+			oracleClient,
+			cfg.Accounts[i].Healthchecks,
+			missMonitor,
+			balanceMonitor,
+		)
+	}
+
 	telemetryCfg := telemetry.Config{}
 	err = mapstructure.Decode(cfg.Telemetry, &telemetryCfg)
 	if err != nil {
@@ -308,15 +476,22 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 
 	// Block main process until all spawned goroutines have gracefully exited and
 	// signal has been captured in the main process or if an error occurs.
-	return g.Wait()
+	err = g.Wait()
+
+	// wait for the oracle to exit and close the databases
+	if stopErr := oracle.Stop(); stopErr != nil {
+		logger.Err(stopErr).Msg("failed to gracefully shutdown price-feeder oracle")
+	}
+
+	return err
 }
 
-func getKeyringPassword() (string, error) {
+func getKeyringPassword(dir string) (string, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	pass := os.Getenv(envVariablePass)
 	if pass == "" {
-		return input.GetString("Enter keyring password", reader)
+		return input.GetString(fmt.Sprintf("Enter keyring password (%s)", dir), reader)
 	}
 	return pass, nil
 }
@@ -373,7 +548,8 @@ func startPriceFeeder(
 	for {
 		select {
 		case <-ctx.Done():
-			shutdownCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			// the parent context is already cancelled at this point
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 			defer cancel()
 
 			logger.Info().Str("listen_addr", cfg.Server.ListenAddr).Msg("shutting down price-feeder server...")
@@ -407,7 +583,6 @@ func startPriceOracle(ctx context.Context, logger zerolog.Logger, oracle *oracle
 
 		case err := <-srvErrCh:
 			logger.Err(err).Msg("error starting the price-feeder oracle")
-			oracle.Stop()
 			return err
 		}
 	}
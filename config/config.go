@@ -1,14 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"strings"
 	"time"
 
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/derivative"
 	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
 
 	"github.com/BurntSushi/toml"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -18,13 +21,29 @@ import (
 const (
 	DenomUSD = "USD"
 
-	defaultListenAddr         = "0.0.0.0:7171"
-	defaultSrvWriteTimeout    = 15 * time.Second
-	defaultSrvReadTimeout     = 15 * time.Second
-	defaultProviderTimeout    = 100 * time.Millisecond
-	defaultHeightPollInterval = 1 * time.Second
-	defaultHistoryDb          = "prices.db"
-	defaultDerivativePeriod   = 30 * time.Minute
+	defaultListenAddr          = "0.0.0.0:7171"
+	defaultSrvWriteTimeout     = 15 * time.Second
+	defaultSrvReadTimeout      = 15 * time.Second
+	defaultProviderTimeout     = 100 * time.Millisecond
+	defaultHeightPollInterval  = 1 * time.Second
+	defaultHistoryDb           = "prices.db"
+	defaultDerivativePeriod    = 30 * time.Minute
+	defaultMissMonitorInterval = 2 * time.Minute
+	defaultBalanceInterval     = 10 * time.Minute
+	defaultBalanceWarnDays     = 7
+	defaultAuditRetention      = 10
+	defaultConfirmations       = 3
+	defaultConfirmInterval     = 30 * time.Second
+	defaultReputationWindow    = 24 * time.Hour
+	defaultReputationTolerance = "1"
+	defaultQuarantineScore     = "0.2"
+	defaultMinSamples          = 30
+	defaultCrossRateTolerance  = "2"
+	defaultFallbackAlertAfter  = 3
+	defaultDegradeAfter        = 3
+	defaultQuarantineAfter     = 5 * time.Minute
+	defaultRecoverAfter        = 2 * time.Minute
+	defaultProbeInterval       = 1 * time.Minute
 )
 
 var (
@@ -33,69 +52,23 @@ var (
 	// ErrEmptyConfigPath defines a sentinel error for an empty config path.
 	ErrEmptyConfigPath = errors.New("empty configuration file path")
 
-	// SupportedProviders defines a lookup table of all the supported currency API
-	// providers.
-	SupportedProviders = map[provider.Name]struct{}{
-		provider.ProviderAstroportInjective: {},
-		provider.ProviderAstroportNeutron:   {},
-		provider.ProviderAstroportTerra2:    {},
-		provider.ProviderBinance:            {},
-		provider.ProviderBinanceUS:          {},
-		provider.ProviderBingx:              {},
-		provider.ProviderBitfinex:           {},
-		provider.ProviderBitforex:           {},
-		provider.ProviderBitget:             {},
-		provider.ProviderBitmart:            {},
-		provider.ProviderBitstamp:           {},
-		provider.ProviderBybit:              {},
-		provider.ProviderCamelotV2:          {},
-		provider.ProviderCamelotV3:          {},
-		provider.ProviderCoinbase:           {},
-		provider.ProviderCoinex:             {},
-		provider.ProviderCrypto:             {},
-		provider.ProviderCurve:              {},
-		provider.ProviderDexter:             {},
-		provider.ProviderFin:                {},
-		provider.ProviderFinV2:              {},
-		provider.ProviderGate:               {},
-		provider.ProviderHelix:              {},
-		provider.ProviderHitBtc:             {},
-		provider.ProviderHuobi:              {},
-		provider.ProviderIdxOsmosis:         {},
-		provider.ProviderKraken:             {},
-		provider.ProviderKucoin:             {},
-		provider.ProviderLbank:              {},
-		provider.ProviderMaya:               {},
-		provider.ProviderMexc:               {},
-		provider.ProviderMock:               {},
-		provider.ProviderOkx:                {},
-		provider.ProviderOsmosisV2:          {},
-		provider.ProviderPancakeV3Bsc:       {},
-		provider.ProviderPhemex:             {},
-		provider.ProviderPionex:             {},
-		provider.ProviderPoloniex:           {},
-		provider.ProviderPyth:               {},
-		provider.ProviderShade:              {},
-		provider.ProviderStride:             {},
-		provider.ProviderUniswapV3:          {},
-		provider.ProviderUnstake:            {},
-		provider.ProviderVelodromeV2:        {},
-		provider.ProviderWhitewhaleCmdx:     {},
-		provider.ProviderWhitewhaleHuahua:   {},
-		provider.ProviderWhitewhaleInj:      {},
-		provider.ProviderWhitewhaleJuno:     {},
-		provider.ProviderWhitewhaleLuna:     {},
-		provider.ProviderWhitewhaleLunc:     {},
-		provider.ProviderWhitewhaleSei:      {},
-		provider.ProviderWhitewhaleWhale:    {},
-		provider.ProviderXt:                 {},
-		provider.ProviderZero:               {},
-	}
-
 	SupportedDerivatives = map[string]struct{}{
 		derivative.DerivativeTwap: {},
 	}
 
+	SupportedAggregators = map[string]struct{}{
+		aggregator.AggregatorVWAP:           {},
+		aggregator.AggregatorMedian:         {},
+		aggregator.AggregatorWeightedMedian: {},
+		aggregator.AggregatorTrimmedMean:    {},
+	}
+
+	SupportedDeviations = map[string]struct{}{
+		types.DeviationStdDev:  {},
+		types.DeviationMAD:     {},
+		types.DeviationPercent: {},
+	}
+
 	// maxDeviationThreshold is the maxmimum allowed amount of standard
 	// deviations which validators are able to set for a given asset.
 	maxDeviationThreshold = sdk.MustNewDecFromStr("3.0")
@@ -109,7 +82,8 @@ type (
 		Deviations           []Deviation                   `toml:"deviation_thresholds"`
 		ProviderMinOverrides []ProviderMinOverrides        `toml:"provider_min_overrides"`
 		ProviderWeights      map[string]map[string]float64 `toml:"provider_weight"`
-		Account              Account                       `toml:"account" validate:"required,gt=0,dive,required"`
+		Aggregations         []Aggregation                 `toml:"aggregation" validate:"dive"`
+		Accounts             Accounts                      `toml:"account" validate:"required,gt=0,dive"`
 		Keyring              Keyring                       `toml:"keyring" validate:"required,gt=0,dive,required"`
 		RPC                  RPC                           `toml:"rpc" validate:"required,gt=0,dive,required"`
 		Telemetry            Telemetry                     `toml:"telemetry"`
@@ -119,6 +93,7 @@ type (
 		ProviderEndpoints    []ProviderEndpoints           `toml:"provider_endpoints" validate:"dive"`
 		EnableServer         bool                          `toml:"enable_server"`
 		EnableVoter          bool                          `toml:"enable_voter"`
+		DryRun               bool                          `toml:"dry_run"`
 		Healthchecks         []Healthchecks                `toml:"healthchecks" validate:"dive"`
 		HeightPollInterval   string                        `toml:"height_poll_interval"`
 		HistoryDb            string                        `toml:"history_db"`
@@ -126,6 +101,16 @@ type (
 		Decimals             map[string]map[string]int     `toml:"decimals"`
 		Periods              map[string]map[string]int     `toml:"periods"`
 		UrlSets              map[string]UrlSet             `toml:"url_set"`
+		MissMonitor          MissMonitor                   `toml:"miss_monitor"`
+		BalanceMonitor       BalanceMonitor                `toml:"balance_monitor"`
+		AuditRetention       int                           `toml:"audit_retention" validate:"gte=0"`
+		CircuitBreakers      []CircuitBreaker              `toml:"circuit_breaker" validate:"dive"`
+		VolumeCaps           []VolumeCap                   `toml:"volume_cap" validate:"dive"`
+		Pegs                 []Peg                         `toml:"peg" validate:"dive"`
+		Fallbacks            []Fallback                    `toml:"fallback" validate:"dive"`
+		Reputation           Reputation                    `toml:"reputation"`
+		Health               Health                        `toml:"health"`
+		Consistency          Consistency                   `toml:"consistency"`
 	}
 
 	// Server defines the API server configuration.
@@ -147,11 +132,16 @@ type (
 		DerivativePeriod string          `toml:"derivative_period"`
 	}
 
-	// Deviation defines a maximum amount of standard deviations that a given asset can
-	// be from the median without being filtered out before voting.
+	// Deviation defines how far the price of a given asset can be from the
+	// others without being filtered out before voting: a maximum amount of
+	// standard deviations (default), median absolute deviations or percent.
+	// If only two prices are available, MaxSpread sets the maximum difference
+	// between them in percent.
 	Deviation struct {
 		Base      string `toml:"base" validate:"required"`
+		Method    string `toml:"method"`
 		Threshold string `toml:"threshold" validate:"required"`
+		MaxSpread string `toml:"max_spread"`
 	}
 
 	// ProviderMinOverrides defines the minimum amount of sources that need
@@ -161,16 +151,81 @@ type (
 		Providers uint     `toml:"providers" validate:"required"`
 	}
 
+	// Aggregation defines the method used to combine the prices of all
+	// providers into a single rate for the given denoms. Trim is only used by
+	// the trimmed mean.
+	Aggregation struct {
+		Denoms []string `toml:"denoms" validate:"required"`
+		Method string   `toml:"method" validate:"required"`
+		Trim   string   `toml:"trim"`
+	}
+
+	// CircuitBreaker defines the maximum change in percent of the prices of
+	// the given denoms within one price update, how many consecutive price
+	// updates at least the confirmation interval apart confirm a larger move
+	// and whether the denoms are withheld or voted with their last price
+	// until then.
+	CircuitBreaker struct {
+		Denoms               []string `toml:"denoms" validate:"required"`
+		MaxChange            string   `toml:"max_change" validate:"required"`
+		Confirmations        int      `toml:"confirmations"`
+		ConfirmationInterval string   `toml:"confirmation_interval"`
+		Action               string   `toml:"action"`
+	}
+
+	// VolumeCap defines the maximum share in percent of the total USD volume,
+	// that a single provider can have in the volume weighted prices of the
+	// given denoms. Providers overrides the maximum share of single providers.
+	VolumeCap struct {
+		Denoms    []string          `toml:"denoms" validate:"required"`
+		MaxShare  string            `toml:"max_share"`
+		Providers map[string]string `toml:"providers"`
+	}
+
+	// Peg defines the peg policy of the given stablecoins: fixed at 1 USD,
+	// following the market, or following the market only outside of the band
+	// in percent around the peg. Once the market price deviates more than the
+	// depeg threshold in percent, the stablecoins are depegged and follow
+	// the market, while depeg conversion sets whether prices quoted in them
+	// are converted with the market price or the peg.
+	Peg struct {
+		Denoms          []string `toml:"denoms" validate:"required"`
+		Mode            string   `toml:"mode" validate:"required"`
+		Band            string   `toml:"band"`
+		DepegThreshold  string   `toml:"depeg_threshold"`
+		DepegConversion string   `toml:"depeg_conversion"`
+	}
+
+	// Fallback defines the maximum age of the last successfully computed
+	// price of the given denoms, that is voted while no price can be
+	// computed, and after how many consecutive uses an alert is logged.
+	Fallback struct {
+		Denoms     []string `toml:"denoms" validate:"required"`
+		MaxAge     string   `toml:"max_age" validate:"required"`
+		AlertAfter int      `toml:"alert_after" validate:"gte=0"`
+	}
+
 	// Account defines account related configuration that is related to the
-	// network and transaction signing functionality.
+	// network and transaction signing functionality. The keyring, rpc, gas
+	// and healthchecks configuration defaults to the global one.
 	Account struct {
-		ChainID    string `toml:"chain_id" validate:"required"`
-		Address    string `toml:"address" validate:"required"`
-		Validator  string `toml:"validator" validate:"required"`
-		FeeGranter string `toml:"fee_granter"`
-		Prefix     string `toml:"prefix" validate:"required"`
+		ChainID       string         `toml:"chain_id" validate:"required"`
+		Address       string         `toml:"address" validate:"required"`
+		Validator     string         `toml:"validator" validate:"required"`
+		FeeGranter    string         `toml:"fee_granter"`
+		Prefix        string         `toml:"prefix" validate:"required"`
+		Keyring       *Keyring       `toml:"keyring"`
+		RPC           *RPC           `toml:"rpc"`
+		GasAdjustment float64        `toml:"gas_adjustment"`
+		GasPrices     string         `toml:"gas_prices"`
+		Healthchecks  []Healthchecks `toml:"healthchecks" validate:"dive"`
 	}
 
+	// Accounts defines the accounts of all validators to vote for. It's
+	// either configured as a single [account] or as multiple [[account]]
+	// tables.
+	Accounts []Account
+
 	// Keyring defines the required keyring configuration.
 	Keyring struct {
 		Backend string `toml:"backend" validate:"required"`
@@ -232,11 +287,88 @@ type (
 		VolumePause  int            `toml:"volume_pause"`
 		Decimals     map[string]int `toml:"decimals"`
 		Periods      map[string]int
+		// RequestRate and RequestBurst override the requests per second and
+		// the burst of the provider's rate limit per host.
+		RequestRate  float64 `toml:"request_rate"`
+		RequestBurst int     `toml:"request_burst"`
+		// Type allows to declare long-tail sources under their own name,
+		// currently only "generic_http".
+		Type        provider.Name `toml:"type"`
+		GenericHTTP *GenericHTTP  `toml:"generic_http"`
+	}
+
+	// GenericHTTP defines a REST/JSON source of the generic_http provider.
+	// The path may contain {symbol}, {base} and {quote} to request every
+	// pair on its own, the other paths point into the JSON response.
+	GenericHTTP struct {
+		Path          string            `toml:"path"`
+		TickersPath   string            `toml:"tickers_path"`
+		SymbolPath    string            `toml:"symbol_path"`
+		PricePath     string            `toml:"price_path"`
+		VolumePath    string            `toml:"volume_path"`
+		TimestampPath string            `toml:"timestamp_path"`
+		Symbol        string            `toml:"symbol"`
+		Symbols       map[string]string `toml:"symbols"`
+		Class         string            `toml:"class"`
 	}
 
 	UrlSet struct {
 		Urls []string `toml:"urls"`
 	}
+
+	// MissMonitor defines the configuration of the validator's miss counter
+	// monitoring. An alert is sent to the webhook, if the miss counter
+	// increased by at least the threshold within one interval.
+	MissMonitor struct {
+		Enabled        bool   `toml:"enabled"`
+		Interval       string `toml:"interval"`
+		AlertThreshold uint64 `toml:"alert_threshold"`
+		AlertWebhook   string `toml:"alert_webhook"`
+	}
+
+	// Reputation defines how providers are scored by their deviations from
+	// the final prices within the window. Deviations of more than tolerance
+	// percent count as misses, providers scoring below quarantine are not
+	// used anymore until their score recovers.
+	Reputation struct {
+		Enabled    bool   `toml:"enabled"`
+		Window     string `toml:"window"`
+		Tolerance  string `toml:"tolerance"`
+		Quarantine string `toml:"quarantine"`
+		MinSamples int    `toml:"min_samples"`
+	}
+
+	// Health defines when providers are considered degraded after
+	// consecutive failed polls, quarantined after failing for a duration and
+	// healthy again after succeeding for a duration while recovering.
+	// Quarantined providers are polled once per probe interval and not used
+	// for prices.
+	Health struct {
+		Enabled         bool   `toml:"enabled"`
+		DegradeAfter    int    `toml:"degrade_after"`
+		QuarantineAfter string `toml:"quarantine_after"`
+		RecoverAfter    string `toml:"recover_after"`
+		ProbeInterval   string `toml:"probe_interval"`
+	}
+
+	// Consistency defines the maximum deviation in percent of the cross rates
+	// of pairs not quoted in USD from the rates implied by the USD prices of
+	// their base and quote, and whether inconsistencies are only logged or
+	// the pair or denom most likely at fault is dropped.
+	Consistency struct {
+		Enabled   bool   `toml:"enabled"`
+		Tolerance string `toml:"tolerance"`
+		Action    string `toml:"action"`
+	}
+
+	// BalanceMonitor defines the configuration of the feeder balance
+	// monitoring. A warning is logged, if the funds available to pay fees are
+	// estimated to last less than the given amount of days.
+	BalanceMonitor struct {
+		Enabled  bool    `toml:"enabled"`
+		Interval string  `toml:"interval"`
+		WarnDays float64 `toml:"warn_days" validate:"gte=0"`
+	}
 )
 
 // telemetryValidation is custom validation for the Telemetry struct.
@@ -260,9 +392,87 @@ func endpointValidation(sl validator.StructLevel) {
 		sl.ReportError(endpoint.Name, "urls", "Urls", "urls or url_set empty", "")
 	}
 
-	if _, ok := SupportedProviders[endpoint.Name]; !ok {
+	if !provider.IsRegistered(endpoint.Name) && !endpoint.IsGenericHTTP() {
 		sl.ReportError(endpoint.Name, "name", "Name", "unsupportedEndpointProvider", "")
 	}
+
+	if endpoint.Type != "" && endpoint.Type != provider.ProviderGenericHTTP {
+		sl.ReportError(endpoint.Type, "type", "Type", "unsupportedEndpointType", "")
+	}
+
+	if endpoint.Type != "" && endpoint.Name != endpoint.Type && provider.IsRegistered(endpoint.Name) {
+		sl.ReportError(endpoint.Name, "name", "Name", "registeredEndpointProvider", "")
+	}
+
+	if endpoint.IsGenericHTTP() && endpoint.GenericHTTP == nil {
+		sl.ReportError(endpoint.Name, "generic_http", "GenericHTTP", "missingGenericHTTP", "")
+	}
+
+	if endpoint.RequestRate < 0 || endpoint.RequestBurst < 0 {
+		sl.ReportError(endpoint.Name, "request_rate", "RequestRate", "negativeRateLimit", "")
+	}
+
+	registration, found := provider.Lookup(endpoint.Name)
+	if found && endpoint.Websocket != "" && !registration.Capabilities.Websocket {
+		sl.ReportError(endpoint.Websocket, "websocket", "Websocket", "unsupportedWebsocket", "")
+	}
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface, to support both
+// a single [account] table and an array of [[account]] tables.
+func (a *Accounts) UnmarshalTOML(data interface{}) error {
+	var buf bytes.Buffer
+	err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"account": data})
+	if err != nil {
+		return fmt.Errorf("failed to encode account: %w", err)
+	}
+
+	switch data.(type) {
+	case map[string]interface{}:
+		var cfg struct {
+			Account Account `toml:"account"`
+		}
+		if _, err := toml.Decode(buf.String(), &cfg); err != nil {
+			return err
+		}
+		*a = Accounts{cfg.Account}
+
+	case []map[string]interface{}:
+		var cfg struct {
+			Account []Account `toml:"account"`
+		}
+		if _, err := toml.Decode(buf.String(), &cfg); err != nil {
+			return err
+		}
+		*a = cfg.Account
+
+	default:
+		return fmt.Errorf("invalid account configuration")
+	}
+
+	return nil
+}
+
+// setDefaults sets all unset keyring, rpc, gas and healthchecks options of
+// the account to the global configuration.
+func (a *Account) setDefaults(cfg Config) {
+	if a.Keyring == nil {
+		keyring := cfg.Keyring
+		a.Keyring = &keyring
+	}
+	if a.RPC == nil {
+		rpc := cfg.RPC
+		a.RPC = &rpc
+	}
+	if a.GasAdjustment == 0 {
+		a.GasAdjustment = cfg.GasAdjustment
+	}
+	if a.GasPrices == "" {
+		a.GasPrices = cfg.GasPrices
+	}
+	if len(a.Healthchecks) == 0 {
+		a.Healthchecks = cfg.Healthchecks
+	}
 }
 
 // Validate returns an error if the Config object is invalid.
@@ -272,6 +482,19 @@ func (c Config) Validate() error {
 	return validate.Struct(c)
 }
 
+// GenericProviders returns the names of the sources of the generic_http
+// provider declared under their own name, which are accepted like registered
+// providers.
+func (c Config) GenericProviders() map[provider.Name]struct{} {
+	genericProviders := map[provider.Name]struct{}{}
+	for _, endpoint := range c.ProviderEndpoints {
+		if endpoint.IsGenericHTTP() && endpoint.GenericHTTP != nil {
+			genericProviders[endpoint.Name] = struct{}{}
+		}
+	}
+	return genericProviders
+}
+
 func (p ProviderEndpoints) ToEndpoint(
 	sets map[string]UrlSet,
 ) (provider.Endpoint, error) {
@@ -305,10 +528,30 @@ func (p ProviderEndpoints) ToEndpoint(
 		VolumePause:   p.VolumePause,
 		Decimals:      p.Decimals,
 		Periods:       p.Periods,
+		RateLimit: provider.RateLimit{
+			Rate:  p.RequestRate,
+			Burst: p.RequestBurst,
+		},
+	}
+
+	if p.IsGenericHTTP() && p.GenericHTTP != nil {
+		settings, err := p.GenericHTTP.NewGenericHTTP()
+		if err != nil {
+			return provider.Endpoint{}, err
+		}
+		e.GenericHTTP = &settings
 	}
+
 	return e, nil
 }
 
+// IsGenericHTTP returns whether the endpoint declares a source of the
+// generic_http provider.
+func (p ProviderEndpoints) IsGenericHTTP() bool {
+	return p.Name == provider.ProviderGenericHTTP ||
+		p.Type == provider.ProviderGenericHTTP
+}
+
 // ParseConfig attempts to read and parse configuration from the given file path.
 // An error is returned if reading or parsing the config fails.
 func ParseConfig(configPath string) (Config, error) {
@@ -345,6 +588,73 @@ func ParseConfig(configPath string) (Config, error) {
 	if cfg.HistoryDb == "" {
 		cfg.HistoryDb = defaultHistoryDb
 	}
+	if cfg.MissMonitor.Interval == "" {
+		cfg.MissMonitor.Interval = defaultMissMonitorInterval.String()
+	}
+	if _, err := time.ParseDuration(cfg.MissMonitor.Interval); err != nil {
+		return cfg, fmt.Errorf("failed to parse miss monitor interval: %w", err)
+	}
+	if cfg.BalanceMonitor.Interval == "" {
+		cfg.BalanceMonitor.Interval = defaultBalanceInterval.String()
+	}
+	if _, err := time.ParseDuration(cfg.BalanceMonitor.Interval); err != nil {
+		return cfg, fmt.Errorf("failed to parse balance monitor interval: %w", err)
+	}
+	if cfg.BalanceMonitor.WarnDays == 0 {
+		cfg.BalanceMonitor.WarnDays = defaultBalanceWarnDays
+	}
+	if cfg.AuditRetention == 0 {
+		cfg.AuditRetention = defaultAuditRetention
+	}
+	if cfg.Reputation.Window == "" {
+		cfg.Reputation.Window = defaultReputationWindow.String()
+	}
+	if cfg.Reputation.Tolerance == "" {
+		cfg.Reputation.Tolerance = defaultReputationTolerance
+	}
+	if cfg.Reputation.Quarantine == "" {
+		cfg.Reputation.Quarantine = defaultQuarantineScore
+	}
+	if cfg.Reputation.MinSamples == 0 {
+		cfg.Reputation.MinSamples = defaultMinSamples
+	}
+	if _, err := cfg.Reputation.NewReputation(); err != nil {
+		return cfg, err
+	}
+	if cfg.Health.DegradeAfter == 0 {
+		cfg.Health.DegradeAfter = defaultDegradeAfter
+	}
+	if cfg.Health.QuarantineAfter == "" {
+		cfg.Health.QuarantineAfter = defaultQuarantineAfter.String()
+	}
+	if cfg.Health.RecoverAfter == "" {
+		cfg.Health.RecoverAfter = defaultRecoverAfter.String()
+	}
+	if cfg.Health.ProbeInterval == "" {
+		cfg.Health.ProbeInterval = defaultProbeInterval.String()
+	}
+	if _, err := cfg.Health.NewHealth(); err != nil {
+		return cfg, err
+	}
+	if cfg.Consistency.Tolerance == "" {
+		cfg.Consistency.Tolerance = defaultCrossRateTolerance
+	}
+	if cfg.Consistency.Action == "" {
+		cfg.Consistency.Action = types.ConsistencyActionLog
+	}
+	if _, err := cfg.Consistency.NewConsistency(); err != nil {
+		return cfg, err
+	}
+
+	for _, endpoint := range cfg.ProviderEndpoints {
+		if !endpoint.IsGenericHTTP() || endpoint.GenericHTTP == nil {
+			continue
+		}
+		if _, err := endpoint.GenericHTTP.NewGenericHTTP(); err != nil {
+			return cfg, fmt.Errorf("%s: %w", endpoint.Name, err)
+		}
+	}
+	genericProviders := cfg.GenericProviders()
 
 	derivativeDenoms := map[string]struct{}{}
 	derivativeBases := map[string]struct{}{}
@@ -378,22 +688,37 @@ func ParseConfig(configPath string) (Config, error) {
 				return cfg, fmt.Errorf("cannot combine derivative and nonderivative pairs for %s", cp.Base)
 			}
 		}
-		for _, provider := range cp.Providers {
-			if _, ok := SupportedProviders[provider]; !ok {
-				return cfg, fmt.Errorf("unsupported provider: %s", provider)
+		for _, providerName := range cp.Providers {
+			_, generic := genericProviders[providerName]
+			if !provider.IsRegistered(providerName) && !generic {
+				return cfg, fmt.Errorf("unsupported provider: %s", providerName)
 			}
-			pairs[cp.Base][provider] = struct{}{}
+			pairs[cp.Base][providerName] = struct{}{}
 		}
 	}
 
-	for _, deviation := range cfg.Deviations {
-		threshold, err := sdk.NewDecFromStr(deviation.Threshold)
-		if err != nil {
-			return cfg, fmt.Errorf("deviation thresholds must be numeric: %w", err)
+	for name := range cfg.ContractAdresses {
+		registration, found := provider.Lookup(provider.Name(name))
+		if !found {
+			return cfg, fmt.Errorf("unsupported provider: %s", name)
+		}
+		if !registration.Capabilities.Contracts {
+			return cfg, fmt.Errorf("provider %s does not use contract addresses", name)
 		}
+	}
 
-		if threshold.GT(maxDeviationThreshold) {
-			return cfg, fmt.Errorf("deviation thresholds must not exceed 3.0")
+	for _, providerSettings := range []map[string]map[string]int{cfg.Decimals, cfg.Periods} {
+		for name := range providerSettings {
+			_, generic := genericProviders[provider.Name(name)]
+			if !provider.IsRegistered(provider.Name(name)) && !generic {
+				return cfg, fmt.Errorf("unsupported provider: %s", name)
+			}
+		}
+	}
+
+	for _, deviation := range cfg.Deviations {
+		if _, err := deviation.NewDeviation(); err != nil {
+			return cfg, err
 		}
 	}
 
@@ -403,5 +728,444 @@ func ParseConfig(configPath string) (Config, error) {
 		}
 	}
 
+	for _, aggregation := range cfg.Aggregations {
+		if _, ok := SupportedAggregators[aggregation.Method]; !ok {
+			return cfg, fmt.Errorf("unsupported aggregation method: %s", aggregation.Method)
+		}
+
+		if _, err := aggregation.NewAggregator(); err != nil {
+			return cfg, err
+		}
+	}
+
+	for i, breaker := range cfg.CircuitBreakers {
+		if breaker.Confirmations == 0 {
+			cfg.CircuitBreakers[i].Confirmations = defaultConfirmations
+		}
+		if breaker.ConfirmationInterval == "" {
+			cfg.CircuitBreakers[i].ConfirmationInterval = defaultConfirmInterval.String()
+		}
+		if breaker.Action == "" {
+			cfg.CircuitBreakers[i].Action = types.BreakerActionWithhold
+		}
+
+		if _, err := cfg.CircuitBreakers[i].NewCircuitBreaker(); err != nil {
+			return cfg, err
+		}
+	}
+
+	for _, volumeCap := range cfg.VolumeCaps {
+		if _, err := volumeCap.NewVolumeCap(genericProviders); err != nil {
+			return cfg, err
+		}
+	}
+
+	for _, peg := range cfg.Pegs {
+		if _, err := peg.NewPeg(); err != nil {
+			return cfg, err
+		}
+	}
+
+	for i, fallback := range cfg.Fallbacks {
+		if fallback.AlertAfter == 0 {
+			cfg.Fallbacks[i].AlertAfter = defaultFallbackAlertAfter
+		}
+
+		if _, err := cfg.Fallbacks[i].NewFallback(); err != nil {
+			return cfg, err
+		}
+	}
+
+	// the bech32 prefix is set globally, so all accounts must share it. The
+	// same validator may vote on several chains, e.g. mainnet and testnet.
+	validators := map[string]struct{}{}
+	for i, account := range cfg.Accounts {
+		if account.Prefix != cfg.Accounts[0].Prefix {
+			return cfg, fmt.Errorf("all accounts must use the same prefix")
+		}
+
+		key := account.ChainID + "/" + account.Validator
+		if _, ok := validators[key]; ok {
+			return cfg, fmt.Errorf(
+				"duplicate account for validator %s on %s",
+				account.Validator, account.ChainID,
+			)
+		}
+		validators[key] = struct{}{}
+
+		cfg.Accounts[i].setDefaults(cfg)
+	}
+
 	return cfg, cfg.Validate()
 }
+
+// NewAggregator returns the aggregator configured by the aggregation.
+func (a Aggregation) NewAggregator() (aggregator.Aggregator, error) {
+	trim := sdk.Dec{}
+	if a.Trim != "" {
+		var err error
+		trim, err = sdk.NewDecFromStr(a.Trim)
+		if err != nil {
+			return nil, fmt.Errorf("aggregation trim must be numeric: %w", err)
+		}
+	}
+
+	return aggregator.NewAggregator(a.Method, trim)
+}
+
+// NewDeviation returns the deviation filter configured for an asset.
+func (d Deviation) NewDeviation() (types.Deviation, error) {
+	method := d.Method
+	if method == "" {
+		method = types.DeviationStdDev
+	}
+
+	if _, ok := SupportedDeviations[method]; !ok {
+		return types.Deviation{}, fmt.Errorf("unsupported deviation method: %s", method)
+	}
+
+	threshold, err := sdk.NewDecFromStr(d.Threshold)
+	if err != nil {
+		return types.Deviation{}, fmt.Errorf("deviation thresholds must be numeric: %w", err)
+	}
+
+	switch method {
+	case types.DeviationStdDev:
+		if threshold.GT(maxDeviationThreshold) {
+			return types.Deviation{}, fmt.Errorf("deviation thresholds must not exceed 3.0")
+		}
+	case types.DeviationPercent:
+		if !threshold.IsPositive() || threshold.GTE(sdk.NewDec(100)) {
+			return types.Deviation{}, fmt.Errorf("percent deviation thresholds must be between 0 and 100")
+		}
+	}
+
+	if threshold.IsNegative() {
+		return types.Deviation{}, fmt.Errorf("deviation thresholds must not be negative")
+	}
+
+	maxSpread := sdk.Dec{}
+	if d.MaxSpread != "" {
+		maxSpread, err = sdk.NewDecFromStr(d.MaxSpread)
+		if err != nil {
+			return types.Deviation{}, fmt.Errorf("deviation max spread must be numeric: %w", err)
+		}
+
+		if !maxSpread.IsPositive() {
+			return types.Deviation{}, fmt.Errorf("deviation max spread must be positive")
+		}
+	}
+
+	return types.Deviation{
+		Method:    method,
+		Threshold: threshold,
+		MaxSpread: maxSpread,
+	}, nil
+}
+
+// NewCircuitBreaker returns the circuit breaker configured for the denoms.
+func (c CircuitBreaker) NewCircuitBreaker() (types.CircuitBreaker, error) {
+	maxChange, err := sdk.NewDecFromStr(c.MaxChange)
+	if err != nil {
+		return types.CircuitBreaker{}, fmt.Errorf("circuit breaker max change must be numeric: %w", err)
+	}
+
+	if !maxChange.IsPositive() {
+		return types.CircuitBreaker{}, fmt.Errorf("circuit breaker max change must be positive")
+	}
+
+	if c.Confirmations < 2 {
+		return types.CircuitBreaker{}, fmt.Errorf("circuit breaker confirmations must be at least 2")
+	}
+
+	confirmationInterval, err := time.ParseDuration(c.ConfirmationInterval)
+	if err != nil {
+		return types.CircuitBreaker{}, fmt.Errorf("failed to parse circuit breaker confirmation interval: %w", err)
+	}
+
+	if confirmationInterval < 0 {
+		return types.CircuitBreaker{}, fmt.Errorf("circuit breaker confirmation interval must not be negative")
+	}
+
+	switch c.Action {
+	case types.BreakerActionWithhold, types.BreakerActionLastPrice:
+	default:
+		return types.CircuitBreaker{}, fmt.Errorf("unsupported circuit breaker action: %s", c.Action)
+	}
+
+	return types.CircuitBreaker{
+		MaxChange:            maxChange,
+		Confirmations:        c.Confirmations,
+		ConfirmationInterval: confirmationInterval,
+		Action:               c.Action,
+	}, nil
+}
+
+// NewVolumeCap returns the volume cap configured for the denoms. Provider
+// shares may also be set for the generic providers.
+func (v VolumeCap) NewVolumeCap(
+	genericProviders map[provider.Name]struct{},
+) (types.VolumeCap, error) {
+	if v.MaxShare == "" && len(v.Providers) == 0 {
+		return types.VolumeCap{}, fmt.Errorf("volume cap requires a max share or provider shares")
+	}
+
+	volumeCap := types.VolumeCap{
+		Providers: map[string]sdk.Dec{},
+	}
+
+	if v.MaxShare != "" {
+		maxShare, err := newVolumeShare(v.MaxShare)
+		if err != nil {
+			return types.VolumeCap{}, err
+		}
+		volumeCap.MaxShare = maxShare
+	}
+
+	for name, share := range v.Providers {
+		_, generic := genericProviders[provider.Name(name)]
+		if !provider.IsRegistered(provider.Name(name)) && !generic {
+			return types.VolumeCap{}, fmt.Errorf("unsupported provider: %s", name)
+		}
+
+		maxShare, err := newVolumeShare(share)
+		if err != nil {
+			return types.VolumeCap{}, err
+		}
+		volumeCap.Providers[name] = maxShare
+	}
+
+	return volumeCap, nil
+}
+
+func newVolumeShare(share string) (sdk.Dec, error) {
+	maxShare, err := sdk.NewDecFromStr(share)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("volume cap max share must be numeric: %w", err)
+	}
+
+	if !maxShare.IsPositive() || maxShare.GT(sdk.NewDec(100)) {
+		return sdk.Dec{}, fmt.Errorf("volume cap max share must be between 0 and 100")
+	}
+
+	return maxShare, nil
+}
+
+// NewReputation returns the provider reputation configured.
+func (r Reputation) NewReputation() (types.Reputation, error) {
+	window, err := time.ParseDuration(r.Window)
+	if err != nil {
+		return types.Reputation{}, fmt.Errorf("failed to parse reputation window: %w", err)
+	}
+
+	if window <= 0 {
+		return types.Reputation{}, fmt.Errorf("reputation window must be positive")
+	}
+
+	tolerance, err := sdk.NewDecFromStr(r.Tolerance)
+	if err != nil {
+		return types.Reputation{}, fmt.Errorf("reputation tolerance must be numeric: %w", err)
+	}
+
+	if !tolerance.IsPositive() {
+		return types.Reputation{}, fmt.Errorf("reputation tolerance must be positive")
+	}
+
+	quarantine, err := sdk.NewDecFromStr(r.Quarantine)
+	if err != nil {
+		return types.Reputation{}, fmt.Errorf("reputation quarantine must be numeric: %w", err)
+	}
+
+	if quarantine.IsNegative() || quarantine.GTE(sdk.OneDec()) {
+		return types.Reputation{}, fmt.Errorf("reputation quarantine must be between 0 and 1")
+	}
+
+	if r.MinSamples < 1 {
+		return types.Reputation{}, fmt.Errorf("reputation min samples must be positive")
+	}
+
+	return types.Reputation{
+		Window:     window,
+		Tolerance:  tolerance,
+		Quarantine: quarantine,
+		MinSamples: r.MinSamples,
+	}, nil
+}
+
+// NewConsistency returns the cross rate consistency check configured.
+func (c Consistency) NewConsistency() (types.Consistency, error) {
+	tolerance, err := sdk.NewDecFromStr(c.Tolerance)
+	if err != nil {
+		return types.Consistency{}, fmt.Errorf("consistency tolerance must be numeric: %w", err)
+	}
+
+	if !tolerance.IsPositive() {
+		return types.Consistency{}, fmt.Errorf("consistency tolerance must be positive")
+	}
+
+	switch c.Action {
+	case types.ConsistencyActionLog, types.ConsistencyActionDrop:
+	default:
+		return types.Consistency{}, fmt.Errorf("unsupported consistency action: %s", c.Action)
+	}
+
+	return types.Consistency{
+		Tolerance: tolerance,
+		Action:    c.Action,
+	}, nil
+}
+
+// NewPeg returns the peg policy configured for the denoms.
+func (p Peg) NewPeg() (types.Peg, error) {
+	peg := types.Peg{
+		Mode: p.Mode,
+	}
+
+	switch p.Mode {
+	case types.PegModeFixed, types.PegModeMarket:
+		if p.Band != "" {
+			return types.Peg{}, fmt.Errorf("peg band requires the band mode")
+		}
+	case types.PegModeBand:
+		band, err := sdk.NewDecFromStr(p.Band)
+		if err != nil {
+			return types.Peg{}, fmt.Errorf("peg band must be numeric: %w", err)
+		}
+
+		if !band.IsPositive() {
+			return types.Peg{}, fmt.Errorf("peg band must be positive")
+		}
+		peg.Band = band
+	default:
+		return types.Peg{}, fmt.Errorf("unsupported peg mode: %s", p.Mode)
+	}
+
+	if p.DepegThreshold == "" {
+		if p.DepegConversion != "" {
+			return types.Peg{}, fmt.Errorf("peg depeg conversion requires a depeg threshold")
+		}
+		return peg, nil
+	}
+
+	threshold, err := sdk.NewDecFromStr(p.DepegThreshold)
+	if err != nil {
+		return types.Peg{}, fmt.Errorf("peg depeg threshold must be numeric: %w", err)
+	}
+
+	if !threshold.IsPositive() {
+		return types.Peg{}, fmt.Errorf("peg depeg threshold must be positive")
+	}
+
+	if !peg.Band.IsNil() && threshold.LTE(peg.Band) {
+		return types.Peg{}, fmt.Errorf("peg depeg threshold must be greater than the band")
+	}
+
+	// quote conversions during a depeg must be chosen explicitly
+	switch p.DepegConversion {
+	case types.PegConversionMarket, types.PegConversionPeg:
+	default:
+		return types.Peg{}, fmt.Errorf("unsupported peg depeg conversion: %q", p.DepegConversion)
+	}
+
+	peg.DepegThreshold = threshold
+	peg.DepegConversion = p.DepegConversion
+
+	return peg, nil
+}
+
+// NewFallback returns the last known good price fallback configured for the
+// denoms.
+func (f Fallback) NewFallback() (types.Fallback, error) {
+	maxAge, err := time.ParseDuration(f.MaxAge)
+	if err != nil {
+		return types.Fallback{}, fmt.Errorf("failed to parse fallback max age: %w", err)
+	}
+
+	if maxAge <= 0 {
+		return types.Fallback{}, fmt.Errorf("fallback max age must be positive")
+	}
+
+	if f.AlertAfter < 1 {
+		return types.Fallback{}, fmt.Errorf("fallback alert after must be at least 1")
+	}
+
+	return types.Fallback{
+		MaxAge:     maxAge,
+		AlertAfter: f.AlertAfter,
+	}, nil
+}
+
+// NewGenericHTTP returns the request and response settings of a
+// generic_http source.
+func (g GenericHTTP) NewGenericHTTP() (provider.GenericHTTP, error) {
+	if !strings.HasPrefix(g.Path, "/") {
+		return provider.GenericHTTP{}, fmt.Errorf("generic http path must start with /")
+	}
+
+	if g.PricePath == "" {
+		return provider.GenericHTTP{}, fmt.Errorf("generic http price path is required")
+	}
+
+	if g.Symbol != "" && (!strings.Contains(g.Symbol, "{base}") ||
+		!strings.Contains(g.Symbol, "{quote}")) {
+		return provider.GenericHTTP{}, fmt.Errorf("generic http symbol must contain {base} and {quote}")
+	}
+
+	if g.Class != "" && !provider.IsSourceClass(g.Class) {
+		return provider.GenericHTTP{}, fmt.Errorf("generic http class must be %s or %s",
+			provider.SourceClassCEX, provider.SourceClassDEX)
+	}
+
+	return provider.GenericHTTP{
+		Path:          g.Path,
+		TickersPath:   g.TickersPath,
+		SymbolPath:    g.SymbolPath,
+		PricePath:     g.PricePath,
+		VolumePath:    g.VolumePath,
+		TimestampPath: g.TimestampPath,
+		Symbol:        g.Symbol,
+		Symbols:       g.Symbols,
+		Class:         g.Class,
+	}, nil
+}
+
+// NewHealth returns the provider health tracking configured.
+func (h Health) NewHealth() (types.Health, error) {
+	if h.DegradeAfter < 1 {
+		return types.Health{}, fmt.Errorf("health degrade after must be positive")
+	}
+
+	quarantineAfter, err := time.ParseDuration(h.QuarantineAfter)
+	if err != nil {
+		return types.Health{}, fmt.Errorf("failed to parse health quarantine after: %w", err)
+	}
+
+	if quarantineAfter <= 0 {
+		return types.Health{}, fmt.Errorf("health quarantine after must be positive")
+	}
+
+	recoverAfter, err := time.ParseDuration(h.RecoverAfter)
+	if err != nil {
+		return types.Health{}, fmt.Errorf("failed to parse health recover after: %w", err)
+	}
+
+	if recoverAfter <= 0 {
+		return types.Health{}, fmt.Errorf("health recover after must be positive")
+	}
+
+	probeInterval, err := time.ParseDuration(h.ProbeInterval)
+	if err != nil {
+		return types.Health{}, fmt.Errorf("failed to parse health probe interval: %w", err)
+	}
+
+	if probeInterval <= 0 {
+		return types.Health{}, fmt.Errorf("health probe interval must be positive")
+	}
+
+	return types.Health{
+		DegradeAfter:    h.DegradeAfter,
+		QuarantineAfter: quarantineAfter,
+		RecoverAfter:    recoverAfter,
+		ProbeInterval:   probeInterval,
+	}, nil
+}
@@ -1,13 +1,17 @@
 package config_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"price-feeder/config"
 	"price-feeder/oracle/provider"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,11 +26,13 @@ func TestValidate(t *testing.T) {
 			CurrencyPairs: []config.CurrencyPair{
 				{Base: "ATOM", Quote: "USDT", Providers: []provider.Name{provider.ProviderKraken}},
 			},
-			Account: config.Account{
-				Address:   "fromaddr",
-				Validator: "valaddr",
-				ChainID:   "chain-id",
-				Prefix:    "chain",
+			Accounts: config.Accounts{
+				{
+					Address:   "fromaddr",
+					Validator: "valaddr",
+					ChainID:   "chain-id",
+					Prefix:    "chain",
+				},
 			},
 			Keyring: config.Keyring{
 				Backend: "test",
@@ -87,6 +93,24 @@ func TestValidate(t *testing.T) {
 		},
 	}
 
+	websocketEndpoint := validConfig()
+	websocketEndpoint.ProviderEndpoints = []config.ProviderEndpoints{
+		{
+			Name:      provider.ProviderKraken,
+			Urls:      []string{"https://api.kraken.com"},
+			Websocket: "ws.kraken.com",
+		},
+	}
+
+	unsupportedWebsocket := validConfig()
+	unsupportedWebsocket.ProviderEndpoints = []config.ProviderEndpoints{
+		{
+			Name:      provider.ProviderMexc,
+			Urls:      []string{"https://api.mexc.com"},
+			Websocket: "wbs.mexc.com",
+		},
+	}
+
 	testCases := []struct {
 		name      string
 		cfg       config.Config
@@ -97,6 +121,16 @@ func TestValidate(t *testing.T) {
 			validConfig(),
 			false,
 		},
+		{
+			"websocket endpoint",
+			websocketEndpoint,
+			false,
+		},
+		{
+			"unsupported websocket",
+			unsupportedWebsocket,
+			true,
+		},
 		{
 			"empty pairs",
 			emptyPairs,
@@ -231,6 +265,184 @@ timeout = "200ms"
 	require.Equal(t, provider.ProviderKraken, cfg.CurrencyPairs[0].Providers[0])
 	require.Equal(t, provider.ProviderBinance, cfg.CurrencyPairs[0].Providers[1])
 	require.Equal(t, "twap", cfg.CurrencyPairs[3].Derivative)
+	require.Len(t, cfg.Accounts, 1)
+	require.Equal(t, "kujira-local-testnet", cfg.Accounts[0].ChainID)
+	require.Equal(t, "localhost:9090", cfg.Accounts[0].RPC.GRPCEndpoint)
+	require.Equal(t, "test", cfg.Accounts[0].Keyring.Backend)
+	require.Equal(t, 1.5, cfg.Accounts[0].GasAdjustment)
+	require.Len(t, cfg.Accounts[0].Healthchecks, 1)
+}
+
+func TestParseConfig_Valid_MultipleAccounts(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := []byte(`
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = [
+	"kraken",
+	"binance",
+	"huobi"
+]
+
+[[account]]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[[account]]
+address = "kujira1r0d2w5xvgyvr8qarxgz4ppfcflsqlnmrvguxvd"
+validator = "kujiravaloper1r0d2w5xvgyvr8qarxgz4ppfcflsqlnmrdhlng9"
+chain_id = "harpoon-4"
+prefix = "kujira"
+gas_prices = "0.0034ukuji"
+
+[account.keyring]
+backend = "test"
+dir = "/Users/username/.kujira-testnet"
+
+[account.rpc]
+tmrpc_endpoint = "http://testnet:26657"
+grpc_endpoint = "testnet:9090"
+rpc_timeout = "500ms"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`)
+	_, err = tmpFile.Write(content)
+	require.NoError(t, err)
+
+	cfg, err := config.ParseConfig(tmpFile.Name())
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Accounts, 2)
+
+	require.Equal(t, "kaiyo-1", cfg.Accounts[0].ChainID)
+	require.Equal(t, "localhost:9090", cfg.Accounts[0].RPC.GRPCEndpoint)
+	require.Equal(t, "/Users/username/.kujira", cfg.Accounts[0].Keyring.Dir)
+	require.Equal(t, "0.00125ukuji", cfg.Accounts[0].GasPrices)
+
+	require.Equal(t, "harpoon-4", cfg.Accounts[1].ChainID)
+	require.Equal(t, "testnet:9090", cfg.Accounts[1].RPC.GRPCEndpoint)
+	require.Equal(t, "500ms", cfg.Accounts[1].RPC.RPCTimeout)
+	require.Equal(t, "/Users/username/.kujira-testnet", cfg.Accounts[1].Keyring.Dir)
+	require.Equal(t, "0.0034ukuji", cfg.Accounts[1].GasPrices)
+	require.Equal(t, 1.5, cfg.Accounts[1].GasAdjustment)
+}
+
+func TestParseConfig_SameValidatorOnChains(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[account]]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[[account]]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "%s"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		chainID string
+		valid   bool
+	}{
+		{"harpoon-4", true},
+		{"kaiyo-1", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.chainID)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.ErrorContains(t, err, "duplicate account", tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+		require.Len(t, cfg.Accounts, 2)
+		require.Equal(t, cfg.Accounts[0].Validator, cfg.Accounts[1].Validator)
+	}
+}
+
+func TestParseConfig_Invalid_AccountPrefixes(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := []byte(`
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[account]]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[[account]]
+address = "cosmos15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "cosmosvaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "cosmoshub-4"
+prefix = "cosmos"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`)
+	_, err = tmpFile.Write(content)
+	require.NoError(t, err)
+
+	_, err = config.ParseConfig(tmpFile.Name())
+	require.Error(t, err)
 }
 
 func TestParseConfig_Valid_NoTelemetry(t *testing.T) {
@@ -344,6 +556,228 @@ providers = [
 	require.Error(t, err)
 }
 
+func TestParseConfig_ProviderSettings(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "KUJI"
+quote = "USDC"
+providers = ["%s"]
+
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	generic := `
+[[provider_endpoints]]
+name = "example"
+type = "generic_http"
+urls = ["https://api.example.com"]
+
+[provider_endpoints.generic_http]
+path = "/api/tickers"
+price_path = "last"`
+
+	testCases := []struct {
+		provider string
+		settings string
+		valid    bool
+	}{
+		{"finv2", "[contract_addresses.finv2]\nKUJIUSDC = \"kujira1pool\"", true},
+		{"osmosis", "[decimals.osmosisv2]\nKUJI = 6", true},
+		{"stride", ``, false},
+		{"bitforex", ``, false},
+		{"kraken", "[contract_addresses.kraken]\nKUJIUSDC = \"kujira1pool\"", false},
+		{"kraken", "[contract_addresses.foobar]\nKUJIUSDC = \"kujira1pool\"", false},
+		{"kraken", "[periods.foobar]\nKUJI = 6", false},
+		{"example", "[periods.example]\nKUJI = 6\n" + generic, true},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.provider, tc.settings)))
+		require.NoError(t, err)
+
+		_, err = config.ParseConfig(tmpFile.Name())
+		require.Equal(t, tc.valid, err == nil, tc)
+	}
+}
+
+func TestParseConfig_GenericHTTP(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["%s"]
+
+[[provider_endpoints]]
+urls = ["https://api.example.com"]
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	generic := `
+[provider_endpoints.generic_http]
+path = "/api/tickers"
+tickers_path = "data"
+symbol_path = "symbol"
+price_path = "last"
+symbol = "{base}-{quote}"
+symbols = { ATOM = "atom" }
+class = "dex"`
+
+	testCases := []struct {
+		provider string
+		endpoint string
+		valid    bool
+	}{
+		{"generic_http", `name = "generic_http"` + generic, true},
+		{"example", "name = \"example\"\ntype = \"generic_http\"" + generic, true},
+		{"example", `name = "example"` + generic, false},
+		{"example", "name = \"example\"\ntype = \"generic_http\"", false},
+		{"example", "name = \"example\"\ntype = \"foobar\"" + generic, false},
+		{"kraken", "name = \"kraken\"\ntype = \"generic_http\"" + generic, false},
+		{"generic_http", `name = "generic_http"` + strings.Replace(generic, "/api", "api", 1), false},
+		{"generic_http", `name = "generic_http"` + strings.Replace(generic, "price_path", "foo", 1), false},
+		{"generic_http", `name = "generic_http"` + strings.Replace(generic, "{quote}", "USDT", 1), false},
+		{"generic_http", `name = "generic_http"` + strings.Replace(generic, `"dex"`, `"foo"`, 1), false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.provider, tc.endpoint)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		endpoint, err := cfg.ProviderEndpoints[0].ToEndpoint(cfg.UrlSets)
+		require.NoError(t, err)
+		require.Equal(t, provider.Name(tc.provider), endpoint.Name)
+		require.Equal(t, &provider.GenericHTTP{
+			Path:        "/api/tickers",
+			TickersPath: "data",
+			SymbolPath:  "symbol",
+			PricePath:   "last",
+			Symbol:      "{base}-{quote}",
+			Symbols:     map[string]string{"ATOM": "atom"},
+			Class:       provider.SourceClassDEX,
+		}, endpoint.GenericHTTP)
+	}
+}
+
+func TestParseConfig_RateLimit(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[provider_endpoints]]
+name = "kraken"
+urls = ["https://api.kraken.com"]
+request_rate = %s
+request_burst = %d
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		rate  string
+		burst int
+		valid bool
+	}{
+		{"0.5", 3, true},
+		{"0", 0, true},
+		{"-1", 3, false},
+		{"1", -1, false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.rate, tc.burst)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		endpoint, err := cfg.ProviderEndpoints[0].ToEndpoint(cfg.UrlSets)
+		require.NoError(t, err)
+		require.Equal(t, provider.RateLimit{
+			Rate:  sdk.MustNewDecFromStr(tc.rate).MustFloat64(),
+			Burst: tc.burst,
+		}, endpoint.RateLimit)
+	}
+}
+
 func TestParseConfig_NonUSDQuote(t *testing.T) {
 	tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
 	require.NoError(t, err)
@@ -551,3 +985,675 @@ global_labels = [["chain-id", "umee-local-testnet"]]
 	_, err = config.ParseConfig(tmpFile.Name())
 	require.Error(t, err)
 }
+
+func TestParseConfig_Aggregations(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[aggregation]]
+denoms = ["ATOM", "USDT"]
+method = "%s"
+trim = "%s"
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		method string
+		trim   string
+		valid  bool
+	}{
+		{"median", "", true},
+		{"trimmed_mean", "0.25", true},
+		{"trimmed_mean", "0.5", false},
+		{"trimmed_mean", "x", false},
+		{"mean", "", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.method, tc.trim)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc.method)
+			continue
+		}
+
+		require.NoError(t, err, tc.method)
+		require.Equal(t, []string{"ATOM", "USDT"}, cfg.Aggregations[0].Denoms)
+		require.Equal(t, tc.method, cfg.Aggregations[0].Method)
+	}
+}
+
+func TestParseConfig_DeviationMethods(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[deviation_thresholds]]
+base = "ATOM"
+method = "%s"
+threshold = "%s"
+max_spread = "%s"
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		method    string
+		threshold string
+		maxSpread string
+		valid     bool
+	}{
+		{"", "2", "", true},
+		{"stddev", "4", "", false},
+		{"mad", "5", "", true},
+		{"mad", "-1", "", false},
+		{"percent", "2.5", "1", true},
+		{"percent", "100", "", false},
+		{"percent", "0", "", false},
+		{"percent", "2", "0", false},
+		{"percent", "2", "x", false},
+		{"iqr", "2", "", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(
+			content, tc.method, tc.threshold, tc.maxSpread,
+		)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+		require.Equal(t, tc.method, cfg.Deviations[0].Method)
+
+		deviation, err := cfg.Deviations[0].NewDeviation()
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr(tc.threshold), deviation.Threshold)
+	}
+}
+
+func TestParseConfig_CircuitBreakers(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[circuit_breaker]]
+denoms = ["ATOM"]
+max_change = "%s"
+confirmations = %d
+confirmation_interval = "%s"
+action = "%s"
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		maxChange     string
+		confirmations int
+		interval      string
+		action        string
+		valid         bool
+	}{
+		{"10", 0, "", "", true},
+		{"5.5", 2, "1m", "last_price", true},
+		{"5.5", 2, "0s", "last_price", true},
+		{"10", 1, "", "withhold", false},
+		{"0", 3, "", "withhold", false},
+		{"x", 3, "", "withhold", false},
+		{"10", 3, "", "ignore", false},
+		{"10", 3, "x", "withhold", false},
+		{"10", 3, "-1m", "withhold", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(
+			content, tc.maxChange, tc.confirmations, tc.interval, tc.action,
+		)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		breaker, err := cfg.CircuitBreakers[0].NewCircuitBreaker()
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr(tc.maxChange), breaker.MaxChange)
+
+		if tc.confirmations == 0 {
+			require.Equal(t, 3, breaker.Confirmations)
+			require.Equal(t, 30*time.Second, breaker.ConfirmationInterval)
+			require.Equal(t, "withhold", breaker.Action)
+		} else {
+			require.Equal(t, tc.confirmations, breaker.Confirmations)
+			require.Equal(t, tc.action, breaker.Action)
+		}
+	}
+}
+
+func TestParseConfig_VolumeCaps(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[volume_cap]]
+denoms = ["ATOM"]
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+
+[[provider_endpoints]]
+name = "example"
+type = "generic_http"
+urls = ["https://api.example.com"]
+
+[provider_endpoints.generic_http]
+path = "/api/tickers"
+price_path = "last"
+`
+
+	testCases := []struct {
+		volumeCap string
+		valid     bool
+	}{
+		{`max_share = "40"`, true},
+		{"max_share = \"40\"\n[volume_cap.providers]\nbinance = \"25\"", true},
+		{"[volume_cap.providers]\nbinance = \"100\"", true},
+		{"[volume_cap.providers]\nexample = \"25\"", true},
+		{``, false},
+		{`max_share = "0"`, false},
+		{`max_share = "101"`, false},
+		{`max_share = "x"`, false},
+		{"[volume_cap.providers]\nfoobar = \"25\"", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.volumeCap)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		volumeCap, err := cfg.VolumeCaps[0].NewVolumeCap(cfg.GenericProviders())
+		require.NoError(t, err)
+
+		for name, share := range cfg.VolumeCaps[0].Providers {
+			limit, found := volumeCap.Limit(name)
+			require.True(t, found)
+			require.Equal(t, sdk.MustNewDecFromStr(share), limit)
+		}
+
+		limit, found := volumeCap.Limit("kraken")
+		require.Equal(t, cfg.VolumeCaps[0].MaxShare != "", found)
+		if found {
+			require.Equal(t, sdk.MustNewDecFromStr(cfg.VolumeCaps[0].MaxShare), limit)
+		}
+	}
+}
+
+func TestParseConfig_Reputation(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[reputation]
+enabled = true
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		reputation string
+		valid      bool
+	}{
+		{``, true},
+		{"window = \"6h\"\ntolerance = \"0.5\"\nquarantine = \"0\"\nmin_samples = 10", true},
+		{`window = "x"`, false},
+		{`window = "-1h"`, false},
+		{`tolerance = "0"`, false},
+		{`quarantine = "1"`, false},
+		{`min_samples = -1`, false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.reputation)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+		require.True(t, cfg.Reputation.Enabled)
+
+		reputation, err := cfg.Reputation.NewReputation()
+		require.NoError(t, err)
+
+		if tc.reputation == "" {
+			require.Equal(t, 24*time.Hour, reputation.Window)
+			require.Equal(t, sdk.OneDec(), reputation.Tolerance)
+			require.Equal(t, sdk.MustNewDecFromStr("0.2"), reputation.Quarantine)
+			require.Equal(t, 30, reputation.MinSamples)
+		} else {
+			require.Equal(t, 6*time.Hour, reputation.Window)
+			require.Equal(t, sdk.MustNewDecFromStr("0.5"), reputation.Tolerance)
+			require.True(t, reputation.Quarantine.IsZero())
+			require.Equal(t, 10, reputation.MinSamples)
+		}
+	}
+}
+
+func TestParseConfig_Health(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[health]
+enabled = true
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		health string
+		valid  bool
+	}{
+		{``, true},
+		{"degrade_after = 5\nquarantine_after = \"1h\"\nrecover_after = \"10m\"\nprobe_interval = \"30s\"", true},
+		{`degrade_after = -1`, false},
+		{`quarantine_after = "x"`, false},
+		{`recover_after = "-1m"`, false},
+		{`probe_interval = "0s"`, false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.health)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+		require.True(t, cfg.Health.Enabled)
+
+		health, err := cfg.Health.NewHealth()
+		require.NoError(t, err)
+
+		if tc.health == "" {
+			require.Equal(t, 3, health.DegradeAfter)
+			require.Equal(t, 5*time.Minute, health.QuarantineAfter)
+			require.Equal(t, 2*time.Minute, health.RecoverAfter)
+			require.Equal(t, time.Minute, health.ProbeInterval)
+		} else {
+			require.Equal(t, 5, health.DegradeAfter)
+			require.Equal(t, time.Hour, health.QuarantineAfter)
+			require.Equal(t, 10*time.Minute, health.RecoverAfter)
+			require.Equal(t, 30*time.Second, health.ProbeInterval)
+		}
+	}
+}
+
+func TestParseConfig_Consistency(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[consistency]
+enabled = true
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		consistency string
+		tolerance   string
+		action      string
+		valid       bool
+	}{
+		{``, "2", "log", true},
+		{"tolerance = \"0.5\"\naction = \"drop\"", "0.5", "drop", true},
+		{`tolerance = "0"`, "", "", false},
+		{`tolerance = "x"`, "", "", false},
+		{`action = "ignore"`, "", "", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.consistency)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		consistency, err := cfg.Consistency.NewConsistency()
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr(tc.tolerance), consistency.Tolerance)
+		require.Equal(t, tc.action, consistency.Action)
+	}
+}
+
+func TestParseConfig_Pegs(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "USDT"
+quote = "USD"
+providers = ["kraken"]
+
+[[peg]]
+denoms = ["USDT"]
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		peg   string
+		valid bool
+	}{
+		{`mode = "fixed"`, true},
+		{`mode = "market"`, true},
+		{"mode = \"band\"\nband = \"0.5\"", true},
+		{"mode = \"band\"\nband = \"0.5\"\ndepeg_threshold = \"2\"\ndepeg_conversion = \"market\"", true},
+		{"mode = \"fixed\"\ndepeg_threshold = \"2\"\ndepeg_conversion = \"peg\"", true},
+		{``, false},
+		{`mode = "pegged"`, false},
+		{`mode = "band"`, false},
+		{"mode = \"band\"\nband = \"0\"", false},
+		{"mode = \"fixed\"\nband = \"0.5\"", false},
+		{"mode = \"fixed\"\ndepeg_threshold = \"2\"", false},
+		{"mode = \"fixed\"\ndepeg_conversion = \"peg\"", false},
+		{"mode = \"fixed\"\ndepeg_threshold = \"0\"\ndepeg_conversion = \"peg\"", false},
+		{"mode = \"band\"\nband = \"2\"\ndepeg_threshold = \"1\"\ndepeg_conversion = \"peg\"", false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.peg)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		peg, err := cfg.Pegs[0].NewPeg()
+		require.NoError(t, err)
+		require.Equal(t, cfg.Pegs[0].Mode, peg.Mode)
+		require.Equal(t, cfg.Pegs[0].DepegConversion, peg.DepegConversion)
+
+		if cfg.Pegs[0].DepegThreshold != "" {
+			require.Equal(t, sdk.MustNewDecFromStr(cfg.Pegs[0].DepegThreshold), peg.DepegThreshold)
+		}
+	}
+}
+
+func TestParseConfig_Fallbacks(t *testing.T) {
+	content := `
+gas_adjustment = 1.5
+gas_prices = "0.00125ukuji"
+
+[[currency_pairs]]
+base = "ATOM"
+quote = "USDT"
+providers = ["kraken"]
+
+[[fallback]]
+denoms = ["ATOM"]
+%s
+
+[account]
+address = "kujira15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+validator = "kujiravaloper14rjlkfzp56733j5l5nfk6fphjxymgf8mj04d5p"
+chain_id = "kaiyo-1"
+prefix = "kujira"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.kujira"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+grpc_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	testCases := []struct {
+		fallback   string
+		maxAge     time.Duration
+		alertAfter int
+		valid      bool
+	}{
+		{`max_age = "5m"`, 5 * time.Minute, 3, true},
+		{"max_age = \"30s\"\nalert_after = 1", 30 * time.Second, 1, true},
+		{``, 0, 0, false},
+		{`max_age = "0s"`, 0, 0, false},
+		{`max_age = "x"`, 0, 0, false},
+		{"max_age = \"5m\"\nalert_after = -1", 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		tmpFile, err := ioutil.TempFile("", "price-feeder.toml")
+		require.NoError(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		_, err = tmpFile.Write([]byte(fmt.Sprintf(content, tc.fallback)))
+		require.NoError(t, err)
+
+		cfg, err := config.ParseConfig(tmpFile.Name())
+		if !tc.valid {
+			require.Error(t, err, tc)
+			continue
+		}
+
+		require.NoError(t, err, tc)
+
+		fallback, err := cfg.Fallbacks[0].NewFallback()
+		require.NoError(t, err)
+		require.Equal(t, tc.maxAge, fallback.MaxAge)
+		require.Equal(t, tc.alertAfter, fallback.AlertAfter)
+	}
+}
@@ -0,0 +1,199 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	AggregatorVWAP           = "vwap"
+	AggregatorMedian         = "median"
+	AggregatorWeightedMedian = "weighted_median"
+	AggregatorTrimmedMean    = "trimmed_mean"
+)
+
+// DefaultTrim is the share of prices removed from each end by the trimmed
+// mean, if not configured otherwise.
+var DefaultTrim = sdk.MustNewDecFromStr("0.2")
+
+type (
+	// Aggregator combines the prices of multiple providers into a single
+	// rate.
+	Aggregator interface {
+		Aggregate([]types.TickerPrice) (sdk.Dec, error)
+	}
+
+	// VWAP returns the volume weighted average price.
+	VWAP struct{}
+
+	// Median returns the median price, ignoring volumes.
+	Median struct{}
+
+	// WeightedMedian returns the volume weighted median price. If all
+	// tickers report a volume of 0, it returns the median price instead.
+	WeightedMedian struct{}
+
+	// TrimmedMean removes the given share of the lowest and highest prices
+	// and returns the average of the remaining ones, ignoring volumes.
+	TrimmedMean struct {
+		Trim sdk.Dec
+	}
+)
+
+// NewAggregator returns the aggregator with the given name. The trim is only
+// used by the trimmed mean and defaults to DefaultTrim, if nil.
+func NewAggregator(name string, trim sdk.Dec) (Aggregator, error) {
+	switch name {
+	case AggregatorVWAP:
+		return VWAP{}, nil
+	case AggregatorMedian:
+		return Median{}, nil
+	case AggregatorWeightedMedian:
+		return WeightedMedian{}, nil
+	case AggregatorTrimmedMean:
+		if trim.IsNil() {
+			trim = DefaultTrim
+		}
+		if trim.IsNegative() || trim.GTE(sdk.NewDecWithPrec(5, 1)) {
+			return nil, fmt.Errorf("trim must be >= 0 and < 0.5")
+		}
+		return TrimmedMean{Trim: trim}, nil
+	}
+	return nil, fmt.Errorf("unsupported aggregator: %s", name)
+}
+
+func (VWAP) Aggregate(tickers []types.TickerPrice) (sdk.Dec, error) {
+	return ComputeVWAP(tickers)
+}
+
+func (Median) Aggregate(tickers []types.TickerPrice) (sdk.Dec, error) {
+	if len(tickers) == 0 {
+		return sdk.Dec{}, fmt.Errorf("no tickers supplied")
+	}
+
+	prices := sortedPrices(tickers)
+	middle := len(prices) / 2
+
+	if len(prices)%2 == 1 {
+		return prices[middle], nil
+	}
+
+	return prices[middle-1].Add(prices[middle]).QuoInt64(2), nil
+}
+
+func (WeightedMedian) Aggregate(tickers []types.TickerPrice) (sdk.Dec, error) {
+	if len(tickers) == 0 {
+		return sdk.Dec{}, fmt.Errorf("no tickers supplied")
+	}
+
+	sorted := make([]types.TickerPrice, len(tickers))
+	copy(sorted, tickers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Price.LT(sorted[j].Price)
+	})
+
+	volumeSum := sdk.ZeroDec()
+	for _, ticker := range sorted {
+		volumeSum = volumeSum.Add(ticker.Volume)
+	}
+
+	if !volumeSum.IsPositive() {
+		return Median{}.Aggregate(tickers)
+	}
+
+	half := volumeSum.QuoInt64(2)
+	cumulative := sdk.ZeroDec()
+
+	for i, ticker := range sorted {
+		cumulative = cumulative.Add(ticker.Volume)
+
+		if cumulative.GT(half) {
+			return ticker.Price, nil
+		}
+
+		if cumulative.Equal(half) && ticker.Volume.IsPositive() {
+			// exactly half of the volume on both sides, use the mean of
+			// this and the next price with any volume
+			for _, next := range sorted[i+1:] {
+				if next.Volume.IsPositive() {
+					return ticker.Price.Add(next.Price).QuoInt64(2), nil
+				}
+			}
+		}
+	}
+
+	return sorted[len(sorted)-1].Price, nil
+}
+
+func (a TrimmedMean) Aggregate(tickers []types.TickerPrice) (sdk.Dec, error) {
+	if len(tickers) == 0 {
+		return sdk.Dec{}, fmt.Errorf("no tickers supplied")
+	}
+
+	prices := sortedPrices(tickers)
+
+	trim := int(a.Trim.MulInt64(int64(len(prices))).TruncateInt64())
+	if 2*trim >= len(prices) {
+		trim = (len(prices) - 1) / 2
+	}
+
+	prices = prices[trim : len(prices)-trim]
+
+	sum := sdk.ZeroDec()
+	for _, price := range prices {
+		sum = sum.Add(price)
+	}
+
+	return sum.QuoInt64(int64(len(prices))), nil
+}
+
+// ComputeVWAP computes the volume weighted average price for all tickers.
+// If all tickers report a volume of 0, treat all volumes as 1 and
+// effectively return the average price instead.
+// Ref: https://en.wikipedia.org/wiki/Volume-weighted_average_price
+func ComputeVWAP(tickers []types.TickerPrice) (sdk.Dec, error) {
+	if len(tickers) == 0 {
+		return sdk.Dec{}, fmt.Errorf("no tickers supplied")
+	}
+
+	volumeSum := sdk.ZeroDec()
+
+	for _, tp := range tickers {
+		volumeSum = volumeSum.Add(tp.Volume)
+	}
+
+	weightedPrice := sdk.ZeroDec()
+
+	for _, tp := range tickers {
+		volume := tp.Volume
+		if volumeSum.Equal(sdk.ZeroDec()) {
+			volume = sdk.NewDec(1)
+		}
+
+		// weightedPrice = Σ {P * V} for all TickerPrice
+		weightedPrice = weightedPrice.Add(tp.Price.Mul(volume))
+	}
+
+	if volumeSum.Equal(sdk.ZeroDec()) {
+		volumeSum = sdk.NewDec(int64(len(tickers)))
+	}
+
+	return weightedPrice.Quo(volumeSum), nil
+}
+
+func sortedPrices(tickers []types.TickerPrice) []sdk.Dec {
+	prices := make([]sdk.Dec, len(tickers))
+	for i, ticker := range tickers {
+		prices[i] = ticker.Price
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].LT(prices[j])
+	})
+
+	return prices
+}
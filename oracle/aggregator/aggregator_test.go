@@ -0,0 +1,124 @@
+package aggregator_test
+
+import (
+	"testing"
+
+	"price-feeder/oracle/aggregator"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func tickers(values ...string) []types.TickerPrice {
+	tickers := []types.TickerPrice{}
+	for i := 0; i < len(values); i += 2 {
+		tickers = append(tickers, types.TickerPrice{
+			Price:  sdk.MustNewDecFromStr(values[i]),
+			Volume: sdk.MustNewDecFromStr(values[i+1]),
+		})
+	}
+	return tickers
+}
+
+func TestAggregators(t *testing.T) {
+	prices := map[string][]types.TickerPrice{
+		// one exchange with most of the volume
+		"DOMINANT": tickers("10", "1", "20", "1", "30", "100"),
+		// dex tickers without volume
+		"ZERO": tickers("10", "0", "20", "0", "30", "0", "100", "0"),
+		// exactly half of the volume on each side
+		"HALF":   tickers("1", "1", "2", "0", "5", "1"),
+		"SINGLE": tickers("12.34", "5"),
+	}
+
+	testCases := []struct {
+		name       string
+		aggregator aggregator.Aggregator
+		expected   map[string]sdk.Dec
+	}{
+		{
+			"vwap",
+			aggregator.VWAP{},
+			map[string]sdk.Dec{
+				"DOMINANT": sdk.MustNewDecFromStr("29.705882352941176471"),
+				"ZERO":     sdk.MustNewDecFromStr("40"),
+				"HALF":     sdk.MustNewDecFromStr("3"),
+				"SINGLE":   sdk.MustNewDecFromStr("12.34"),
+			},
+		},
+		{
+			"median",
+			aggregator.Median{},
+			map[string]sdk.Dec{
+				"DOMINANT": sdk.MustNewDecFromStr("20"),
+				"ZERO":     sdk.MustNewDecFromStr("25"),
+				"HALF":     sdk.MustNewDecFromStr("2"),
+				"SINGLE":   sdk.MustNewDecFromStr("12.34"),
+			},
+		},
+		{
+			"weighted_median",
+			aggregator.WeightedMedian{},
+			map[string]sdk.Dec{
+				"DOMINANT": sdk.MustNewDecFromStr("30"),
+				"ZERO":     sdk.MustNewDecFromStr("25"),
+				"HALF":     sdk.MustNewDecFromStr("3"),
+				"SINGLE":   sdk.MustNewDecFromStr("12.34"),
+			},
+		},
+		{
+			"trimmed_mean",
+			aggregator.TrimmedMean{Trim: sdk.MustNewDecFromStr("0.25")},
+			map[string]sdk.Dec{
+				"DOMINANT": sdk.MustNewDecFromStr("20"),
+				"ZERO":     sdk.MustNewDecFromStr("25"),
+				"HALF":     sdk.MustNewDecFromStr("2.666666666666666666"),
+				"SINGLE":   sdk.MustNewDecFromStr("12.34"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for denom, tickers := range prices {
+				rate, err := tc.aggregator.Aggregate(tickers)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected[denom], rate, denom)
+			}
+
+			_, err := tc.aggregator.Aggregate([]types.TickerPrice{})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	prices := tickers("1", "0", "2", "0", "3", "0", "4", "0", "100", "0")
+
+	// 5 * 0.2 = 1 price removed from each end
+	rate, err := aggregator.TrimmedMean{Trim: sdk.MustNewDecFromStr("0.2")}.Aggregate(prices)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("3"), rate)
+
+	// 5 * 0.1 = 0.5 rounds down to no price removed
+	rate, err = aggregator.TrimmedMean{Trim: sdk.MustNewDecFromStr("0.1")}.Aggregate(prices)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("22"), rate)
+}
+
+func TestNewAggregator(t *testing.T) {
+	agg, err := aggregator.NewAggregator(aggregator.AggregatorMedian, sdk.Dec{})
+	require.NoError(t, err)
+	require.Equal(t, aggregator.Median{}, agg)
+
+	agg, err = aggregator.NewAggregator(aggregator.AggregatorTrimmedMean, sdk.Dec{})
+	require.NoError(t, err)
+	require.Equal(t, aggregator.TrimmedMean{Trim: aggregator.DefaultTrim}, agg)
+
+	_, err = aggregator.NewAggregator(aggregator.AggregatorTrimmedMean, sdk.MustNewDecFromStr("0.5"))
+	require.Error(t, err)
+
+	_, err = aggregator.NewAggregator("mean", sdk.Dec{})
+	require.Error(t, err)
+}
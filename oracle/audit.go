@@ -0,0 +1,169 @@
+package oracle
+
+import (
+	"sort"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// priceAuditor collects the audit trail of all prices computed in one
+// price update.
+type priceAuditor struct {
+	audits map[string]*types.PriceAudit
+}
+
+func newPriceAuditor() *priceAuditor {
+	return &priceAuditor{
+		audits: map[string]*types.PriceAudit{},
+	}
+}
+
+func (a *priceAuditor) get(denom string) *types.PriceAudit {
+	audit, found := a.audits[denom]
+	if !found {
+		audit = &types.PriceAudit{
+			Denom:   denom,
+			Tickers: []types.AuditTicker{},
+		}
+		a.audits[denom] = audit
+	}
+
+	return audit
+}
+
+func (a *priceAuditor) addTicker(denom string, ticker types.AuditTicker) {
+	audit := a.get(denom)
+	audit.Tickers = append(audit.Tickers, ticker)
+}
+
+func (a *priceAuditor) addConversion(
+	denom string,
+	pair types.CurrencyPair,
+	quote quoteConversion,
+) {
+	conversion := types.AuditConversion{
+		Symbol:    pair.String(),
+		Quote:     pair.Quote,
+		Rate:      quote.rate,
+		Paths:     []types.AuditPath{},
+		Providers: []string{},
+		Peg:       quote.peg,
+	}
+
+	for _, path := range quote.paths {
+		conversion.Paths = append(conversion.Paths, types.AuditPath{
+			Denoms:    path.denoms(),
+			Rate:      path.rate,
+			Sources:   path.sources,
+			Liquidity: path.liquidity,
+		})
+	}
+
+	for providerName := range quote.providers {
+		conversion.Providers = append(conversion.Providers, providerName.String())
+	}
+
+	for providerName := range quote.dropped {
+		conversion.Dropped = append(conversion.Dropped, providerName.String())
+	}
+
+	sort.Strings(conversion.Providers)
+	sort.Strings(conversion.Dropped)
+
+	audit := a.get(denom)
+	audit.Conversions = append(audit.Conversions, conversion)
+}
+
+// drop marks the used tickers of all providers, that are missing in the
+// filtered tickers, as dropped for the given reason.
+func (a *priceAuditor) drop(
+	denom string,
+	filtered map[provider.Name]types.TickerPrice,
+	reason string,
+) {
+	audit := a.get(denom)
+	for i, ticker := range audit.Tickers {
+		if ticker.Dropped != "" {
+			continue
+		}
+
+		if _, found := filtered[provider.Name(ticker.Provider)]; !found {
+			audit.Tickers[i].Dropped = reason
+		}
+	}
+}
+
+// capVolumes sets the capped volumes of the used tickers of the given
+// providers.
+func (a *priceAuditor) capVolumes(
+	denom string,
+	tickers map[provider.Name]types.TickerPrice,
+	capped map[provider.Name]struct{},
+) {
+	audit := a.get(denom)
+	for i, ticker := range audit.Tickers {
+		if ticker.Dropped != "" {
+			continue
+		}
+
+		providerName := provider.Name(ticker.Provider)
+		if _, found := capped[providerName]; found {
+			volume := tickers[providerName].Volume
+			audit.Tickers[i].CappedVolume = &volume
+		}
+	}
+}
+
+// setScores sets the reputation scores of the providers of all tickers.
+func (a *priceAuditor) setScores(
+	denom string,
+	scores map[string]types.ProviderScore,
+) {
+	audit := a.get(denom)
+	for i, ticker := range audit.Tickers {
+		if score, found := scores[ticker.Provider]; found {
+			score := score.Score
+			audit.Tickers[i].Score = &score
+		}
+	}
+}
+
+func (a *priceAuditor) warn(denom string, warning string) {
+	audit := a.get(denom)
+	audit.Warnings = append(audit.Warnings, warning)
+}
+
+func (a *priceAuditor) fail(denom string, err string) {
+	a.get(denom).Error = err
+}
+
+// setPeg sets how the peg policy of a stablecoin has been applied.
+func (a *priceAuditor) setPeg(denom string, peg types.AuditPeg) {
+	a.get(denom).Peg = &peg
+}
+
+func (a *priceAuditor) setPrice(denom string, price sdk.Dec) {
+	audit := a.get(denom)
+	audit.Price = &price
+	audit.Error = ""
+}
+
+// result returns all audits with their tickers sorted by provider and
+// symbol.
+func (a *priceAuditor) result() map[string]types.PriceAudit {
+	audits := make(map[string]types.PriceAudit, len(a.audits))
+	for denom, audit := range a.audits {
+		sort.SliceStable(audit.Tickers, func(i, j int) bool {
+			if audit.Tickers[i].Provider != audit.Tickers[j].Provider {
+				return audit.Tickers[i].Provider < audit.Tickers[j].Provider
+			}
+			return audit.Tickers[i].Symbol < audit.Tickers[j].Symbol
+		})
+		audits[denom] = *audit
+	}
+
+	return audits
+}
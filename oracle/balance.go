@@ -0,0 +1,299 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"price-feeder/oracle/types"
+)
+
+const (
+	basicAllowanceTypeURL      = "/cosmos.feegrant.v1beta1.BasicAllowance"
+	periodicAllowanceTypeURL   = "/cosmos.feegrant.v1beta1.PeriodicAllowance"
+	allowedMsgAllowanceTypeURL = "/cosmos.feegrant.v1beta1.AllowedMsgAllowance"
+)
+
+// BalanceMonitor defines the configuration of the balance monitor. The funds
+// available to pay fees are checked every interval and a warning is logged,
+// if they are estimated to last less than the given amount of days.
+type BalanceMonitor struct {
+	Interval time.Duration
+	WarnDays float64
+}
+
+// GetBalance returns the last known balance of the feeder and whether it has
+// been fetched yet.
+func (v *Voter) GetBalance() (types.FeederBalance, bool) {
+	v.mtx.RLock()
+	defer v.mtx.RUnlock()
+
+	if v.balance == nil {
+		return types.FeederBalance{}, false
+	}
+
+	return *v.balance, true
+}
+
+func (v *Voter) monitorBalance(ctx context.Context) {
+	for {
+		balance, err := v.updateBalance(ctx)
+		if err != nil {
+			v.logger.Warn().Err(err).Msg("failed to update feeder balance")
+		} else {
+			v.checkBalance(balance)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(v.balanceMonitor.Interval):
+		}
+	}
+}
+
+func (v *Voter) checkBalance(balance types.FeederBalance) {
+	logger := v.logger.With().
+		Str("denom", balance.Denom).
+		Str("balance", balance.Balance.String()).
+		Logger()
+
+	if balance.Allowance != nil {
+		logger = logger.With().Str("allowance", balance.Allowance.String()).Logger()
+	}
+
+	if balance.RemainingDays == nil {
+		logger.Info().Msg("updated feeder balance")
+		return
+	}
+
+	logger = logger.With().Float64("remaining_days", *balance.RemainingDays).Logger()
+
+	if *balance.RemainingDays < v.balanceMonitor.WarnDays {
+		logger.Warn().Msg("feeder is running out of funds")
+		return
+	}
+
+	logger.Info().Msg("updated feeder balance")
+}
+
+func (v *Voter) updateBalance(ctx context.Context) (types.FeederBalance, error) {
+	gasPrices, err := sdk.ParseDecCoins(v.oracleClient.GasPrices)
+	if err != nil {
+		return types.FeederBalance{}, fmt.Errorf("failed to parse gas prices: %w", err)
+	}
+	if len(gasPrices) == 0 {
+		return types.FeederBalance{}, fmt.Errorf("no gas prices configured")
+	}
+
+	balance := types.FeederBalance{
+		Feeder:    v.oracleClient.OracleAddrString,
+		Denom:     gasPrices[0].Denom,
+		UpdatedAt: time.Now(),
+	}
+
+	grpcConn, err := v.dialGRPC()
+	if err != nil {
+		return types.FeederBalance{}, err
+	}
+
+	defer grpcConn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	payer := v.oracleClient.OracleAddr
+	if !v.oracleClient.FeeGranterAddr.Empty() {
+		payer = v.oracleClient.FeeGranterAddr
+		balance.FeeGranter = payer.String()
+
+		response, err := feegrant.NewQueryClient(grpcConn).Allowance(
+			ctx,
+			&feegrant.QueryAllowanceRequest{
+				Granter: payer.String(),
+				Grantee: balance.Feeder,
+			},
+		)
+		switch {
+		case status.Code(err) == codes.NotFound:
+			// fees can't be paid at all without an allowance
+			v.logger.Error().
+				Str("fee_granter", balance.FeeGranter).
+				Msg("no fee allowance granted to feeder")
+			zero := sdk.ZeroInt()
+			balance.Allowance = &zero
+
+		case err != nil:
+			return types.FeederBalance{}, fmt.Errorf("failed to get fee allowance: %w", err)
+
+		default:
+			err := ParseAllowance(response.Allowance.Allowance, &balance, balance.UpdatedAt)
+			if err != nil {
+				return types.FeederBalance{}, err
+			}
+		}
+	}
+
+	response, err := banktypes.NewQueryClient(grpcConn).Balance(
+		ctx,
+		&banktypes.QueryBalanceRequest{
+			Address: payer.String(),
+			Denom:   balance.Denom,
+		},
+	)
+	if err != nil {
+		return types.FeederBalance{}, fmt.Errorf("failed to get balance: %w", err)
+	}
+	balance.Balance = response.Balance.Amount
+
+	if feesPerDay, ok := v.oracleClient.Fees.FeesPerDay(balance.Denom); ok {
+		balance.FeesPerDay = &feesPerDay
+	}
+
+	balance.RemainingDays = ComputeRemainingDays(balance, time.Now())
+
+	v.mtx.Lock()
+	v.balance = &balance
+	v.mtx.Unlock()
+
+	labels := []metrics.Label{
+		telemetry.NewLabel("validator", v.oracleClient.ValidatorAddrString),
+		telemetry.NewLabel("denom", balance.Denom),
+	}
+
+	telemetry.SetGaugeWithLabels(
+		[]string{"feeder", "balance"},
+		float32(sdk.NewDecFromInt(balance.Balance).MustFloat64()),
+		labels,
+	)
+	if balance.Allowance != nil {
+		telemetry.SetGaugeWithLabels(
+			[]string{"feeder", "allowance"},
+			float32(sdk.NewDecFromInt(*balance.Allowance).MustFloat64()),
+			labels,
+		)
+	}
+	if balance.RemainingDays != nil {
+		telemetry.SetGaugeWithLabels(
+			[]string{"feeder", "remaining_days"},
+			float32(*balance.RemainingDays),
+			labels,
+		)
+	}
+
+	return balance, nil
+}
+
+// ParseAllowance sets the remaining spend limit in the denom of the balance,
+// the expiration and, for periodic allowances, the end of the current period
+// of a fee allowance. The spend limit of a periodic allowance is the lower
+// of what is left in the current period and of the basic spend limit. A
+// period that ended before now is reset by the chain on the next fee paid,
+// so its full period spend limit is available.
+func ParseAllowance(
+	allowance *codectypes.Any,
+	balance *types.FeederBalance,
+	now time.Time,
+) error {
+	if allowance == nil {
+		return fmt.Errorf("empty fee allowance")
+	}
+
+	var (
+		basic       feegrant.BasicAllowance
+		periodLimit *sdk.Int
+		periodReset *time.Time
+	)
+
+	switch allowance.TypeUrl {
+	case basicAllowanceTypeURL:
+		if err := basic.Unmarshal(allowance.Value); err != nil {
+			return err
+		}
+
+	case periodicAllowanceTypeURL:
+		var periodic feegrant.PeriodicAllowance
+		if err := periodic.Unmarshal(allowance.Value); err != nil {
+			return err
+		}
+		basic = periodic.Basic
+
+		// same as the reset of the period by the chain
+		canSpend := periodic.PeriodCanSpend
+		if !now.Before(periodic.PeriodReset) {
+			canSpend = periodic.PeriodSpendLimit
+			periodic.PeriodReset = periodic.PeriodReset.Add(periodic.Period)
+			if now.After(periodic.PeriodReset) {
+				periodic.PeriodReset = now.Add(periodic.Period)
+			}
+		}
+
+		amount := canSpend.AmountOf(balance.Denom)
+		periodLimit = &amount
+		periodReset = &periodic.PeriodReset
+
+	case allowedMsgAllowanceTypeURL:
+		var allowed feegrant.AllowedMsgAllowance
+		if err := allowed.Unmarshal(allowance.Value); err != nil {
+			return err
+		}
+		return ParseAllowance(allowed.Allowance, balance, now)
+
+	default:
+		return fmt.Errorf("unsupported fee allowance: %s", allowance.TypeUrl)
+	}
+
+	var limit *sdk.Int
+	if !basic.SpendLimit.Empty() {
+		amount := basic.SpendLimit.AmountOf(balance.Denom)
+		limit = &amount
+	}
+	if periodLimit != nil && (limit == nil || periodLimit.LT(*limit)) {
+		limit = periodLimit
+	}
+
+	balance.Allowance = limit
+	balance.AllowanceExpiration = basic.Expiration
+	balance.AllowancePeriodReset = periodReset
+
+	return nil
+}
+
+// ComputeRemainingDays estimates how many days the feeder is able to pay its
+// fees, based on the fees paid per day, the available funds and the
+// expiration of the fee allowance. It returns nil, if no estimate is
+// possible.
+func ComputeRemainingDays(balance types.FeederBalance, now time.Time) *float64 {
+	var remaining *float64
+
+	if balance.FeesPerDay != nil && balance.FeesPerDay.IsPositive() {
+		available := balance.Balance
+		if balance.Allowance != nil && balance.Allowance.LT(available) {
+			available = *balance.Allowance
+		}
+
+		days := sdk.NewDecFromInt(available).Quo(*balance.FeesPerDay).MustFloat64()
+		remaining = &days
+	}
+
+	if balance.AllowanceExpiration != nil {
+		days := balance.AllowanceExpiration.Sub(now).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		if remaining == nil || days < *remaining {
+			remaining = &days
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,120 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/stretchr/testify/require"
+
+	"price-feeder/oracle/types"
+)
+
+func TestComputeRemainingDays(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	feesPerDay := sdk.NewDec(1000)
+
+	balance := types.FeederBalance{
+		Balance: sdk.NewInt(10000),
+	}
+
+	// no fees paid yet
+	require.Nil(t, ComputeRemainingDays(balance, now))
+
+	balance.FeesPerDay = &feesPerDay
+	require.Equal(t, 10.0, *ComputeRemainingDays(balance, now))
+
+	// allowance is lower than the balance
+	allowance := sdk.NewInt(5000)
+	balance.Allowance = &allowance
+	require.Equal(t, 5.0, *ComputeRemainingDays(balance, now))
+
+	// allowance expires first
+	expiration := now.Add(48 * time.Hour)
+	balance.AllowanceExpiration = &expiration
+	require.Equal(t, 2.0, *ComputeRemainingDays(balance, now))
+
+	// expired allowance
+	expiration = now.Add(-time.Hour)
+	require.Equal(t, 0.0, *ComputeRemainingDays(balance, now))
+
+	// only the expiration is known
+	balance.FeesPerDay = nil
+	expiration = now.Add(72 * time.Hour)
+	require.Equal(t, 3.0, *ComputeRemainingDays(balance, now))
+}
+
+func TestParseAllowance(t *testing.T) {
+	expiration := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	basic := &feegrant.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 1000)),
+		Expiration: &expiration,
+	}
+	allowance, err := codectypes.NewAnyWithValue(basic)
+	require.NoError(t, err)
+
+	now := expiration.Add(-24 * time.Hour)
+
+	balance := types.FeederBalance{Denom: "ukuji"}
+	require.NoError(t, ParseAllowance(allowance, &balance, now))
+	require.Equal(t, sdk.NewInt(1000), *balance.Allowance)
+	require.Equal(t, expiration, *balance.AllowanceExpiration)
+	require.Nil(t, balance.AllowancePeriodReset)
+
+	// spend limit of another denom
+	balance = types.FeederBalance{Denom: "uusk"}
+	require.NoError(t, ParseAllowance(allowance, &balance, now))
+	require.True(t, balance.Allowance.IsZero())
+
+	// periodic allowance without basic spend limit, wrapped in an allowed msg
+	// allowance, within its period
+	periodReset := now.Add(30 * time.Minute)
+	periodic, err := codectypes.NewAnyWithValue(&feegrant.PeriodicAllowance{
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("ukuji", 4)),
+		PeriodReset:      periodReset,
+	})
+	require.NoError(t, err)
+	allowance, err = codectypes.NewAnyWithValue(&feegrant.AllowedMsgAllowance{
+		Allowance:       periodic,
+		AllowedMessages: []string{"/kujira.oracle.MsgAggregateExchangeRateVote"},
+	})
+	require.NoError(t, err)
+
+	balance = types.FeederBalance{Denom: "ukuji"}
+	require.NoError(t, ParseAllowance(allowance, &balance, now))
+	require.Equal(t, sdk.NewInt(4), *balance.Allowance)
+	require.Nil(t, balance.AllowanceExpiration)
+	require.Equal(t, periodReset, *balance.AllowancePeriodReset)
+
+	// the period ended, the full period spend limit is available until the
+	// next one
+	later := now.Add(3 * time.Hour)
+	require.NoError(t, ParseAllowance(allowance, &balance, later))
+	require.Equal(t, sdk.NewInt(10), *balance.Allowance)
+	require.Equal(t, later.Add(time.Hour), *balance.AllowancePeriodReset)
+
+	// the basic spend limit is lower than the period spend limit
+	allowance, err = codectypes.NewAnyWithValue(&feegrant.PeriodicAllowance{
+		Basic: feegrant.BasicAllowance{
+			SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 2)),
+			Expiration: &expiration,
+		},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("ukuji", 4)),
+		PeriodReset:      periodReset,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ParseAllowance(allowance, &balance, now))
+	require.Equal(t, sdk.NewInt(2), *balance.Allowance)
+	require.Equal(t, expiration, *balance.AllowanceExpiration)
+	require.Equal(t, periodReset, *balance.AllowancePeriodReset)
+
+	require.Error(t, ParseAllowance(&codectypes.Any{TypeUrl: "/foo"}, &balance, now))
+}
@@ -0,0 +1,240 @@
+package oracle
+
+import (
+	"fmt"
+	"time"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// breakerHistory is the number of accepted prices a circuit breaker keeps
+// to compare new prices with.
+const breakerHistory = 10
+
+// breakerState holds the accepted prices of a denom and the prices of a
+// move that is still waiting for confirmation, together with when the last
+// of them has been counted.
+type breakerState struct {
+	history     []sdk.Dec
+	pending     []sdk.Dec
+	confirmedAt time.Time
+}
+
+// applyCircuitBreakers checks the computed prices of all denoms with a
+// circuit breaker against their last accepted prices and recent history. The
+// prices of tripped denoms are withheld or replaced by the last accepted
+// price, until the move is confirmed. It must not be called concurrently.
+func (o *Oracle) applyCircuitBreakers(
+	prices map[string]sdk.Dec,
+	audits map[string]types.PriceAudit,
+	now time.Time,
+) map[string]sdk.Dec {
+	if len(o.breakers) == 0 {
+		return prices
+	}
+
+	if o.breakerStates == nil {
+		o.breakerStates = map[string]*breakerState{}
+	}
+
+	for denom, breaker := range o.breakers {
+		state, found := o.breakerStates[denom]
+		if !found {
+			state = &breakerState{}
+			o.breakerStates[denom] = state
+		}
+
+		price, found := prices[denom]
+		if !found {
+			// a missing price interrupts consecutive confirmations
+			state.pending = nil
+			continue
+		}
+
+		reason, ok := state.check(price, breaker, audits[denom], now, o.sourceClass)
+		if ok {
+			if reason != "" {
+				o.logger.Info().
+					Str("denom", denom).
+					Str("price", price.String()).
+					Msg("price move confirmed by " + reason)
+
+				if audit, found := audits[denom]; found {
+					audit.Warnings = append(audit.Warnings, "price move confirmed by "+reason)
+					audits[denom] = audit
+				}
+			}
+			continue
+		}
+
+		reference := state.history[len(state.history)-1]
+
+		telemetry.IncrCounterWithLabels(
+			[]string{"circuit_breaker", "tripped"},
+			1,
+			[]metrics.Label{telemetry.NewLabel("denom", denom)},
+		)
+
+		o.logger.Warn().
+			Str("denom", denom).
+			Str("price", price.String()).
+			Str("last_price", reference.String()).
+			Int("pending", len(state.pending)).
+			Str("action", breaker.Action).
+			Msg("circuit breaker tripped")
+
+		warning := fmt.Sprintf(
+			"circuit breaker tripped: %s moved from %s (%d/%d confirmations)",
+			price, reference, len(state.pending), breaker.Confirmations,
+		)
+
+		if breaker.Action == types.BreakerActionLastPrice {
+			prices[denom] = reference
+			warning += ", last price used"
+		} else {
+			delete(prices, denom)
+			warning += ", withheld"
+		}
+
+		if audit, found := audits[denom]; found {
+			audit.Warnings = append(audit.Warnings, warning)
+			if breaker.Action == types.BreakerActionLastPrice {
+				audit.Price = &reference
+			} else {
+				audit.Price = nil
+				audit.Error = "circuit breaker tripped"
+			}
+			audits[denom] = audit
+		}
+	}
+
+	return prices
+}
+
+// check returns whether the price is accepted and, if it moved more than
+// allowed, by what the move has been confirmed. A price within the
+// confirmation interval of the last one counted is no new confirmation.
+func (s *breakerState) check(
+	price sdk.Dec,
+	breaker types.CircuitBreaker,
+	audit types.PriceAudit,
+	now time.Time,
+	sourceClass func(provider.Name) string,
+) (string, bool) {
+	if len(s.history) == 0 {
+		s.accept(price)
+		return "", true
+	}
+
+	reference := s.history[len(s.history)-1]
+	median, err := Median(s.history)
+	if err != nil {
+		median = reference
+	}
+
+	if !exceedsChange(price, reference, breaker.MaxChange) &&
+		!exceedsChange(price, median, breaker.MaxChange) {
+		s.accept(price)
+		return "", true
+	}
+
+	// consecutive prices must move in the same direction
+	if len(s.pending) > 0 &&
+		s.pending[0].GT(reference) != price.GT(reference) {
+		s.pending = nil
+	}
+
+	if len(s.pending) == 0 || now.Sub(s.confirmedAt) >= breaker.ConfirmationInterval {
+		s.pending = append(s.pending, price)
+		s.confirmedAt = now
+	}
+
+	if len(s.pending) >= breaker.Confirmations {
+		s.reset(price)
+		return fmt.Sprintf("%d consecutive price updates", breaker.Confirmations), true
+	}
+
+	if classes := confirmingClasses(audit, reference, breaker.MaxChange, price.GT(reference), sourceClass); classes > 1 {
+		s.reset(price)
+		return fmt.Sprintf("%d source classes", classes), true
+	}
+
+	return "", false
+}
+
+func (s *breakerState) accept(price sdk.Dec) {
+	s.pending = nil
+	s.history = append(s.history, price)
+	if len(s.history) > breakerHistory {
+		s.history = s.history[len(s.history)-breakerHistory:]
+	}
+}
+
+// reset starts a new history after a confirmed move, so the old prices
+// don't trip the breaker again.
+func (s *breakerState) reset(price sdk.Dec) {
+	s.pending = nil
+	s.history = []sdk.Dec{price}
+}
+
+// exceedsChange returns whether the price differs more than maxChange
+// percent from the reference.
+func exceedsChange(price, reference, maxChange sdk.Dec) bool {
+	if !reference.IsPositive() {
+		return false
+	}
+
+	change := price.Sub(reference).Abs().Quo(reference).MulInt64(100)
+
+	return change.GT(maxChange)
+}
+
+// confirmingClasses returns the number of source classes, whose median USD
+// price of all tickers used moved in the same direction by more than
+// maxChange percent.
+func confirmingClasses(
+	audit types.PriceAudit,
+	reference sdk.Dec,
+	maxChange sdk.Dec,
+	up bool,
+	sourceClass func(provider.Name) string,
+) int {
+	prices := map[string][]sdk.Dec{}
+	for _, ticker := range audit.Tickers {
+		if ticker.Dropped != "" {
+			continue
+		}
+
+		class := sourceClass(provider.Name(ticker.Provider))
+		prices[class] = append(prices[class], ticker.USDPrice)
+	}
+
+	classes := 0
+	for _, classPrices := range prices {
+		median, err := Median(classPrices)
+		if err != nil {
+			continue
+		}
+
+		if exceedsChange(median, reference, maxChange) && median.GT(reference) == up {
+			classes++
+		}
+	}
+
+	return classes
+}
+
+// sourceClass returns the source class of the provider, generic_http sources
+// declare theirs in the config.
+func (o *Oracle) sourceClass(name provider.Name) string {
+	endpoint, found := o.endpoints[name]
+	if found && endpoint.GenericHTTP != nil && endpoint.GenericHTTP.Class != "" {
+		return endpoint.GenericHTTP.Class
+	}
+	return name.SourceClass()
+}
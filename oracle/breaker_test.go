@@ -0,0 +1,178 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCircuitBreakers(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		breakers: map[string]types.CircuitBreaker{
+			"ATOM": {
+				MaxChange:            sdk.NewDec(10),
+				Confirmations:        3,
+				ConfirmationInterval: 30 * time.Second,
+				Action:               types.BreakerActionWithhold,
+			},
+			"KUJI": {
+				MaxChange:     sdk.NewDec(10),
+				Confirmations: 2,
+				Action:        types.BreakerActionLastPrice,
+			},
+		},
+	}
+
+	now := time.Now()
+	apply := func(atom, kuji string) map[string]sdk.Dec {
+		prices := map[string]sdk.Dec{
+			"ATOM": sdk.MustNewDecFromStr(atom),
+			"KUJI": sdk.MustNewDecFromStr(kuji),
+			"USDT": sdk.OneDec(),
+		}
+		now = now.Add(time.Minute)
+		return oracle.applyCircuitBreakers(prices, map[string]types.PriceAudit{}, now)
+	}
+
+	prices := apply("10", "1")
+	require.Equal(t, sdk.MustNewDecFromStr("10"), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("1"), prices["KUJI"])
+
+	// small moves pass
+	prices = apply("10.5", "1.05")
+	require.Equal(t, sdk.MustNewDecFromStr("10.5"), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("1.05"), prices["KUJI"])
+
+	// a single tick glitch is withheld or replaced by the last price
+	prices = apply("20", "2")
+	require.NotContains(t, prices, "ATOM")
+	require.Equal(t, sdk.MustNewDecFromStr("1.05"), prices["KUJI"])
+	require.Equal(t, sdk.OneDec(), prices["USDT"])
+
+	prices = apply("10.4", "1.04")
+	require.Equal(t, sdk.MustNewDecFromStr("10.4"), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("1.04"), prices["KUJI"])
+
+	// a lasting move is accepted after the confirmations
+	prices = apply("15", "1.5")
+	require.NotContains(t, prices, "ATOM")
+	require.Equal(t, sdk.MustNewDecFromStr("1.04"), prices["KUJI"])
+
+	prices = apply("15.2", "1.52")
+	require.NotContains(t, prices, "ATOM")
+	require.Equal(t, sdk.MustNewDecFromStr("1.52"), prices["KUJI"])
+
+	prices = apply("15.1", "1.51")
+	require.Equal(t, sdk.MustNewDecFromStr("15.1"), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("1.51"), prices["KUJI"])
+
+	// the new price is the reference now
+	prices = apply("15", "1.5")
+	require.Equal(t, sdk.MustNewDecFromStr("15"), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("1.5"), prices["KUJI"])
+
+	// price updates within the confirmation interval don't confirm a move
+	prices = apply("20", "1.5")
+	require.NotContains(t, prices, "ATOM")
+
+	for i := 0; i < 3; i++ {
+		prices = oracle.applyCircuitBreakers(
+			map[string]sdk.Dec{"ATOM": sdk.NewDec(20)},
+			map[string]types.PriceAudit{},
+			now.Add(time.Duration(i+1)*time.Second),
+		)
+		require.NotContains(t, prices, "ATOM")
+	}
+
+	prices = apply("20", "1.5")
+	require.NotContains(t, prices, "ATOM")
+
+	prices = apply("20", "1.5")
+	require.Equal(t, sdk.NewDec(20), prices["ATOM"])
+}
+
+func TestApplyCircuitBreakers_SourceClasses(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		breakers: map[string]types.CircuitBreaker{
+			"ATOM": {
+				MaxChange:     sdk.NewDec(10),
+				Confirmations: 3,
+				Action:        types.BreakerActionWithhold,
+			},
+		},
+	}
+
+	audit := func(cex, dex string) types.PriceAudit {
+		return types.PriceAudit{
+			Denom: "ATOM",
+			Tickers: []types.AuditTicker{{
+				Provider: provider.ProviderBinance.String(),
+				USDPrice: sdk.MustNewDecFromStr(cex),
+			}, {
+				Provider: provider.ProviderOsmosis.String(),
+				USDPrice: sdk.MustNewDecFromStr(dex),
+			}, {
+				Provider: provider.ProviderKraken.String(),
+				USDPrice: sdk.MustNewDecFromStr("100"),
+				Dropped:  types.AuditDroppedDeviation,
+			}},
+		}
+	}
+
+	prices := oracle.applyCircuitBreakers(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(10)},
+		map[string]types.PriceAudit{"ATOM": audit("10", "10")},
+		time.Now(),
+	)
+	require.Equal(t, sdk.NewDec(10), prices["ATOM"])
+
+	// only the dex moved
+	audits := map[string]types.PriceAudit{"ATOM": audit("10", "14")}
+	prices = oracle.applyCircuitBreakers(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(12)},
+		audits,
+		time.Now(),
+	)
+	require.NotContains(t, prices, "ATOM")
+	require.Nil(t, audits["ATOM"].Price)
+	require.Equal(t, "circuit breaker tripped", audits["ATOM"].Error)
+	require.Len(t, audits["ATOM"].Warnings, 1)
+
+	// both source classes confirm the move
+	audits = map[string]types.PriceAudit{"ATOM": audit("12", "12.4")}
+	prices = oracle.applyCircuitBreakers(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(12)},
+		audits,
+		time.Now(),
+	)
+	require.Equal(t, sdk.NewDec(12), prices["ATOM"])
+	require.Equal(t, []string{"price move confirmed by 2 source classes"}, audits["ATOM"].Warnings)
+}
+
+func TestOracle_SourceClass(t *testing.T) {
+	oracle := &Oracle{
+		endpoints: map[provider.Name]provider.Endpoint{
+			"mydex": {
+				Name:        "mydex",
+				GenericHTTP: &provider.GenericHTTP{Class: provider.SourceClassDEX},
+			},
+			"myexchange": {
+				Name:        "myexchange",
+				GenericHTTP: &provider.GenericHTTP{},
+			},
+		},
+	}
+
+	require.Equal(t, provider.SourceClassDEX, oracle.sourceClass(provider.ProviderOsmosis))
+	require.Equal(t, provider.SourceClassCEX, oracle.sourceClass(provider.ProviderBinance))
+	require.Equal(t, provider.SourceClassDEX, oracle.sourceClass("mydex"))
+	require.Equal(t, provider.SourceClassCEX, oracle.sourceClass("myexchange"))
+}
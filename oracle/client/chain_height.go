@@ -2,20 +2,60 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/rs/zerolog"
 )
 
-type ChainHeight struct {
-	Logger zerolog.Logger
-	ctx context.Context
-	rpc client.TendermintRPC
-	pollInterval time.Duration
-	height int64
-	err error
-}
+const (
+	// blockEventTimeout defines how long the last new block event may be
+	// ago, before the chain height is polled again.
+	blockEventTimeout = 30 * time.Second
+
+	blockEventSubscriber = "price-feeder"
+)
+
+// subscribeRetryInterval defines how long to wait, before subscribing to new
+// block events again, after the subscription failed or its channel has been
+// closed.
+var subscribeRetryInterval = blockEventTimeout
+
+type (
+	// ChainHeight keeps track of the current chain height. New heights are
+	// received by subscribing to new block headers via the CometBFT websocket.
+	// If the subscription fails or stops delivering events, the rpc status is
+	// polled instead.
+	ChainHeight struct {
+		Logger       zerolog.Logger
+		ctx          context.Context
+		rpc          client.TendermintRPC
+		pollInterval time.Duration
+
+		mtx       sync.RWMutex
+		height    int64
+		err       error
+		newHeight chan struct{}
+		lastEvent time.Time
+	}
+
+	// blockEventsClient defines the part of the CometBFT rpc client, that is
+	// needed to subscribe to block events.
+	blockEventsClient interface {
+		Start() error
+		Stop() error
+		Subscribe(
+			ctx context.Context,
+			subscriber, query string,
+			outCapacity ...int,
+		) (<-chan coretypes.ResultEvent, error)
+		Unsubscribe(ctx context.Context, subscriber, query string) error
+	}
+)
 
 func NewChainHeight(
 	ctx context.Context,
@@ -24,43 +64,182 @@ func NewChainHeight(
 	pollInterval time.Duration,
 ) (*ChainHeight, error) {
 	c := &ChainHeight{
-		Logger: logger.With().Str("oracle_client", "chain_height").Logger(),
-		ctx: ctx,
-		rpc: rpc,
-		height: 0,
+		Logger:       logger.With().Str("oracle_client", "chain_height").Logger(),
+		ctx:          ctx,
+		rpc:          rpc,
+		height:       0,
 		pollInterval: pollInterval,
-		err: nil,
+		err:          nil,
+		newHeight:    make(chan struct{}),
 	}
 	c.update()
+	go c.subscribe()
 	go c.poll()
 	return c, c.err
 }
 
+func (c *ChainHeight) subscribe() {
+	events, ok := c.rpc.(blockEventsClient)
+	if !ok {
+		c.Logger.Warn().Msg("rpc client doesn't support block events")
+		return
+	}
+
+	if err := events.Start(); err != nil {
+		c.Logger.Warn().Err(err).Msg("failed to start websocket client")
+		return
+	}
+
+	defer func() {
+		if err := events.Stop(); err != nil {
+			c.Logger.Debug().Err(err).Msg("failed to stop websocket client")
+		}
+	}()
+
+	query := tmtypes.EventQueryNewBlockHeader.String()
+
+	for {
+		ch, err := events.Subscribe(c.ctx, blockEventSubscriber, query)
+		if err == nil {
+			c.Logger.Info().Msg("subscribed to new block events")
+			if !c.receive(ch) {
+				return
+			}
+
+			c.Logger.Warn().Msg("new block events closed, polling the chain height")
+
+			// the subscription is dropped, so subscribing again doesn't fail
+			if err := events.Unsubscribe(c.ctx, blockEventSubscriber, query); err != nil {
+				c.Logger.Debug().Err(err).Msg("failed to unsubscribe from new block events")
+			}
+		} else {
+			c.Logger.Warn().Err(err).Msg("failed to subscribe to new block events")
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(subscribeRetryInterval):
+		}
+	}
+}
+
+// receive updates the chain height on every new block event. It returns false
+// once the context is done and true if the event channel has been closed, in
+// which case the chain height is polled again until resubscribed.
+func (c *ChainHeight) receive(ch <-chan coretypes.ResultEvent) bool {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case event, ok := <-ch:
+			if !ok {
+				c.mtx.Lock()
+				c.lastEvent = time.Time{}
+				c.mtx.Unlock()
+				return true
+			}
+
+			data, ok := event.Data.(tmtypes.EventDataNewBlockHeader)
+			if !ok {
+				continue
+			}
+
+			c.mtx.Lock()
+			c.lastEvent = time.Now()
+			c.mtx.Unlock()
+
+			c.setHeight(data.Header.Height)
+		}
+	}
+}
+
 func (c *ChainHeight) poll() {
 	for {
-		time.Sleep(c.pollInterval)
-		c.update()
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(c.pollInterval):
+		}
+
+		c.mtx.RLock()
+		subscribed := time.Since(c.lastEvent) < blockEventTimeout
+		c.mtx.RUnlock()
+
+		if !subscribed {
+			c.update()
+		}
 	}
 }
 
 func (c *ChainHeight) update() {
 	status, err := c.rpc.Status(c.ctx)
-	if err == nil {
-		if c.height < status.SyncInfo.LatestBlockHeight {
-			c.height = status.SyncInfo.LatestBlockHeight
-			c.Logger.Info().Int64("height", c.height).Msg("got new chain height")
-		} else {
-			c.Logger.Debug().
-				Int64("new", status.SyncInfo.LatestBlockHeight).
-				Int64("current", c.height).
-				Msg("ignoring stale chain height")
-		}
-	} else {
+	if err != nil {
 		c.Logger.Warn().Err(err).Msg("failed to get chain height")
+
+		c.mtx.Lock()
+		c.err = err
+		c.mtx.Unlock()
+		return
+	}
+
+	c.setHeight(status.SyncInfo.LatestBlockHeight)
+}
+
+func (c *ChainHeight) setHeight(height int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.err = nil
+
+	if c.height >= height {
+		c.Logger.Debug().
+			Int64("new", height).
+			Int64("current", c.height).
+			Msg("ignoring stale chain height")
+		return
 	}
-	c.err = err
+
+	c.height = height
+	c.Logger.Info().Int64("height", c.height).Msg("got new chain height")
+
+	// wake up everyone waiting for a new height
+	close(c.newHeight)
+	c.newHeight = make(chan struct{})
 }
 
 func (c *ChainHeight) GetChainHeight() (int64, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
 	return c.height, c.err
 }
+
+// WaitForNewHeight blocks until the chain height is greater than the given
+// height and returns the new height. An error is returned if no new height
+// has been seen within the timeout.
+func (c *ChainHeight) WaitForNewHeight(height int64, timeout time.Duration) (int64, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.mtx.RLock()
+		current, err, newHeight := c.height, c.err, c.newHeight
+		c.mtx.RUnlock()
+
+		if current > height {
+			return current, nil
+		}
+
+		select {
+		case <-newHeight:
+		case <-c.ctx.Done():
+			return current, c.ctx.Err()
+		case <-timer.C:
+			if err != nil {
+				return current, err
+			}
+			return current, fmt.Errorf("no new block since height %d", height)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRPC struct {
+	client.TendermintRPC
+
+	mtx    sync.Mutex
+	height int64
+}
+
+func (m *mockRPC) Status(context.Context) (*coretypes.ResultStatus, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return &coretypes.ResultStatus{
+		SyncInfo: coretypes.SyncInfo{LatestBlockHeight: m.height},
+	}, nil
+}
+
+func (m *mockRPC) setHeight(height int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.height = height
+}
+
+type mockEventsRPC struct {
+	*mockRPC
+
+	subscriptions chan chan coretypes.ResultEvent
+	unsubscribed  chan struct{}
+}
+
+func (m *mockEventsRPC) Start() error { return nil }
+
+func (m *mockEventsRPC) Stop() error { return nil }
+
+func (m *mockEventsRPC) Subscribe(
+	context.Context,
+	string, string,
+	...int,
+) (<-chan coretypes.ResultEvent, error) {
+	ch := make(chan coretypes.ResultEvent)
+	m.subscriptions <- ch
+	return ch, nil
+}
+
+func (m *mockEventsRPC) Unsubscribe(context.Context, string, string) error {
+	m.unsubscribed <- struct{}{}
+	return nil
+}
+
+func newBlockEvent(height int64) coretypes.ResultEvent {
+	return coretypes.ResultEvent{
+		Data: tmtypes.EventDataNewBlockHeader{
+			Header: tmtypes.Header{Height: height},
+		},
+	}
+}
+
+func TestChainHeight_WaitForNewHeight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rpc := &mockRPC{height: 10}
+
+	chainHeight, err := NewChainHeight(ctx, rpc, zerolog.Nop(), 10*time.Millisecond)
+	require.NoError(t, err)
+
+	height, err := chainHeight.GetChainHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), height)
+
+	// returns immediately, if the height is already greater
+	height, err = chainHeight.WaitForNewHeight(9, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), height)
+
+	_, err = chainHeight.WaitForNewHeight(10, 50*time.Millisecond)
+	require.Error(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		rpc.setHeight(11)
+	}()
+
+	height, err = chainHeight.WaitForNewHeight(10, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), height)
+
+	cancel()
+
+	_, err = chainHeight.WaitForNewHeight(11, time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestChainHeight_EventsClosed(t *testing.T) {
+	defer func(interval time.Duration) {
+		subscribeRetryInterval = interval
+	}(subscribeRetryInterval)
+	subscribeRetryInterval = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rpc := &mockEventsRPC{
+		mockRPC:       &mockRPC{height: 10},
+		subscriptions: make(chan chan coretypes.ResultEvent, 2),
+		unsubscribed:  make(chan struct{}, 1),
+	}
+
+	chainHeight, err := NewChainHeight(ctx, rpc, zerolog.Nop(), 10*time.Millisecond)
+	require.NoError(t, err)
+
+	events := <-rpc.subscriptions
+	events <- newBlockEvent(20)
+
+	height, err := chainHeight.WaitForNewHeight(10, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), height)
+
+	// polled again right after the events have been closed
+	rpc.setHeight(21)
+	close(events)
+
+	height, err = chainHeight.WaitForNewHeight(20, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int64(21), height)
+
+	// and subscribed again
+	<-rpc.unsubscribed
+	events = <-rpc.subscriptions
+	events <- newBlockEvent(30)
+
+	height, err = chainHeight.WaitForNewHeight(21, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), height)
+}
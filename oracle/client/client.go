@@ -26,6 +26,10 @@ import (
 	"github.com/Team-Kujira/core/app/params"
 )
 
+// newBlockTimeout defines how long to wait for the next block before giving
+// up on broadcasting a tx.
+const newBlockTimeout = 1 * time.Minute
+
 type (
 	// OracleClient defines a structure that interfaces with the Umee node.
 	OracleClient struct {
@@ -47,6 +51,8 @@ type (
 		GRPCEndpoint        string
 		KeyringPassphrase   string
 		ChainHeight         *ChainHeight
+		Fees                *FeeHistory
+		DryRun              bool
 	}
 
 	passReader struct {
@@ -71,6 +77,7 @@ func NewOracleClient(
 	gasAdjustment float64,
 	gasPrices string,
 	heightPollInterval time.Duration,
+	dryRun bool,
 ) (OracleClient, error) {
 	oracleAddr, err := sdk.AccAddressFromBech32(oracleAddrString)
 	if err != nil {
@@ -96,16 +103,27 @@ func NewOracleClient(
 		GasAdjustment:       gasAdjustment,
 		GRPCEndpoint:        grpcEndpoint,
 		GasPrices:           gasPrices,
+		Fees:                NewFeeHistory(),
+		DryRun:              dryRun,
+	}
+
+	// txs are never signed in dry run mode, so the keyring doesn't need
+	// to contain the feeder key
+	if !dryRun {
+		_, err := oracleClient.CreateClientContext()
+		if err != nil {
+			return OracleClient{}, err
+		}
 	}
 
-	clientCtx, err := oracleClient.CreateClientContext()
+	rpcClient, err := oracleClient.CreateRPCClient()
 	if err != nil {
 		return OracleClient{}, err
 	}
 
 	chainHeight, err := NewChainHeight(
 		ctx,
-		clientCtx.Client,
+		rpcClient,
 		oracleClient.Logger,
 		heightPollInterval,
 	)
@@ -151,21 +169,20 @@ func (oc OracleClient) BroadcastTx(nextBlockHeight, timeoutHeight int64, msgs ..
 		return err
 	}
 
-	// re-try voting until timeout
+	// re-try voting once per block until timeout
 	for lastCheckHeight < maxBlockHeight {
-		latestBlockHeight, err := oc.ChainHeight.GetChainHeight()
+		latestBlockHeight, err := oc.ChainHeight.WaitForNewHeight(
+			lastCheckHeight,
+			newBlockTimeout,
+		)
 		if err != nil {
 			return err
 		}
 
-		if latestBlockHeight <= lastCheckHeight {
-			continue
-		}
-
 		// set last check height to latest block height
 		lastCheckHeight = latestBlockHeight
 
-		resp, err := BroadcastTx(clientCtx, factory, msgs...)
+		resp, fee, err := BroadcastTx(clientCtx, factory, msgs...)
 		if resp != nil && resp.Code != 0 {
 			telemetry.IncrCounter(1, "failure", "tx", "code")
 			err = fmt.Errorf("invalid response code from tx: %d", resp.Code)
@@ -199,6 +216,8 @@ func (oc OracleClient) BroadcastTx(nextBlockHeight, timeoutHeight int64, msgs ..
 			Int64("tx_height", resp.Height).
 			Msg("successfully broadcasted tx")
 
+		oc.Fees.Add(fee)
+
 		return nil
 	}
 
@@ -216,14 +235,7 @@ func (oc OracleClient) CreateClientContext() (client.Context, error) {
 		return client.Context{}, err
 	}
 
-	httpClient, err := tmjsonclient.DefaultHTTPClient(oc.TMRPC)
-	if err != nil {
-		return client.Context{}, err
-	}
-
-	httpClient.Timeout = oc.RPCTimeout
-
-	tmRPC, err := rpchttp.NewWithClient(oc.TMRPC, "/websocket", httpClient)
+	tmRPC, err := oc.CreateRPCClient()
 	if err != nil {
 		return client.Context{}, err
 	}
@@ -280,6 +292,19 @@ func (oc OracleClient) CreateClientContext() (client.Context, error) {
 	return clientCtx, nil
 }
 
+// CreateRPCClient creates a Tendermint RPC client for the configured
+// endpoint, which doesn't depend on the keyring.
+func (oc OracleClient) CreateRPCClient() (*rpchttp.HTTP, error) {
+	httpClient, err := tmjsonclient.DefaultHTTPClient(oc.TMRPC)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient.Timeout = oc.RPCTimeout
+
+	return rpchttp.NewWithClient(oc.TMRPC, "/websocket", httpClient)
+}
+
 // CreateTxFactory creates an SDK Factory instance used for transaction
 // generation, signing and broadcasting.
 func (oc OracleClient) CreateTxFactory() (tx.Factory, error) {
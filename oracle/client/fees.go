@@ -0,0 +1,84 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// feeHistoryWindow defines how long paid fees are kept to estimate the fees
+// per day.
+const feeHistoryWindow = 24 * time.Hour
+
+type (
+	// FeeHistory keeps track of the fees paid for all txs broadcasted within
+	// the last feeHistoryWindow.
+	FeeHistory struct {
+		mtx     sync.Mutex
+		started time.Time
+		fees    []paidFee
+	}
+
+	paidFee struct {
+		time time.Time
+		fee  sdk.Coins
+	}
+)
+
+func NewFeeHistory() *FeeHistory {
+	return &FeeHistory{
+		started: time.Now(),
+		fees:    []paidFee{},
+	}
+}
+
+// Add records a paid fee.
+func (h *FeeHistory) Add(fee sdk.Coins) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.fees = append(h.fees, paidFee{time: time.Now(), fee: fee})
+	h.prune(time.Now())
+}
+
+// FeesPerDay returns the paid fees of the given denom extrapolated to one
+// day. The boolean is false, if no fees have been paid yet.
+func (h *FeeHistory) FeesPerDay(denom string) (sdk.Dec, bool) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	now := time.Now()
+	h.prune(now)
+
+	return feesPerDay(h.fees, denom, now.Sub(h.started))
+}
+
+func (h *FeeHistory) prune(now time.Time) {
+	i := 0
+	for i < len(h.fees) && now.Sub(h.fees[i].time) > feeHistoryWindow {
+		i++
+	}
+	h.fees = h.fees[i:]
+}
+
+func feesPerDay(fees []paidFee, denom string, elapsed time.Duration) (sdk.Dec, bool) {
+	if len(fees) == 0 || elapsed <= 0 {
+		return sdk.ZeroDec(), false
+	}
+
+	if elapsed > feeHistoryWindow {
+		elapsed = feeHistoryWindow
+	}
+
+	total := sdk.ZeroInt()
+	for _, fee := range fees {
+		total = total.Add(fee.fee.AmountOf(denom))
+	}
+
+	perDay := sdk.NewDecFromInt(total).
+		MulInt64(int64(24 * time.Hour)).
+		QuoInt64(int64(elapsed))
+
+	return perDay, true
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeesPerDay(t *testing.T) {
+	_, ok := feesPerDay([]paidFee{}, "ukuji", time.Hour)
+	require.False(t, ok)
+
+	fees := []paidFee{
+		{fee: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 100))},
+		{fee: sdk.NewCoins(sdk.NewInt64Coin("ukuji", 200), sdk.NewInt64Coin("uusk", 5))},
+	}
+
+	// extrapolated from a single hour
+	perDay, ok := feesPerDay(fees, "ukuji", time.Hour)
+	require.True(t, ok)
+	require.Equal(t, sdk.NewDec(7200), perDay)
+
+	// at most one day is considered
+	perDay, ok = feesPerDay(fees, "ukuji", 48*time.Hour)
+	require.True(t, ok)
+	require.Equal(t, sdk.NewDec(300), perDay)
+
+	perDay, ok = feesPerDay(fees, "uusk", 24*time.Hour)
+	require.True(t, ok)
+	require.Equal(t, sdk.NewDec(5), perDay)
+}
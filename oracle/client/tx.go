@@ -12,38 +12,43 @@ import (
 //
 // Note, BroadcastTx is copied from the SDK except it removes a few unnecessary
 // things like prompting for confirmation and printing the response. Instead,
-// we return the TxResponse.
-func BroadcastTx(clientCtx client.Context, txf tx.Factory, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+// we return the TxResponse and the fee of the tx.
+func BroadcastTx(
+	clientCtx client.Context,
+	txf tx.Factory,
+	msgs ...sdk.Msg,
+) (*sdk.TxResponse, sdk.Coins, error) {
 	txf, err := prepareFactory(clientCtx, txf)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	_, adjusted, err := tx.CalculateGas(clientCtx, txf, msgs...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	txf = txf.WithGas(adjusted)
 
 	unsignedTx, err := txf.BuildUnsignedTx(msgs...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	unsignedTx.SetFeeGranter(clientCtx.GetFeeGranterAddress())
 	// unsignedTx.SetFeePayer(clientCtx.GetFeePayerAddress())
 
 	if err = tx.Sign(txf, clientCtx.GetFromName(), unsignedTx, true); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	txBytes, err := clientCtx.TxConfig.TxEncoder()(unsignedTx.GetTx())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return clientCtx.BroadcastTx(txBytes)
+	resp, err := clientCtx.BroadcastTx(txBytes)
+	return resp, unsignedTx.GetTx().GetFee(), err
 }
 
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
@@ -0,0 +1,255 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+
+	"price-feeder/config"
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// applyConsistency checks the cross rates of the computed prices. Depending
+// on the configured action, inconsistencies are only logged or the faulty
+// pairs are dropped and the prices recomputed without them, while faulty
+// denoms are withheld. It returns the prices, the audits and the denoms
+// withheld.
+func (o *Oracle) applyConsistency(
+	providerPrices provider.AggregatedProviderPrices,
+	prices map[string]sdk.Dec,
+	audits map[string]types.PriceAudit,
+	options ComputeOptions,
+) (map[string]sdk.Dec, map[string]types.PriceAudit, map[string]struct{}, error) {
+	withheld := map[string]struct{}{}
+	if !o.consistency.Enabled() {
+		return prices, audits, withheld, nil
+	}
+
+	inconsistencies := findInconsistencies(
+		providerPrices, o.providerPairs, prices, audits, o.consistency.Tolerance,
+	)
+	if len(inconsistencies) == 0 {
+		return prices, audits, withheld, nil
+	}
+
+	droppedSymbols := map[string]struct{}{}
+	droppedDenoms := map[string]struct{}{}
+
+	for _, inconsistency := range inconsistencies {
+		o.logger.Warn().
+			Str("symbol", inconsistency.Symbol).
+			Str("observed", inconsistency.Observed.String()).
+			Str("implied", inconsistency.Implied.String()).
+			Str("deviation", inconsistency.Deviation.String()).
+			Str("culprit", inconsistency.Culprit).
+			Str("provider", inconsistency.Provider).
+			Str("action", o.consistency.Action).
+			Msg("inconsistent cross rate")
+
+		telemetry.IncrCounterWithLabels(
+			[]string{"consistency", "inconsistent"},
+			1,
+			[]metrics.Label{telemetry.NewLabel("symbol", inconsistency.Symbol)},
+		)
+
+		if inconsistency.Culprit == inconsistency.Symbol {
+			droppedSymbols[inconsistency.Symbol] = struct{}{}
+		} else {
+			droppedDenoms[inconsistency.Culprit] = struct{}{}
+		}
+	}
+
+	if o.consistency.Action == types.ConsistencyActionDrop {
+		if len(droppedSymbols) > 0 {
+			filtered := make(provider.AggregatedProviderPrices, len(providerPrices))
+			for providerName, tickers := range providerPrices {
+				filtered[providerName] = map[string]types.TickerPrice{}
+				for symbol, ticker := range tickers {
+					if _, found := droppedSymbols[symbol]; !found {
+						filtered[providerName][symbol] = ticker
+					}
+				}
+			}
+
+			var err error
+			prices, audits, err = GetComputedPrices(
+				o.logger,
+				filtered,
+				o.providerPairs,
+				options,
+			)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+
+		for denom := range droppedDenoms {
+			delete(prices, denom)
+			withheld[denom] = struct{}{}
+
+			if audit, found := audits[denom]; found {
+				audit.Price = nil
+				audit.Error = "inconsistent cross rates"
+				audits[denom] = audit
+			}
+		}
+	}
+
+	for _, inconsistency := range inconsistencies {
+		warning := fmt.Sprintf(
+			"%s cross rate %s deviates %s%% from implied %s, most likely caused by %s",
+			inconsistency.Symbol,
+			inconsistency.Observed,
+			inconsistency.Deviation,
+			inconsistency.Implied,
+			inconsistency.Culprit,
+		)
+		if inconsistency.Provider != "" {
+			warning += " (" + inconsistency.Provider + ")"
+		}
+
+		for _, denom := range []string{inconsistency.Base, inconsistency.Quote} {
+			if audit, found := audits[denom]; found {
+				audit.Warnings = append(audit.Warnings, warning)
+				audits[denom] = audit
+			}
+		}
+	}
+
+	return prices, audits, withheld, nil
+}
+
+// findInconsistencies compares the cross rate of every configured pair not
+// quoted in USD, the median of its tickers, with the rate implied by the USD
+// prices of its base and quote. A denom is blamed for an inconsistency, if
+// all of its two or more pairs are inconsistent, otherwise the pair itself.
+func findInconsistencies(
+	providerPrices provider.AggregatedProviderPrices,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	prices map[string]sdk.Dec,
+	audits map[string]types.PriceAudit,
+	tolerance sdk.Dec,
+) []types.Inconsistency {
+	pairs := map[string]types.CurrencyPair{}
+	for _, currencyPairs := range providerPairs {
+		for _, pair := range currencyPairs {
+			if pair.Quote != config.DenomUSD && pair.Base != pair.Quote {
+				pairs[pair.String()] = pair
+			}
+		}
+	}
+
+	checked := map[string]int{}
+	failed := map[string]int{}
+	inconsistencies := []types.Inconsistency{}
+
+	for symbol, pair := range pairs {
+		basePrice, found := prices[pair.Base]
+		if !found || !basePrice.IsPositive() {
+			continue
+		}
+		quotePrice, found := prices[pair.Quote]
+		if !found || !quotePrice.IsPositive() {
+			continue
+		}
+
+		tickers := map[provider.Name]types.TickerPrice{}
+		rates := []sdk.Dec{}
+		for providerName, providerTickers := range providerPrices {
+			if ticker, found := providerTickers[symbol]; found {
+				tickers[providerName] = ticker
+				rates = append(rates, ticker.Price)
+			}
+		}
+
+		observed, err := Median(rates)
+		if err != nil {
+			continue
+		}
+
+		checked[pair.Base]++
+		checked[pair.Quote]++
+
+		implied := basePrice.Quo(quotePrice)
+		deviation := observed.Sub(implied).Abs().Quo(implied).MulInt64(100)
+		if deviation.LTE(tolerance) {
+			continue
+		}
+
+		failed[pair.Base]++
+		failed[pair.Quote]++
+
+		inconsistencies = append(inconsistencies, types.Inconsistency{
+			Symbol:    symbol,
+			Base:      pair.Base,
+			Quote:     pair.Quote,
+			Observed:  observed,
+			Implied:   implied,
+			Deviation: deviation,
+			Culprit:   symbol,
+			Provider:  farthestTicker(tickers, implied),
+		})
+	}
+
+	for i, inconsistency := range inconsistencies {
+		culprit := ""
+		for _, denom := range []string{inconsistency.Base, inconsistency.Quote} {
+			if failed[denom] < 2 || failed[denom] != checked[denom] {
+				continue
+			}
+			if culprit == "" || failed[denom] > failed[culprit] {
+				culprit = denom
+			}
+		}
+
+		if culprit == "" {
+			continue
+		}
+
+		// the USD price of the culprit implied by the observed cross rate
+		implied := inconsistency.Observed.Mul(prices[inconsistency.Quote])
+		if culprit == inconsistency.Quote {
+			implied = prices[inconsistency.Base].Quo(inconsistency.Observed)
+		}
+
+		tickers := map[provider.Name]types.TickerPrice{}
+		for _, ticker := range audits[culprit].Tickers {
+			if ticker.Dropped == "" {
+				tickers[provider.Name(ticker.Provider)] = types.TickerPrice{Price: ticker.USDPrice}
+			}
+		}
+
+		inconsistencies[i].Culprit = culprit
+		inconsistencies[i].Provider = farthestTicker(tickers, implied)
+	}
+
+	sort.Slice(inconsistencies, func(i, j int) bool {
+		return inconsistencies[i].Symbol < inconsistencies[j].Symbol
+	})
+
+	return inconsistencies
+}
+
+// farthestTicker returns the provider whose ticker price is the farthest
+// from the given price.
+func farthestTicker(
+	tickers map[provider.Name]types.TickerPrice,
+	price sdk.Dec,
+) string {
+	farthest := ""
+	distance := sdk.ZeroDec()
+	for providerName, ticker := range tickers {
+		d := ticker.Price.Sub(price).Abs()
+		if farthest == "" || d.GT(distance) ||
+			(d.Equal(distance) && providerName.String() < farthest) {
+			farthest = providerName.String()
+			distance = d
+		}
+	}
+
+	return farthest
+}
@@ -0,0 +1,178 @@
+package oracle
+
+import (
+	"testing"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindInconsistencies(t *testing.T) {
+	ethBtc := types.CurrencyPair{Base: "ETH", Quote: "BTC"}
+	atomOsmo := types.CurrencyPair{Base: "ATOM", Quote: "OSMO"}
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderBinance: {"ETHBTC": testTicker("0.0667", "100")},
+		provider.ProviderKraken:  {"ETHBTC": testTicker("0.0666", "100")},
+		provider.ProviderOsmosis: {"ATOMOSMO": testTicker("25", "100")},
+	}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderBinance: {ethBtc},
+		provider.ProviderKraken:  {ethBtc},
+		provider.ProviderOsmosis: {atomOsmo},
+	}
+
+	prices := map[string]sdk.Dec{
+		"BTC":  sdk.NewDec(30000),
+		"ETH":  sdk.NewDec(2000),
+		"ATOM": sdk.NewDec(10),
+		"OSMO": sdk.MustNewDecFromStr("0.5"),
+	}
+
+	inconsistencies := findInconsistencies(
+		providerPrices, providerPairs, prices, nil, sdk.NewDec(2),
+	)
+	require.Len(t, inconsistencies, 1)
+	require.Equal(t, "ATOMOSMO", inconsistencies[0].Symbol)
+	require.Equal(t, sdk.NewDec(25), inconsistencies[0].Observed)
+	require.Equal(t, sdk.NewDec(20), inconsistencies[0].Implied)
+	require.Equal(t, sdk.NewDec(25), inconsistencies[0].Deviation)
+	require.Equal(t, "ATOMOSMO", inconsistencies[0].Culprit)
+	require.Equal(t, "osmosis", inconsistencies[0].Provider)
+
+	// missing prices are not checked
+	delete(prices, "OSMO")
+	require.Empty(t, findInconsistencies(
+		providerPrices, providerPairs, prices, nil, sdk.NewDec(2),
+	))
+}
+
+func TestFindInconsistencies_Denom(t *testing.T) {
+	atomOsmo := types.CurrencyPair{Base: "ATOM", Quote: "OSMO"}
+	atomBtc := types.CurrencyPair{Base: "ATOM", Quote: "BTC"}
+	osmoBtc := types.CurrencyPair{Base: "OSMO", Quote: "BTC"}
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderOsmosis: {
+			"ATOMOSMO": testTicker("20", "100"),
+			"OSMOBTC":  testTicker("0.0000166667", "100"),
+		},
+		provider.ProviderBinance: {"ATOMBTC": testTicker("0.000333333", "100")},
+	}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderOsmosis: {atomOsmo, osmoBtc},
+		provider.ProviderBinance: {atomBtc},
+	}
+
+	// the USD price of ATOM is off, all of its cross rates are inconsistent
+	prices := map[string]sdk.Dec{
+		"ATOM": sdk.NewDec(12),
+		"OSMO": sdk.MustNewDecFromStr("0.5"),
+		"BTC":  sdk.NewDec(30000),
+	}
+
+	audits := map[string]types.PriceAudit{
+		"ATOM": {
+			Tickers: []types.AuditTicker{
+				{Provider: "kraken", USDPrice: sdk.NewDec(10)},
+				{Provider: "coinbase", USDPrice: sdk.NewDec(14)},
+				{Provider: "okx", USDPrice: sdk.NewDec(30), Dropped: types.AuditDroppedDeviation},
+			},
+		},
+	}
+
+	inconsistencies := findInconsistencies(
+		providerPrices, providerPairs, prices, audits, sdk.NewDec(2),
+	)
+	require.Len(t, inconsistencies, 2)
+	require.Equal(t, "ATOMBTC", inconsistencies[0].Symbol)
+	require.Equal(t, "ATOMOSMO", inconsistencies[1].Symbol)
+
+	for _, inconsistency := range inconsistencies {
+		require.Equal(t, "ATOM", inconsistency.Culprit)
+		require.Equal(t, "coinbase", inconsistency.Provider)
+	}
+}
+
+func TestApplyConsistency(t *testing.T) {
+	atomUsd := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+	osmoUsd := types.CurrencyPair{Base: "OSMO", Quote: "USD"}
+	atomOsmo := types.CurrencyPair{Base: "ATOM", Quote: "OSMO"}
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderKraken: {
+			"ATOMUSD": testTicker("10", "100"),
+			"OSMOUSD": testTicker("0.5", "1000"),
+		},
+		provider.ProviderCoinbase: {"ATOMUSD": testTicker("10", "100")},
+		provider.ProviderOsmosis:  {"ATOMOSMO": testTicker("24", "100")},
+	}
+
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		providerPairs: map[provider.Name][]types.CurrencyPair{
+			provider.ProviderKraken:   {atomUsd, osmoUsd},
+			provider.ProviderCoinbase: {atomUsd},
+			provider.ProviderOsmosis:  {atomOsmo},
+		},
+		deviations: map[string]types.Deviation{
+			"ATOM": {
+				Method:    types.DeviationPercent,
+				Threshold: sdk.NewDec(50),
+			},
+		},
+		providerMinOverrides: map[string]int{
+			"ATOM": 1,
+			"OSMO": 1,
+		},
+		consistency: types.Consistency{
+			Tolerance: sdk.NewDec(2),
+			Action:    types.ConsistencyActionLog,
+		},
+	}
+
+	compute := func() (map[string]sdk.Dec, map[string]types.PriceAudit) {
+		prices, audits, err := GetComputedPrices(
+			oracle.logger,
+			providerPrices,
+			oracle.providerPairs,
+			ComputeOptions{
+				Deviations:           oracle.deviations,
+				ProviderMinOverrides: oracle.providerMinOverrides,
+			},
+		)
+		require.NoError(t, err)
+		return prices, audits
+	}
+
+	// (10 * 1000 + 10 * 1000 + 12 * 1200) / 3200
+	prices, audits := compute()
+	require.Equal(t, sdk.MustNewDecFromStr("10.75"), prices["ATOM"])
+
+	// only logged
+	prices, audits, withheld, err := oracle.applyConsistency(providerPrices, prices, audits, oracle.computeOptions(nil))
+	require.NoError(t, err)
+	require.Empty(t, withheld)
+	require.Equal(t, sdk.MustNewDecFromStr("10.75"), prices["ATOM"])
+	require.Len(t, audits["ATOM"].Warnings, 1)
+	require.Contains(t, audits["ATOM"].Warnings[0], "most likely caused by ATOMOSMO (osmosis)")
+	require.Contains(t, audits["OSMO"].Warnings, audits["ATOM"].Warnings[0])
+
+	// the prices are recomputed without the faulty pair
+	oracle.consistency.Action = types.ConsistencyActionDrop
+
+	prices, audits = compute()
+	prices, audits, withheld, err = oracle.applyConsistency(providerPrices, prices, audits, oracle.computeOptions(nil))
+	require.NoError(t, err)
+	require.Empty(t, withheld)
+	require.Equal(t, sdk.NewDec(10), prices["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("0.5"), prices["OSMO"])
+	require.Len(t, audits["ATOM"].Tickers, 2)
+	require.Len(t, audits["ATOM"].Warnings, 1)
+}
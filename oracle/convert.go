@@ -3,6 +3,8 @@ package oracle
 import (
 	"sort"
 
+	"price-feeder/config"
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/provider"
 	"price-feeder/oracle/types"
 
@@ -12,21 +14,27 @@ import (
 
 // convertTickersToUSD converts any tickers which are not quoted in USD to USD,
 // using the conversion rates of other tickers. It will also filter out any tickers
-// not within the deviation threshold set by the config.
+// not within the deviation threshold set by the config. The rates of each denom
+// are combined by its configured aggregator, which defaults to VWAP, weighted
+// by their USD volume limited by the volume cap of the denom and scaled by the
+// reputation scores of their providers. Stablecoins with a peg policy are
+// priced and converted according to it.
+// Alongside the rates it returns an audit trail of how each rate has been
+// computed.
 //
 // Ref: https://github.com/umee-network/umee/blob/4348c3e433df8c37dd98a690e96fc275de609bc1/price-feeder/oracle/filter.go#L41
 func convertTickersToUSD(
 	logger zerolog.Logger,
 	providerPrices provider.AggregatedProviderPrices,
 	providerPairs map[provider.Name][]types.CurrencyPair,
-	deviationThresholds map[string]sdk.Dec,
-	providerMinOverrides map[string]int,
-	providerWeights map[string]ProviderWeight,
-) (map[string]sdk.Dec, error) {
+	options ComputeOptions,
+) (map[string]sdk.Dec, map[string]types.PriceAudit, error) {
 	if len(providerPrices) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
+	auditor := newPriceAuditor()
+
 	// group ticker prices by symbol
 
 	providerPricesBySymbol := map[string]map[provider.Name]types.TickerPrice{}
@@ -57,7 +65,7 @@ func convertTickersToUSD(
 	// override volume data
 	for _, pair := range pairs {
 		base := pair.Base
-		weight, found := providerWeights[base]
+		weight, found := options.ProviderWeights[base]
 		if !found {
 			continue
 		}
@@ -67,7 +75,7 @@ func convertTickersToUSD(
 
 		tickers, err := SetWeight(tickers, weight)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		providerPricesBySymbol[symbol] = tickers
@@ -75,110 +83,159 @@ func convertTickersToUSD(
 
 	// calculate USD values
 
-	// more than 6 conversions for the USD price is probably not very accurate
-	maxConversions := 6
-	usdRates := map[string]map[provider.Name]types.TickerPrice{}
-
-	for i := 0; i < maxConversions; i++ {
-		// reorder pairs
+	// The pairs form a graph of conversions to USD. All tickers of a denom
+	// quoted in USD are used directly, all others are converted with the
+	// USD rate of their quote, which is combined from the best paths of the
+	// quote to USD. The tickers of better ranked pairs take precedence, if
+	// a provider offers multiple pairs of the same denom.
+	//
+	// The conversions are weighted by the same reputation scores and don't
+	// use the tickers of quarantined providers at all.
+	graph := newConversionGraph(
+		logger,
+		pairs,
+		conversionTickers(pairs, providerPricesBySymbol, options),
+		options.Deviations,
+		options.ProviderMinOverrides,
+		options.Aggregators,
+		options.VolumeCaps,
+		options.Pegs,
+	)
+
+	type conversion struct {
+		pair  types.CurrencyPair
+		quote quoteConversion
+	}
 
-		sort.Slice(pairs, func(i, j int) bool {
-			return pairs[i].String() < pairs[j].String()
-		})
+	conversions := map[string][]conversion{}
+	for _, pair := range pairs {
+		if len(providerPricesBySymbol[pair.String()]) == 0 {
+			continue
+		}
 
-		// Process denoms that currently have no USD rate yet at last. This allows
-		// to add more prices for already existing USD rates which are used to calculate
-		// the prices for the remaining denoms
+		if pair.Quote == config.DenomUSD {
+			conversions[pair.Base] = append(conversions[pair.Base], conversion{
+				pair: pair,
+			})
+			continue
+		}
 
-		reordered := []types.CurrencyPair{}
-		for _, pair := range pairs {
-			_, found := usdRates[pair.Base]
-			if found {
-				reordered = append([]types.CurrencyPair{pair}, reordered...)
-			} else {
-				reordered = append(reordered, pair)
-			}
+		quote, found := graph.quoteRate(pair.Quote, pair.Base)
+		if !found {
+			continue
 		}
 
-		pairs = reordered
+		conversions[pair.Base] = append(conversions[pair.Base], conversion{
+			pair:  pair,
+			quote: quote,
+		})
+	}
+
+	// length returns the number of conversions of the best path to USD, a
+	// stablecoin converted at its peg without any path counts as one
+	length := func(c conversion) int {
+		switch {
+		case c.pair.Quote == config.DenomUSD:
+			return 0
+		case len(c.quote.paths) == 0:
+			return 1
+		default:
+			return len(c.quote.paths[0].edges) + 1
+		}
+	}
 
-		unresolved := []types.CurrencyPair{}
+	usdRates := map[string]map[provider.Name]types.TickerPrice{}
 
-		for _, currencyPair := range pairs {
-			symbol := currencyPair.String()
-			base := currencyPair.Base
-			quote := currencyPair.Quote
+	for base, baseConversions := range conversions {
+		sort.SliceStable(baseConversions, func(i, j int) bool {
+			a, b := baseConversions[i], baseConversions[j]
+			if length(a) != length(b) {
+				return length(a) < length(b)
+			}
+			if len(a.quote.providers) != len(b.quote.providers) {
+				return len(a.quote.providers) > len(b.quote.providers)
+			}
+			return a.pair.String() < b.pair.String()
+		})
 
-			maxDeviation := deviationThresholds[quote]
+		for _, conversion := range baseConversions {
+			symbol := conversion.pair.String()
 			tickerPrices := providerPricesBySymbol[symbol]
 
 			newRates := map[provider.Name]types.TickerPrice{}
+			var quoteRate *sdk.Dec
 
-			if quote == "USD" {
+			// volumes are converted to USD, so tickers of different quotes
+			// are weighted alike
+			if conversion.pair.Quote == config.DenomUSD {
 				for providerName, tickerPrice := range tickerPrices {
-					newRates[providerName] = tickerPrice
+					newRates[providerName] = notionalVolume(tickerPrice)
 				}
 			} else {
-				minProviders, found := providerMinOverrides[quote]
-				if !found {
-					minProviders = 3
+				rate := conversion.quote.rate
+				quoteRate = &rate
+				auditor.addConversion(base, conversion.pair, conversion.quote)
+
+				for _, path := range conversion.quote.paths {
+					logger.Debug().
+						Str("symbol", symbol).
+						Strs("path", path.denoms()).
+						Str("rate", path.rate.String()).
+						Int("sources", path.sources).
+						Str("liquidity", path.liquidity.String()).
+						Msg("conversion path")
 				}
 
-				// a minimum of 3 usd prices are needed
-				rates, found := usdRates[quote]
-				if !found || len(rates) < minProviders {
-					unresolved = append(unresolved, currencyPair)
-					continue
+				for providerName, tickerPrice := range tickerPrices {
+					newRates[providerName] = notionalVolume(types.TickerPrice{
+						Price:  tickerPrice.Price.Mul(rate),
+						Volume: tickerPrice.Volume,
+						Time:   tickerPrice.Time,
+					})
 				}
+			}
 
-				filtered, err := FilterTickerDeviations(
-					logger, symbol, rates, maxDeviation, false,
-				)
-				if err != nil {
-					if len(rates) >= 3 {
-						unresolved = append(unresolved, currencyPair)
-						continue
-					}
+			for providerName, tickerPrice := range newRates {
+				ticker := providerPrices[providerName][symbol]
+				auditTicker := types.AuditTicker{
+					Provider:  providerName.String(),
+					Symbol:    symbol,
+					Price:     ticker.Price,
+					Volume:    ticker.Volume,
+					QuoteRate: quoteRate,
+					USDPrice:  tickerPrice.Price,
+					USDVolume: tickerPrice.Volume,
 				}
 
-				rate, err := vwapRate(filtered)
-				if err != nil {
-					return nil, err
+				if weight, found := options.ProviderWeights[base].Weight[providerName.String()]; found {
+					weight := weight
+					auditTicker.Weight = &weight
 				}
 
-				for providerName, tickerPrice := range tickerPrices {
-					newRates[providerName] = types.TickerPrice{
-						Price:  tickerPrice.Price.Mul(rate),
-						Volume: tickerPrice.Volume,
-						Time:   tickerPrice.Time,
-					}
+				if _, found := usdRates[base][providerName]; found {
+					auditTicker.Dropped = types.AuditDroppedDuplicate
 				}
+
+				auditor.addTicker(base, auditTicker)
 			}
 
-			if len(newRates) > 0 {
-				newRates, err := addRates(
-					logger,
-					symbol,
-					usdRates[base],
-					newRates,
-				)
-				if err != nil {
-					return nil, err
-				}
-				usdRates[base] = newRates
+			newRates, err := addRates(
+				logger,
+				symbol,
+				usdRates[base],
+				newRates,
+			)
+			if err != nil {
+				return nil, nil, err
 			}
+			usdRates[base] = newRates
 		}
+	}
 
-		// Stop if there are no unresolved symbols left or no symbol could
-		// be converted, in which case the list of unresolved symbols is
-		// the same as the list of pairs
-		if len(unresolved) == 0 || len(unresolved) == len(pairs) {
-			break
+	for _, pair := range pairs {
+		if _, found := usdRates[pair.Base]; !found {
+			auditor.fail(pair.Base, "no USD rate found")
 		}
-
-		// Try the next round with all unresolved symbols
-		pairs = []types.CurrencyPair{}
-		pairs = append(pairs, unresolved...)
 	}
 
 	ratesDec := map[string]sdk.Dec{}
@@ -192,14 +249,42 @@ func convertTickersToUSD(
 			)
 		}
 
-		threshold := deviationThresholds[denom]
+		if len(options.Quarantined) > 0 {
+			tickers = excludeQuarantined(tickers, options.Quarantined)
+			auditor.drop(denom, tickers, types.AuditDroppedHealth)
+			if len(tickers) == 0 {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("all providers quarantined")
+				auditor.fail(denom, "all providers quarantined by their health")
+				continue
+			}
+		}
+
+		if denomScores, found := options.Scores[denom]; found {
+			var skipped bool
+			tickers, skipped = applyScores(tickers, denomScores)
+			auditor.drop(denom, tickers, types.AuditDroppedQuarantine)
+			auditor.setScores(denom, denomScores)
+			if skipped {
+				logger.Warn().
+					Str("denom", denom).
+					Msg("all providers quarantined")
+				auditor.warn(denom, "all providers quarantined, quarantine skipped")
+			}
+		}
+
+		threshold := options.Deviations[denom]
 		filtered, err := FilterTickerDeviations(
 			logger, denom, tickers, threshold, true,
 		)
 		if err != nil {
-			minimum, found := providerMinOverrides[denom]
+			auditor.drop(denom, filtered, types.AuditDroppedSpread)
+
+			minimum, found := options.ProviderMinOverrides[denom]
 			if !found {
 				logger.Err(err)
+				auditor.fail(denom, err.Error())
 				continue
 			}
 			if len(filtered) < minimum {
@@ -208,13 +293,25 @@ func convertTickersToUSD(
 					Int("minimum", minimum).
 					Int("available", len(filtered)).
 					Msg("not enough tickers")
+				auditor.fail(denom, "not enough tickers")
 				continue
 			}
+
+			auditor.warn(denom, err.Error())
+		} else {
+			auditor.drop(denom, filtered, types.AuditDroppedDeviation)
 		}
 
-		rate, err := vwapRate(filtered)
+		if volumeCap, found := options.VolumeCaps[denom]; found {
+			var capped map[provider.Name]struct{}
+			filtered, capped = capVolumes(filtered, volumeCap)
+			auditor.capVolumes(denom, filtered, capped)
+		}
+
+		rate, err := aggregateRate(options.Aggregators, denom, filtered)
 		if err != nil {
 			logger.Err(err)
+			auditor.fail(denom, err.Error())
 			continue
 		}
 
@@ -222,10 +319,21 @@ func convertTickersToUSD(
 			logger.Error().
 				Str("denom", denom).
 				Msg("rate is zero")
+			auditor.fail(denom, "rate is zero")
 			continue
 		}
 
+		if peg, found := options.Pegs[denom]; found {
+			auditPeg := peg.Audit(rate)
+			auditor.setPeg(denom, auditPeg)
+			if auditPeg.Depegged {
+				auditor.warn(denom, "depegged, price follows the market")
+			}
+			rate = peg.Price(rate)
+		}
+
 		ratesDec[denom] = rate
+		auditor.setPrice(denom, rate)
 
 		provider.TelemetryProviderPrice(
 			"_final",
@@ -235,7 +343,58 @@ func convertTickersToUSD(
 		)
 	}
 
-	return ratesDec, nil
+	// stablecoins fixed at 1 USD keep their peg without a market price
+	for _, pair := range pairs {
+		peg, found := options.Pegs[pair.Base]
+		if !found || peg.Mode != types.PegModeFixed {
+			continue
+		}
+		if _, found := ratesDec[pair.Base]; found {
+			continue
+		}
+
+		ratesDec[pair.Base] = sdk.OneDec()
+		auditor.warn(pair.Base, "no market price, pegged at 1 USD: "+auditor.get(pair.Base).Error)
+		auditor.setPeg(pair.Base, types.AuditPeg{Mode: peg.Mode})
+		auditor.setPrice(pair.Base, sdk.OneDec())
+	}
+
+	return ratesDec, auditor.result(), nil
+}
+
+// conversionTickers returns the tickers used to convert prices between their
+// quotes. Like the tickers of the denoms, they are weighted by the reputation
+// scores of the base denom and the ones of quarantined providers are left
+// out.
+func conversionTickers(
+	pairs []types.CurrencyPair,
+	tickersBySymbol map[string]map[provider.Name]types.TickerPrice,
+	options ComputeOptions,
+) map[string]map[provider.Name]types.TickerPrice {
+	if len(options.Quarantined) == 0 && len(options.Scores) == 0 {
+		return tickersBySymbol
+	}
+
+	conversion := make(map[string]map[provider.Name]types.TickerPrice, len(pairs))
+	for _, pair := range pairs {
+		symbol := pair.String()
+		tickers := tickersBySymbol[symbol]
+		if len(tickers) == 0 {
+			continue
+		}
+
+		if len(options.Quarantined) > 0 {
+			tickers = excludeQuarantined(tickers, options.Quarantined)
+		}
+
+		if denomScores, found := options.Scores[pair.Base]; found {
+			tickers, _ = applyScores(tickers, denomScores)
+		}
+
+		conversion[symbol] = tickers
+	}
+
+	return conversion
 }
 
 func addRates(
@@ -265,11 +424,22 @@ func addRates(
 	return rates, nil
 }
 
-func vwapRate(rates map[provider.Name]types.TickerPrice) (sdk.Dec, error) {
+// aggregateRate combines the rates of all providers for the given denom with
+// its aggregator, falling back to VWAP.
+func aggregateRate(
+	aggregators map[string]aggregator.Aggregator,
+	denom string,
+	rates map[provider.Name]types.TickerPrice,
+) (sdk.Dec, error) {
 	prices := []types.TickerPrice{}
 	for _, price := range rates {
 		prices = append(prices, price)
 	}
 
-	return ComputeVWAP(prices)
+	agg, found := aggregators[denom]
+	if !found {
+		agg = aggregator.VWAP{}
+	}
+
+	return agg.Aggregate(prices)
 }
@@ -3,6 +3,7 @@ package oracle
 import (
 	"testing"
 
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/provider"
 	"price-feeder/oracle/types"
 
@@ -75,13 +76,13 @@ func TestConvertTickersToUsdChaining(t *testing.T) {
 		"ATOM":   1,
 	}
 
-	convertedTickers, err := convertTickersToUSD(
+	convertedTickers, _, err := convertTickersToUSD(
 		zerolog.Nop(),
 		providerPrices,
 		providerPairs,
-		make(map[string]sdk.Dec),
-		providerMinOverrides,
-		nil,
+		ComputeOptions{
+			ProviderMinOverrides: providerMinOverrides,
+		},
 	)
 	require.NoError(t, err)
 
@@ -159,22 +160,22 @@ func TestConvertTickersToUSDFiltering(t *testing.T) {
 		"BTC":  1,
 	}
 
-	rates, err := convertTickersToUSD(
+	rates, _, err := convertTickersToUSD(
 		zerolog.Nop(),
 		providerPrices,
 		providerPairs,
-		make(map[string]sdk.Dec),
-		prividerMinOverrides,
-		nil,
+		ComputeOptions{
+			ProviderMinOverrides: prividerMinOverrides,
+		},
 	)
 	require.NoError(t, err)
 
-	// skip BTC/USDT from Coinbase
-	// (30000*10+30010*10+30020*100) / 120 = 30017.5
+	// skip BTC/USDT from Coinbase, weighted by USD volume
+	// (30000*300000+30010*300100+30020*3002000) / 3602100 = 30017.501179...
 
 	require.Equal(
 		t,
-		sdk.MustNewDecFromStr("30017.5"),
+		sdk.MustNewDecFromStr("30017.501179867299630771"),
 		rates["BTC"],
 	)
 }
@@ -223,31 +224,31 @@ func TestConvertTickersToUsdVwap(t *testing.T) {
 		"USDT": 1,
 	}
 
-	rates, err := convertTickersToUSD(
+	rates, _, err := convertTickersToUSD(
 		zerolog.Nop(),
 		providerPrices,
 		providerPairs,
-		make(map[string]sdk.Dec),
-		providerMinOverrides,
-		nil,
+		ComputeOptions{
+			ProviderMinOverrides: providerMinOverrides,
+		},
 	)
 	require.NoError(t, err)
 
-	// VWAP( BTCUSDT * USDTUSD, BTCUSD )
-	// ((30000*0.999*55*+30050*45) / 100 = 30006.0
+	// VWAP( BTCUSDT * USDTUSD, BTCUSD ) weighted by USD volume
+	// (29970*1648350+30050*1352250) / 3000600 = 30006.052789...
 
 	require.Equal(
 		t,
-		sdk.MustNewDecFromStr("30006.0"),
+		sdk.MustNewDecFromStr("30006.052789442111577684"),
 		rates["BTC"],
 	)
 
-	// BTCUSD * ETHBTC
-	// 30050 * 0.066 = 1983.3
+	// ETHBTC * VWAP( BTCUSDT * USDTUSD, BTCUSD )
+	// 0.066 * 30006.052789... = 1980.399484...
 
 	require.Equal(
 		t,
-		sdk.MustNewDecFromStr("1983.3"),
+		sdk.MustNewDecFromStr("1980.399484103179364127"),
 		rates["ETH"],
 	)
 }
@@ -263,13 +264,11 @@ func TestConvertTickersToUsdEmptyProvider(t *testing.T) {
 		},
 	}
 
-	rates, err := convertTickersToUSD(
+	rates, _, err := convertTickersToUSD(
 		zerolog.Nop(),
 		providerPrices,
 		providerPairs,
-		make(map[string]sdk.Dec),
-		make(map[string]int),
-		nil,
+		ComputeOptions{},
 	)
 	require.NoError(t, err)
 
@@ -281,15 +280,384 @@ func TestConvertTickersToUsdEmptyPrices(t *testing.T) {
 
 	providerPairs := map[provider.Name][]types.CurrencyPair{}
 
-	rates, err := convertTickersToUSD(
+	rates, _, err := convertTickersToUSD(
 		zerolog.Nop(),
 		providerPrices,
 		providerPairs,
-		make(map[string]sdk.Dec),
-		make(map[string]int),
-		nil,
+		ComputeOptions{},
 	)
 	require.NoError(t, err)
 
 	require.Equal(t, 0, len(rates))
 }
+
+func TestConvertTickersToUsdAggregators(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{}
+
+	providerPrices[provider.ProviderBinance] = map[string]types.TickerPrice{
+		"BTCUSDT": {
+			Price:  sdk.MustNewDecFromStr("30000"),
+			Volume: sdk.MustNewDecFromStr("10"),
+		},
+	}
+
+	providerPrices[provider.ProviderKraken] = map[string]types.TickerPrice{
+		"USDTUSD": {
+			Price:  sdk.MustNewDecFromStr("0.99"),
+			Volume: sdk.MustNewDecFromStr("1000"),
+		},
+	}
+
+	providerPrices[provider.ProviderCoinbase] = map[string]types.TickerPrice{
+		"USDTUSD": {
+			Price:  sdk.MustNewDecFromStr("1"),
+			Volume: sdk.MustNewDecFromStr("1"),
+		},
+	}
+
+	providerPrices[provider.ProviderBitstamp] = map[string]types.TickerPrice{
+		"USDTUSD": {
+			Price:  sdk.MustNewDecFromStr("1.01"),
+			Volume: sdk.MustNewDecFromStr("1"),
+		},
+	}
+
+	usdtUsd := types.CurrencyPair{Base: "USDT", Quote: "USD"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderBinance:  {types.CurrencyPair{Base: "BTC", Quote: "USDT"}},
+		provider.ProviderKraken:   {usdtUsd},
+		provider.ProviderCoinbase: {usdtUsd},
+		provider.ProviderBitstamp: {usdtUsd},
+	}
+
+	deviations := map[string]types.Deviation{
+		"USDT": {Threshold: sdk.MustNewDecFromStr("2")},
+	}
+
+	providerMinOverrides := map[string]int{
+		"BTC":  1,
+		"USDT": 1,
+	}
+
+	rates, _, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			Deviations:           deviations,
+			ProviderMinOverrides: providerMinOverrides,
+		},
+	)
+	require.NoError(t, err)
+
+	// VWAP( USDTUSD ) weighted by USD volume
+	// (0.99*990+1*1+1.01*1.01) / 992.01 = 0.990030443241499582
+
+	require.Equal(
+		t,
+		sdk.MustNewDecFromStr("0.990030443241499582"),
+		rates["USDT"],
+	)
+
+	// the median is used for the final USDT rate and to convert BTCUSDT
+
+	rates, _, err = convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			Deviations:           deviations,
+			ProviderMinOverrides: providerMinOverrides,
+			Aggregators: map[string]aggregator.Aggregator{
+				"USDT": aggregator.Median{},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		sdk.MustNewDecFromStr("1"),
+		rates["USDT"],
+	)
+
+	require.Equal(
+		t,
+		sdk.MustNewDecFromStr("30000"),
+		rates["BTC"],
+	)
+}
+
+func TestConvertTickersToUsdAudits(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{}
+
+	providerPrices[provider.ProviderKraken] = map[string]types.TickerPrice{
+		"BTCUSDT": {
+			Price:  sdk.MustNewDecFromStr("30000"),
+			Volume: sdk.MustNewDecFromStr("10"),
+		},
+	}
+
+	providerPrices[provider.ProviderBinance] = map[string]types.TickerPrice{
+		"BTCUSDT": {
+			Price:  sdk.MustNewDecFromStr("30010"),
+			Volume: sdk.MustNewDecFromStr("10"),
+		},
+	}
+
+	providerPrices[provider.ProviderKucoin] = map[string]types.TickerPrice{
+		"BTCUSDT": {
+			Price:  sdk.MustNewDecFromStr("30020"),
+			Volume: sdk.MustNewDecFromStr("100"),
+		},
+	}
+
+	providerPrices[provider.ProviderCoinbase] = map[string]types.TickerPrice{
+		"BTCUSDT": {
+			Price:  sdk.MustNewDecFromStr("30450"),
+			Volume: sdk.MustNewDecFromStr("10000"),
+		},
+		"USDTUSD": {
+			Price:  sdk.MustNewDecFromStr("1"),
+			Volume: sdk.MustNewDecFromStr("10000"),
+		},
+	}
+
+	btcUsdt := types.CurrencyPair{Base: "BTC", Quote: "USDT"}
+	usdtUsd := types.CurrencyPair{Base: "USDT", Quote: "USD"}
+	ethUsdt := types.CurrencyPair{Base: "ETH", Quote: "USDT"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {btcUsdt},
+		provider.ProviderBinance:  {btcUsdt, ethUsdt},
+		provider.ProviderKucoin:   {btcUsdt},
+		provider.ProviderCoinbase: {btcUsdt, usdtUsd},
+	}
+
+	providerWeights := map[string]ProviderWeight{
+		"BTC": {
+			Type: "manual",
+			Weight: map[string]sdk.Dec{
+				provider.ProviderKucoin.String(): sdk.MustNewDecFromStr("100"),
+			},
+		},
+	}
+
+	rates, audits, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			ProviderMinOverrides: map[string]int{"USDT": 1, "BTC": 1},
+			ProviderWeights:      providerWeights,
+		},
+	)
+	require.NoError(t, err)
+
+	audit := audits["BTC"]
+	require.Equal(t, "BTC", audit.Denom)
+	require.Equal(t, rates["BTC"], *audit.Price)
+	require.Empty(t, audit.Error)
+	require.Len(t, audit.Tickers, 4)
+
+	require.Len(t, audit.Conversions, 1)
+	require.Equal(t, "USDT", audit.Conversions[0].Quote)
+	require.Equal(t, sdk.OneDec(), audit.Conversions[0].Rate)
+	require.Equal(t, []string{"coinbase"}, audit.Conversions[0].Providers)
+	require.Len(t, audit.Conversions[0].Paths, 1)
+	require.Equal(t, []string{"USDT", "USD"}, audit.Conversions[0].Paths[0].Denoms)
+
+	for _, ticker := range audit.Tickers {
+		require.Equal(t, "BTCUSDT", ticker.Symbol)
+		require.Equal(t, providerPrices[provider.Name(ticker.Provider)]["BTCUSDT"].Price, ticker.Price)
+		require.Equal(t, sdk.OneDec(), *ticker.QuoteRate)
+
+		switch ticker.Provider {
+		case provider.ProviderCoinbase.String():
+			require.Equal(t, types.AuditDroppedDeviation, ticker.Dropped)
+		case provider.ProviderKucoin.String():
+			require.Equal(t, sdk.MustNewDecFromStr("100"), *ticker.Weight)
+			require.Empty(t, ticker.Dropped)
+		default:
+			require.Nil(t, ticker.Weight)
+			require.Empty(t, ticker.Dropped)
+		}
+	}
+
+	require.Equal(t, rates["USDT"], *audits["USDT"].Price)
+	require.Nil(t, audits["USDT"].Tickers[0].QuoteRate)
+
+	require.Nil(t, audits["ETH"].Price)
+	require.Equal(t, "no USD rate found", audits["ETH"].Error)
+}
+
+func TestConvertTickersToUsdVolumeCaps(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{}
+
+	providerPrices[provider.ProviderKraken] = map[string]types.TickerPrice{
+		"BTCUSD": {
+			Price:  sdk.MustNewDecFromStr("30000"),
+			Volume: sdk.MustNewDecFromStr("90"),
+		},
+	}
+
+	providerPrices[provider.ProviderCoinbase] = map[string]types.TickerPrice{
+		"BTCUSD": {
+			Price:  sdk.MustNewDecFromStr("30100"),
+			Volume: sdk.MustNewDecFromStr("10"),
+		},
+	}
+
+	btcUsd := types.CurrencyPair{Base: "BTC", Quote: "USD"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {btcUsd},
+		provider.ProviderCoinbase: {btcUsd},
+	}
+
+	volumeCaps := map[string]types.VolumeCap{
+		"BTC": {MaxShare: sdk.NewDec(50)},
+	}
+
+	rates, audits, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			ProviderMinOverrides: map[string]int{"BTC": 1},
+			VolumeCaps:           volumeCaps,
+		},
+	)
+	require.NoError(t, err)
+
+	// both providers are limited to half of the USD volume of 3001000
+	require.Equal(t, sdk.MustNewDecFromStr("30050"), rates["BTC"])
+
+	for _, ticker := range audits["BTC"].Tickers {
+		switch ticker.Provider {
+		case provider.ProviderKraken.String():
+			require.Equal(t, sdk.MustNewDecFromStr("2700000"), ticker.USDVolume)
+			require.Equal(t, sdk.MustNewDecFromStr("1500500"), *ticker.CappedVolume)
+		case provider.ProviderCoinbase.String():
+			require.Equal(t, sdk.MustNewDecFromStr("301000"), ticker.USDVolume)
+			require.Nil(t, ticker.CappedVolume)
+		}
+	}
+}
+
+func TestConvertTickersToUsdScores(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderKraken: {
+			"BTCUSD": {
+				Price:  sdk.MustNewDecFromStr("30000"),
+				Volume: sdk.MustNewDecFromStr("10"),
+			},
+		},
+		provider.ProviderCoinbase: {
+			"BTCUSD": {
+				Price:  sdk.MustNewDecFromStr("30100"),
+				Volume: sdk.MustNewDecFromStr("10"),
+			},
+		},
+		provider.ProviderBinance: {
+			"BTCUSD": {
+				Price:  sdk.MustNewDecFromStr("33000"),
+				Volume: sdk.MustNewDecFromStr("1000"),
+			},
+		},
+	}
+
+	btcUsd := types.CurrencyPair{Base: "BTC", Quote: "USD"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {btcUsd},
+		provider.ProviderCoinbase: {btcUsd},
+		provider.ProviderBinance:  {btcUsd},
+	}
+
+	scores := map[string]map[string]types.ProviderScore{
+		"BTC": {
+			"coinbase": {Score: sdk.MustNewDecFromStr("0.5")},
+			"binance":  {Score: sdk.MustNewDecFromStr("0.1"), Quarantined: true},
+		},
+	}
+
+	rates, audits, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			ProviderMinOverrides: map[string]int{"BTC": 1},
+			Scores:               scores,
+		},
+	)
+	require.NoError(t, err)
+
+	// (30000*300000 + 30100*150500) / 450500
+	require.Equal(t, sdk.MustNewDecFromStr("30033.407325194228634850"), rates["BTC"])
+
+	for _, ticker := range audits["BTC"].Tickers {
+		switch ticker.Provider {
+		case provider.ProviderBinance.String():
+			require.Equal(t, types.AuditDroppedQuarantine, ticker.Dropped)
+			require.Equal(t, sdk.MustNewDecFromStr("0.1"), *ticker.Score)
+		case provider.ProviderCoinbase.String():
+			require.Empty(t, ticker.Dropped)
+			require.Equal(t, sdk.MustNewDecFromStr("0.5"), *ticker.Score)
+		default:
+			require.Empty(t, ticker.Dropped)
+			require.Nil(t, ticker.Score)
+		}
+	}
+}
+
+func TestConvertTickersToUsdScoredConversions(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderKraken: {
+			"USDTUSD": {Price: sdk.NewDec(2), Volume: sdk.NewDec(1000)},
+		},
+		provider.ProviderCoinbase: {
+			"USDTUSD": {Price: sdk.NewDec(1), Volume: sdk.NewDec(1000)},
+		},
+		provider.ProviderBinance: {
+			"KUJIUSDT": {Price: sdk.NewDec(1), Volume: sdk.NewDec(10)},
+		},
+	}
+
+	usdtUsd := types.CurrencyPair{Base: "USDT", Quote: "USD"}
+	kujiUsdt := types.CurrencyPair{Base: "KUJI", Quote: "USDT"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {usdtUsd},
+		provider.ProviderCoinbase: {usdtUsd},
+		provider.ProviderBinance:  {kujiUsdt},
+	}
+
+	convert := func(score types.ProviderScore) map[string]sdk.Dec {
+		rates, _, err := convertTickersToUSD(
+			zerolog.Nop(),
+			providerPrices,
+			providerPairs,
+			ComputeOptions{
+				ProviderMinOverrides: map[string]int{"USDT": 1, "KUJI": 1},
+				Scores: map[string]map[string]types.ProviderScore{
+					"USDT": {"kraken": score},
+				},
+			},
+		)
+		require.NoError(t, err)
+		return rates
+	}
+
+	// the conversion is weighted like the price of the quote:
+	// (2*2000*0.5 + 1*1000) / (2000*0.5 + 1000)
+	rates := convert(types.ProviderScore{Score: sdk.MustNewDecFromStr("0.5")})
+	require.Equal(t, sdk.MustNewDecFromStr("1.5"), rates["USDT"])
+	require.Equal(t, rates["USDT"], rates["KUJI"])
+
+	// quarantined providers are not used for conversions
+	rates = convert(types.ProviderScore{Score: sdk.MustNewDecFromStr("0.1"), Quarantined: true})
+	require.Equal(t, sdk.OneDec(), rates["USDT"])
+	require.Equal(t, sdk.OneDec(), rates["KUJI"])
+}
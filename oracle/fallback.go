@@ -0,0 +1,130 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetFallbackPrices returns the last known good prices used in the current
+// prices, sorted by denom.
+func (o *Oracle) GetFallbackPrices() []types.FallbackPrice {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	fallbackPrices := make([]types.FallbackPrice, len(o.fallbackPrices))
+	copy(fallbackPrices, o.fallbackPrices)
+
+	return fallbackPrices
+}
+
+// applyFallbacks records the computed prices of all denoms with a fallback
+// and fills in the last known good prices of those that could not be
+// computed, while they are younger than their maximum age. Prices withheld
+// or replaced by a circuit breaker and denoms withheld for inconsistent
+// cross rates are neither recorded nor filled in. It returns the prices and
+// the fallback prices used. It must not be called concurrently.
+func (o *Oracle) applyFallbacks(
+	prices map[string]sdk.Dec,
+	computed map[string]sdk.Dec,
+	withheld map[string]struct{},
+	audits map[string]types.PriceAudit,
+	now time.Time,
+) (map[string]sdk.Dec, []types.FallbackPrice) {
+	fallbackPrices := []types.FallbackPrice{}
+	if len(o.fallbacks) == 0 {
+		return prices, fallbackPrices
+	}
+
+	if o.lastGoodPrices == nil {
+		o.lastGoodPrices = map[string]types.FallbackPrice{}
+	}
+
+	for denom, fallback := range o.fallbacks {
+		labels := []metrics.Label{telemetry.NewLabel("denom", denom)}
+
+		if _, found := withheld[denom]; found {
+			telemetry.SetGaugeWithLabels([]string{"fallback", "uses"}, 0, labels)
+			continue
+		}
+
+		if computedPrice, found := computed[denom]; found {
+			if price, found := prices[denom]; found && price.Equal(computedPrice) {
+				o.lastGoodPrices[denom] = types.FallbackPrice{
+					Denom: denom,
+					Price: price,
+					Time:  now,
+				}
+			}
+
+			telemetry.SetGaugeWithLabels([]string{"fallback", "uses"}, 0, labels)
+			continue
+		}
+
+		lastGood, found := o.lastGoodPrices[denom]
+		if !found {
+			continue
+		}
+
+		age := now.Sub(lastGood.Time)
+		if age > fallback.MaxAge {
+			telemetry.SetGaugeWithLabels([]string{"fallback", "uses"}, 0, labels)
+
+			if audit, found := audits[denom]; found {
+				audit.Warnings = append(audit.Warnings, fmt.Sprintf(
+					"last known good price expired after %s", age.Round(time.Second),
+				))
+				audits[denom] = audit
+			}
+			continue
+		}
+
+		lastGood.Uses++
+		o.lastGoodPrices[denom] = lastGood
+
+		prices[denom] = lastGood.Price
+		fallbackPrices = append(fallbackPrices, lastGood)
+
+		telemetry.SetGaugeWithLabels(
+			[]string{"fallback", "uses"},
+			float32(lastGood.Uses),
+			labels,
+		)
+
+		logger := o.logger.Warn()
+		if lastGood.Uses >= fallback.AlertAfter {
+			logger = o.logger.Error()
+		}
+		if lastGood.Uses == fallback.AlertAfter {
+			telemetry.IncrCounterWithLabels([]string{"fallback", "alert"}, 1, labels)
+		}
+		logger.
+			Str("denom", denom).
+			Str("price", lastGood.Price.String()).
+			Dur("age", age).
+			Int("uses", lastGood.Uses).
+			Msg("last known good price used")
+
+		if audit, found := audits[denom]; found {
+			audit.Price = &lastGood.Price
+			audit.Fallback = &lastGood
+			audit.Warnings = append(audit.Warnings, fmt.Sprintf(
+				"last known good price from %s used (%d consecutive uses)",
+				lastGood.Time.Format(time.RFC3339), lastGood.Uses,
+			))
+			audits[denom] = audit
+		}
+	}
+
+	sort.Slice(fallbackPrices, func(i, j int) bool {
+		return fallbackPrices[i].Denom < fallbackPrices[j].Denom
+	})
+
+	return prices, fallbackPrices
+}
@@ -0,0 +1,135 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFallbacks(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		fallbacks: map[string]types.Fallback{
+			"ATOM": {MaxAge: time.Minute, AlertAfter: 2},
+		},
+	}
+
+	now := time.Now()
+	apply := func(
+		prices map[string]sdk.Dec,
+		computed map[string]sdk.Dec,
+		now time.Time,
+	) (map[string]sdk.Dec, map[string]types.PriceAudit, []types.FallbackPrice) {
+		audits := map[string]types.PriceAudit{
+			"ATOM": {Denom: "ATOM", Error: "not enough tickers"},
+		}
+		prices, fallbackPrices := oracle.applyFallbacks(prices, computed, nil, audits, now)
+		return prices, audits, fallbackPrices
+	}
+
+	// nothing to fall back to yet
+	prices, _, fallbackPrices := apply(map[string]sdk.Dec{}, map[string]sdk.Dec{}, now)
+	require.Empty(t, prices)
+	require.Empty(t, fallbackPrices)
+
+	computed := map[string]sdk.Dec{"ATOM": sdk.NewDec(10), "OSMO": sdk.NewDec(1)}
+	prices, _, fallbackPrices = apply(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(10), "OSMO": sdk.NewDec(1)},
+		computed,
+		now,
+	)
+	require.Equal(t, sdk.NewDec(10), prices["ATOM"])
+	require.Empty(t, fallbackPrices)
+
+	// prices withheld by a circuit breaker are not filled in
+	prices, _, fallbackPrices = apply(map[string]sdk.Dec{}, computed, now)
+	require.Empty(t, prices)
+	require.Empty(t, fallbackPrices)
+
+	// prices replaced by a circuit breaker are not recorded
+	prices, _, _ = apply(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(10)},
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(20)},
+		now.Add(10*time.Second),
+	)
+	require.Equal(t, sdk.NewDec(10), prices["ATOM"])
+	require.Equal(t, now, oracle.lastGoodPrices["ATOM"].Time)
+
+	prices, audits, fallbackPrices := apply(
+		map[string]sdk.Dec{}, map[string]sdk.Dec{}, now.Add(30*time.Second),
+	)
+	require.Equal(t, sdk.NewDec(10), prices["ATOM"])
+	require.Len(t, fallbackPrices, 1)
+	require.Equal(t, "ATOM", fallbackPrices[0].Denom)
+	require.Equal(t, now, fallbackPrices[0].Time)
+	require.Equal(t, 1, fallbackPrices[0].Uses)
+	require.Equal(t, sdk.NewDec(10), *audits["ATOM"].Price)
+	require.Equal(t, fallbackPrices[0], *audits["ATOM"].Fallback)
+	require.Len(t, audits["ATOM"].Warnings, 1)
+
+	_, _, fallbackPrices = apply(
+		map[string]sdk.Dec{}, map[string]sdk.Dec{}, now.Add(45*time.Second),
+	)
+	require.Equal(t, 2, fallbackPrices[0].Uses)
+
+	// expired
+	prices, audits, fallbackPrices = apply(
+		map[string]sdk.Dec{}, map[string]sdk.Dec{}, now.Add(2*time.Minute),
+	)
+	require.Empty(t, prices)
+	require.Empty(t, fallbackPrices)
+	require.Nil(t, audits["ATOM"].Price)
+	require.Contains(t, audits["ATOM"].Warnings[0], "last known good price expired")
+
+	// a computed price resets the uses
+	apply(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(11)},
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(11)},
+		now.Add(3*time.Minute),
+	)
+	_, _, fallbackPrices = apply(
+		map[string]sdk.Dec{}, map[string]sdk.Dec{}, now.Add(3*time.Minute+time.Second),
+	)
+	require.Equal(t, sdk.NewDec(11), fallbackPrices[0].Price)
+	require.Equal(t, 1, fallbackPrices[0].Uses)
+}
+
+func TestApplyFallbacks_Withheld(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		fallbacks: map[string]types.Fallback{
+			"ATOM": {MaxAge: time.Minute, AlertAfter: 2},
+		},
+	}
+
+	now := time.Now()
+	oracle.applyFallbacks(
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(10)},
+		map[string]sdk.Dec{"ATOM": sdk.NewDec(10)},
+		nil,
+		map[string]types.PriceAudit{},
+		now,
+	)
+
+	// denoms withheld for inconsistent cross rates are not filled in
+	audits := map[string]types.PriceAudit{
+		"ATOM": {Denom: "ATOM", Error: "inconsistent cross rates"},
+	}
+	prices, fallbackPrices := oracle.applyFallbacks(
+		map[string]sdk.Dec{},
+		map[string]sdk.Dec{},
+		map[string]struct{}{"ATOM": {}},
+		audits,
+		now.Add(10*time.Second),
+	)
+	require.Empty(t, prices)
+	require.Empty(t, fallbackPrices)
+	require.Nil(t, audits["ATOM"].Price)
+	require.Equal(t, now, oracle.lastGoodPrices["ATOM"].Time)
+	require.Zero(t, oracle.lastGoodPrices["ATOM"].Uses)
+}
@@ -1,6 +1,8 @@
 package oracle
 
 import (
+	"fmt"
+
 	"price-feeder/oracle/provider"
 
 	"price-feeder/oracle/types"
@@ -16,23 +18,47 @@ import (
 // in the config.
 var defaultDeviationThreshold = sdk.MustNewDecFromStr("1.0")
 
-// FilterTickerDeviations finds the standard deviations of the prices of
-// all assets, and filters out any providers that are not within 2𝜎 of the mean.
+// defaultDeviationThresholds defines the default thresholds of the other
+// filter methods: 3 scaled MADs or 1% around the median.
+var defaultDeviationThresholds = map[string]sdk.Dec{
+	types.DeviationStdDev:  defaultDeviationThreshold,
+	types.DeviationMAD:     sdk.MustNewDecFromStr("3.0"),
+	types.DeviationPercent: sdk.MustNewDecFromStr("1.0"),
+}
+
+// madScale scales the median absolute deviation to a consistent estimator
+// of 𝜎 for normally distributed prices. As the MAD is 0 once more than half
+// of the prices are equal, the margin around the median is at least
+// minMADMargin percent of it.
+var (
+	madScale     = sdk.MustNewDecFromStr("1.4826")
+	minMADMargin = sdk.MustNewDecFromStr("0.1")
+)
 
 func isBetween(p, mean, margin sdk.Dec) bool {
 	return p.GTE(mean.Sub(margin)) &&
 		p.LTE(mean.Add(margin))
 }
 
+// FilterTickerDeviations filters out the prices of all providers that are
+// too far away from the others, using the method of the given deviation:
+// a multiple of 𝜎 around the mean (default), a multiple of the scaled median
+// absolute deviation around the median or a percentage band around the
+// median.
 func FilterTickerDeviations(
 	logger zerolog.Logger,
 	symbol string,
 	tickerPrices map[provider.Name]types.TickerPrice,
-	deviationThreshold sdk.Dec,
+	deviation types.Deviation,
 	stats bool,
 ) (map[provider.Name]types.TickerPrice, error) {
+	if deviation.Method == "" {
+		deviation.Method = types.DeviationStdDev
+	}
+
+	deviationThreshold := deviation.Threshold
 	if deviationThreshold.IsNil() {
-		deviationThreshold = defaultDeviationThreshold
+		deviationThreshold = defaultDeviationThresholds[deviation.Method]
 	}
 
 	prices := []sdk.Dec{}
@@ -40,9 +66,44 @@ func FilterTickerDeviations(
 		prices = append(prices, tickerPrice.Price)
 	}
 
-	deviation, mean, err := StandardDeviation(prices)
-	if err != nil {
-		return tickerPrices, err
+	if len(prices) == 2 && !deviation.MaxSpread.IsNil() {
+		return filterSpread(logger, symbol, tickerPrices, prices, deviation.MaxSpread)
+	}
+
+	var (
+		center sdk.Dec
+		margin sdk.Dec
+		err    error
+	)
+
+	switch deviation.Method {
+	case types.DeviationMAD:
+		var mad sdk.Dec
+		mad, center, err = MedianAbsoluteDeviation(prices)
+		if err != nil {
+			return tickerPrices, err
+		}
+		margin = mad.Mul(madScale).Mul(deviationThreshold)
+
+		minMargin := center.Mul(minMADMargin).QuoInt64(100)
+		if margin.LT(minMargin) {
+			margin = minMargin
+		}
+
+	case types.DeviationPercent:
+		center, err = Median(prices)
+		if err != nil {
+			return tickerPrices, err
+		}
+		margin = center.Mul(deviationThreshold).QuoInt64(100)
+
+	default:
+		var deviation sdk.Dec
+		deviation, center, err = StandardDeviation(prices)
+		if err != nil {
+			return tickerPrices, err
+		}
+		margin = deviation.Mul(deviationThreshold)
 	}
 
 	if stats {
@@ -50,38 +111,73 @@ func FilterTickerDeviations(
 			telemetry.NewLabel("symbol", symbol),
 		}
 
-		margin := deviation.Mul(deviationThreshold)
-
 		telemetry.SetGaugeWithLabels(
 			[]string{"deviation", "high"},
-			float32(mean.Add(margin).MustFloat64()),
+			float32(center.Add(margin).MustFloat64()),
 			labels,
 		)
 		telemetry.SetGaugeWithLabels(
 			[]string{"deviation", "low"},
-			float32(mean.Sub(margin).MustFloat64()),
+			float32(center.Sub(margin).MustFloat64()),
 			labels,
 		)
 	}
 
-	// We accept any prices that are within (2 * T)𝜎, or for which we couldn't get 𝜎.
-	// T is defined as the deviation threshold, either set by the config
-	// or defaulted to 1.
+	// We accept any prices that are within the margin around the center, or
+	// for which we couldn't get the margin. The margin is defined by the
+	// deviation threshold, either set by the config or defaulted per method.
 	filteredPrices := map[provider.Name]types.TickerPrice{}
 	for providerName, tickerPrice := range tickerPrices {
-		if isBetween(tickerPrice.Price, mean, deviation.Mul(deviationThreshold)) {
+		if isBetween(tickerPrice.Price, center, margin) {
 			filteredPrices[providerName] = tickerPrice
 		} else {
 			telemetry.IncrCounter(1, "failure", "provider", "type", "ticker")
 			logger.Debug().
 				Str("symbol", symbol).
 				Str("provider", providerName.String()).
+				Str("method", deviation.Method).
 				Str("price", tickerPrice.Price.String()).
-				Str("mean", mean.String()).
-				Str("margin", deviation.Mul(deviationThreshold).String()).
+				Str("center", center.String()).
+				Str("margin", margin.String()).
 				Msg("deviating price")
 		}
 	}
 
 	return filteredPrices, nil
 }
+
+// filterSpread accepts both prices, if they are within maxSpread percent of
+// their mean. As there is no way to tell which one is wrong otherwise, none
+// of them is accepted if they aren't.
+func filterSpread(
+	logger zerolog.Logger,
+	symbol string,
+	tickerPrices map[provider.Name]types.TickerPrice,
+	prices []sdk.Dec,
+	maxSpread sdk.Dec,
+) (map[provider.Name]types.TickerPrice, error) {
+	center, err := Median(prices)
+	if err != nil {
+		return tickerPrices, err
+	}
+
+	if !center.IsPositive() {
+		return tickerPrices, fmt.Errorf("invalid prices for %s", symbol)
+	}
+
+	spread := prices[0].Sub(prices[1]).Abs().Quo(center).MulInt64(100)
+	if spread.LTE(maxSpread) {
+		return tickerPrices, nil
+	}
+
+	telemetry.IncrCounter(1, "failure", "provider", "type", "spread")
+	logger.Warn().
+		Str("symbol", symbol).
+		Str("spread", spread.String()).
+		Str("max_spread", maxSpread.String()).
+		Msg("prices of two providers exceed max spread")
+
+	return map[provider.Name]types.TickerPrice{}, fmt.Errorf(
+		"spread of %s exceeds max spread: %s%%", symbol, spread,
+	)
+}
@@ -38,7 +38,7 @@ func TestSuccessFilterTickerDeviations(t *testing.T) {
 		zerolog.Nop(),
 		pair.String(),
 		providerTickers,
-		sdk.Dec{},
+		types.Deviation{},
 		false,
 	)
 
@@ -46,7 +46,7 @@ func TestSuccessFilterTickerDeviations(t *testing.T) {
 	require.NoError(t, err, "It should successfully filter out the provider using tickers")
 	require.False(t, ok, "The filtered ticker deviation price at coinbase should be empty")
 
-	customDeviation := sdk.NewDec(2)
+	customDeviation := types.Deviation{Threshold: sdk.NewDec(2)}
 
 	pricesFilteredCustom, err := FilterTickerDeviations(
 		zerolog.Nop(),
@@ -89,7 +89,7 @@ func TestSuccessFilterTickerDeviations2(t *testing.T) {
 		zerolog.Nop(),
 		pair.String(),
 		tickerPrices,
-		sdk.NewDec(1),
+		types.Deviation{Threshold: sdk.NewDec(1)},
 		false,
 	)
 
@@ -106,3 +106,166 @@ func TestSuccessFilterTickerDeviations2(t *testing.T) {
 		require.Equal(t, tickerPrice, filteredPrice)
 	}
 }
+
+func TestFilterTickerDeviationsMAD(t *testing.T) {
+	tickerPrices := map[provider.Name]types.TickerPrice{
+		provider.ProviderBinance:  {Price: sdk.MustNewDecFromStr("10.0")},
+		provider.ProviderHuobi:    {Price: sdk.MustNewDecFromStr("10.1")},
+		provider.ProviderKraken:   {Price: sdk.MustNewDecFromStr("9.9")},
+		provider.ProviderCoinbase: {Price: sdk.MustNewDecFromStr("10.3")},
+		provider.ProviderOkx:      {Price: sdk.MustNewDecFromStr("15")},
+	}
+
+	// median: 10.1, MAD: 0.2, band: 9.21 - 10.99
+	filteredPrices, err := FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{Method: types.DeviationMAD},
+		false,
+	)
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 4)
+	require.NotContains(t, filteredPrices, provider.ProviderOkx)
+
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{
+			Method:    types.DeviationMAD,
+			Threshold: sdk.MustNewDecFromStr("0.5"),
+		},
+		false,
+	)
+	// band: 9.95 - 10.25
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 2)
+	require.NotContains(t, filteredPrices, provider.ProviderKraken)
+	require.NotContains(t, filteredPrices, provider.ProviderCoinbase)
+
+	// not enough prices
+	delete(tickerPrices, provider.ProviderOkx)
+	delete(tickerPrices, provider.ProviderCoinbase)
+	delete(tickerPrices, provider.ProviderKraken)
+
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{Method: types.DeviationMAD},
+		false,
+	)
+	require.Error(t, err)
+	require.Equal(t, tickerPrices, filteredPrices)
+}
+
+func TestFilterTickerDeviationsMAD_EqualPrices(t *testing.T) {
+	tickerPrices := map[provider.Name]types.TickerPrice{
+		provider.ProviderBinance:  {Price: sdk.MustNewDecFromStr("10.00")},
+		provider.ProviderHuobi:    {Price: sdk.MustNewDecFromStr("10.00")},
+		provider.ProviderKraken:   {Price: sdk.MustNewDecFromStr("10.00")},
+		provider.ProviderCoinbase: {Price: sdk.MustNewDecFromStr("10.0001")},
+		provider.ProviderOkx:      {Price: sdk.MustNewDecFromStr("10.5")},
+	}
+
+	// MAD: 0, band: 9.99 - 10.01
+	filteredPrices, err := FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{Method: types.DeviationMAD},
+		false,
+	)
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 4)
+	require.Contains(t, filteredPrices, provider.ProviderCoinbase)
+	require.NotContains(t, filteredPrices, provider.ProviderOkx)
+}
+
+func TestFilterTickerDeviationsPercent(t *testing.T) {
+	tickerPrices := map[provider.Name]types.TickerPrice{
+		provider.ProviderBinance: {Price: sdk.MustNewDecFromStr("100")},
+		provider.ProviderKraken:  {Price: sdk.MustNewDecFromStr("104")},
+	}
+
+	// median: 102, band: 97.92 - 106.08
+	filteredPrices, err := FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{
+			Method:    types.DeviationPercent,
+			Threshold: sdk.MustNewDecFromStr("4"),
+		},
+		false,
+	)
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 2)
+
+	// median: 102, band: 100.98 - 103.02
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{Method: types.DeviationPercent},
+		false,
+	)
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 0)
+
+	tickerPrices[provider.ProviderHuobi] = types.TickerPrice{
+		Price: sdk.MustNewDecFromStr("101"),
+	}
+
+	// median: 101, band: 99.99 - 102.01
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{Method: types.DeviationPercent},
+		false,
+	)
+	require.NoError(t, err)
+	require.Len(t, filteredPrices, 2)
+	require.NotContains(t, filteredPrices, provider.ProviderKraken)
+}
+
+func TestFilterTickerDeviationsMaxSpread(t *testing.T) {
+	tickerPrices := map[provider.Name]types.TickerPrice{
+		provider.ProviderBinance: {Price: sdk.MustNewDecFromStr("100")},
+		provider.ProviderKraken:  {Price: sdk.MustNewDecFromStr("102")},
+	}
+
+	// spread: 2 / 101 = 1.98%
+	filteredPrices, err := FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{MaxSpread: sdk.MustNewDecFromStr("2")},
+		false,
+	)
+	require.NoError(t, err)
+	require.Equal(t, tickerPrices, filteredPrices)
+
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{MaxSpread: sdk.MustNewDecFromStr("1.5")},
+		false,
+	)
+	require.Error(t, err)
+	require.Len(t, filteredPrices, 0)
+
+	// without max spread two prices can't be filtered by the std deviation
+	filteredPrices, err = FilterTickerDeviations(
+		zerolog.Nop(),
+		"ATOMUSDT",
+		tickerPrices,
+		types.Deviation{},
+		false,
+	)
+	require.Error(t, err)
+	require.Equal(t, tickerPrices, filteredPrices)
+}
@@ -0,0 +1,430 @@
+package oracle
+
+import (
+	"sort"
+
+	"price-feeder/config"
+	"price-feeder/oracle/aggregator"
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// maxConversions limits the length of conversion paths, as prices
+	// converted more than 6 times are probably not very accurate.
+	maxConversions = 6
+
+	// maxPaths limits the number of paths the USD rate of a quote is
+	// computed from.
+	maxPaths = 3
+)
+
+type (
+	// conversionGraph models all currency pairs as edges from base to quote
+	// to find the best paths to convert prices to USD. The USD rates of the
+	// quotes are cached, as many pairs share the same quote.
+	conversionGraph struct {
+		logger               zerolog.Logger
+		edges                map[string][]*conversionEdge
+		deviations           map[string]types.Deviation
+		providerMinOverrides map[string]int
+		aggregators          map[string]aggregator.Aggregator
+		volumeCaps           map[string]types.VolumeCap
+		pegs                 map[string]types.Peg
+		distances            map[string]int
+		quoteRates           map[types.CurrencyPair]cachedQuoteRate
+	}
+
+	// cachedQuoteRate is the result of quoteRate for a quote and the base
+	// converted with it.
+	cachedQuoteRate struct {
+		conversion quoteConversion
+		found      bool
+	}
+
+	// conversionEdge holds the tickers of a currency pair and its rate
+	// computed from the tickers that passed the deviation filter. The
+	// volumes of the filtered tickers are the capped notional volumes in the
+	// quote denom.
+	conversionEdge struct {
+		pair     types.CurrencyPair
+		tickers  map[provider.Name]types.TickerPrice
+		filtered map[provider.Name]types.TickerPrice
+		rate     sdk.Dec
+	}
+
+	// conversionPath is a path of edges from a denom to USD. Sources is the
+	// lowest number of providers of all edges and liquidity the USD volume of
+	// the first edge.
+	conversionPath struct {
+		edges     []*conversionEdge
+		rate      sdk.Dec
+		sources   int
+		liquidity sdk.Dec
+	}
+
+	// quoteConversion is the USD rate of a quote combined from its best
+	// paths. The peg is set, if the rate is subject to the peg policy of a
+	// stablecoin quote.
+	quoteConversion struct {
+		rate      sdk.Dec
+		paths     []conversionPath
+		providers map[provider.Name]struct{}
+		dropped   map[provider.Name]struct{}
+		peg       *types.AuditPeg
+	}
+)
+
+func newConversionGraph(
+	logger zerolog.Logger,
+	pairs []types.CurrencyPair,
+	tickersBySymbol map[string]map[provider.Name]types.TickerPrice,
+	deviations map[string]types.Deviation,
+	providerMinOverrides map[string]int,
+	aggregators map[string]aggregator.Aggregator,
+	volumeCaps map[string]types.VolumeCap,
+	pegs map[string]types.Peg,
+) *conversionGraph {
+	graph := &conversionGraph{
+		logger:               logger,
+		edges:                map[string][]*conversionEdge{},
+		deviations:           deviations,
+		providerMinOverrides: providerMinOverrides,
+		aggregators:          aggregators,
+		volumeCaps:           volumeCaps,
+		pegs:                 pegs,
+		quoteRates:           map[types.CurrencyPair]cachedQuoteRate{},
+	}
+
+	for _, pair := range pairs {
+		tickers := tickersBySymbol[pair.String()]
+		if len(tickers) == 0 || pair.Base == pair.Quote {
+			continue
+		}
+
+		edge := &conversionEdge{
+			pair:    pair,
+			tickers: tickers,
+		}
+
+		// without a rate the edge can still provide USD prices for its base,
+		// but it can't be used to convert the prices of other denoms
+		graph.setRate(edge)
+
+		graph.edges[pair.Base] = append(graph.edges[pair.Base], edge)
+	}
+
+	for _, edges := range graph.edges {
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].pair.String() < edges[j].pair.String()
+		})
+	}
+
+	graph.distances = graph.distancesToUSD()
+
+	return graph
+}
+
+// setRate filters the tickers of the edge and combines them to its rate
+// using the deviation filter, volume cap and aggregator of the base denom.
+// The tickers are weighted by their notional volume in the quote, which is
+// proportional to their USD volume, as all tickers share the same quote.
+func (g *conversionGraph) setRate(edge *conversionEdge) {
+	base := edge.pair.Base
+
+	tickers := make(map[provider.Name]types.TickerPrice, len(edge.tickers))
+	for providerName, ticker := range edge.tickers {
+		tickers[providerName] = notionalVolume(ticker)
+	}
+
+	filtered, err := FilterTickerDeviations(
+		g.logger, edge.pair.String(), tickers, g.deviations[base], false,
+	)
+	if err != nil && (len(edge.tickers) >= 3 || len(filtered) == 0) {
+		return
+	}
+
+	if volumeCap, found := g.volumeCaps[base]; found {
+		filtered, _ = capVolumes(filtered, volumeCap)
+	}
+
+	rate, err := aggregateRate(g.aggregators, base, filtered)
+	if err != nil || !rate.IsPositive() {
+		return
+	}
+
+	edge.filtered = filtered
+	edge.rate = rate
+}
+
+// usable returns whether the edge has a rate to convert prices.
+func (e *conversionEdge) usable() bool {
+	return e.filtered != nil
+}
+
+// distancesToUSD returns the lowest number of conversions of each denom to
+// USD, which is used to skip denoms that can't reach USD anymore.
+func (g *conversionGraph) distancesToUSD() map[string]int {
+	distances := map[string]int{config.DenomUSD: 0}
+
+	for distance := 1; distance <= maxConversions; distance++ {
+		changed := false
+		for base, edges := range g.edges {
+			if _, found := distances[base]; found {
+				continue
+			}
+			for _, edge := range edges {
+				if !edge.usable() {
+					continue
+				}
+				quoteDistance, found := distances[edge.pair.Quote]
+				if found && quoteDistance == distance-1 {
+					distances[base] = distance
+					changed = true
+					break
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return distances
+}
+
+// paths returns the best paths from the denom to USD that don't pass any
+// of the excluded denoms, ranked by length, sources and liquidity.
+func (g *conversionGraph) paths(
+	denom string,
+	exclude map[string]struct{},
+) []conversionPath {
+	type partial struct {
+		edges   []*conversionEdge
+		visited map[string]struct{}
+	}
+
+	paths := []conversionPath{}
+
+	frontier := []partial{{
+		visited: map[string]struct{}{denom: {}},
+	}}
+
+	for length := 1; length <= maxConversions && len(frontier) > 0; length++ {
+		next := []partial{}
+
+		for _, p := range frontier {
+			current := denom
+			if len(p.edges) > 0 {
+				current = p.edges[len(p.edges)-1].pair.Quote
+			}
+
+			for _, edge := range g.edges[current] {
+				quote := edge.pair.Quote
+				if !edge.usable() {
+					continue
+				}
+				if _, found := exclude[quote]; found {
+					continue
+				}
+				if _, found := p.visited[quote]; found {
+					continue
+				}
+
+				// skip denoms that can't reach USD in the remaining conversions
+				distance, found := g.distances[quote]
+				if !found || length+distance > maxConversions {
+					continue
+				}
+
+				edges := append(append([]*conversionEdge{}, p.edges...), edge)
+
+				if quote == config.DenomUSD {
+					paths = append(paths, newConversionPath(edges))
+					continue
+				}
+
+				visited := make(map[string]struct{}, len(p.visited)+1)
+				for visitedDenom := range p.visited {
+					visited[visitedDenom] = struct{}{}
+				}
+				visited[quote] = struct{}{}
+
+				next = append(next, partial{edges: edges, visited: visited})
+			}
+		}
+
+		// longer paths are ranked lower, so no need to look for them
+		if len(paths) >= maxPaths {
+			break
+		}
+
+		frontier = next
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i].edges) != len(paths[j].edges) {
+			return len(paths[i].edges) < len(paths[j].edges)
+		}
+		if paths[i].sources != paths[j].sources {
+			return paths[i].sources > paths[j].sources
+		}
+		return paths[i].liquidity.GT(paths[j].liquidity)
+	})
+
+	if len(paths) > maxPaths {
+		paths = paths[:maxPaths]
+	}
+
+	return paths
+}
+
+func newConversionPath(edges []*conversionEdge) conversionPath {
+	path := conversionPath{
+		edges:     edges,
+		rate:      sdk.OneDec(),
+		liquidity: sdk.ZeroDec(),
+	}
+
+	for i, edge := range edges {
+		path.rate = path.rate.Mul(edge.rate)
+
+		if i == 0 || len(edge.filtered) < path.sources {
+			path.sources = len(edge.filtered)
+		}
+	}
+
+	// the liquidity is the uncapped base volume of the first edge valued at
+	// the USD rate of the path
+	for providerName := range edges[0].filtered {
+		path.liquidity = path.liquidity.Add(edges[0].tickers[providerName].Volume)
+	}
+	path.liquidity = path.liquidity.Mul(path.rate)
+
+	return path
+}
+
+// quoteRate returns the USD rate of the quote, computed once per quote and
+// converted base. The market rate of a pegged stablecoin is replaced by its
+// conversion rate and a stablecoin fixed at 1 USD is converted at the peg
+// even without a market rate. The conversion must not be modified.
+func (g *conversionGraph) quoteRate(
+	quote string,
+	base string,
+) (quoteConversion, bool) {
+	key := types.CurrencyPair{Base: base, Quote: quote}
+	if cached, found := g.quoteRates[key]; found {
+		return cached.conversion, cached.found
+	}
+
+	conversion, found := g.computeQuoteRate(quote, base)
+	g.quoteRates[key] = cachedQuoteRate{conversion: conversion, found: found}
+
+	return conversion, found
+}
+
+func (g *conversionGraph) computeQuoteRate(
+	quote string,
+	base string,
+) (quoteConversion, bool) {
+	conversion, found := g.marketRate(quote, base)
+
+	peg, pegged := g.pegs[quote]
+	if !pegged {
+		return conversion, found
+	}
+
+	if !found {
+		if peg.Mode != types.PegModeFixed {
+			return quoteConversion{}, false
+		}
+
+		return quoteConversion{
+			rate:      sdk.OneDec(),
+			providers: map[provider.Name]struct{}{},
+			dropped:   map[provider.Name]struct{}{},
+			peg:       &types.AuditPeg{Mode: peg.Mode},
+		}, true
+	}
+
+	audit := peg.Audit(conversion.rate)
+	conversion.rate = peg.ConversionRate(conversion.rate)
+	conversion.peg = &audit
+
+	return conversion, true
+}
+
+// marketRate returns the USD rate of the quote, combined from its best paths
+// weighted by their liquidity. The paths must not pass the base denom that
+// is converted. It fails if less than the minimum providers of the quote
+// provide the first conversions of the paths.
+func (g *conversionGraph) marketRate(
+	quote string,
+	base string,
+) (quoteConversion, bool) {
+	paths := g.paths(quote, map[string]struct{}{base: {}})
+	if len(paths) == 0 {
+		return quoteConversion{}, false
+	}
+
+	conversion := quoteConversion{
+		paths:     paths,
+		providers: map[provider.Name]struct{}{},
+		dropped:   map[provider.Name]struct{}{},
+	}
+
+	prices := []types.TickerPrice{}
+	for _, path := range paths {
+		prices = append(prices, types.TickerPrice{
+			Price:  path.rate,
+			Volume: path.liquidity,
+		})
+
+		edge := path.edges[0]
+		for providerName := range edge.tickers {
+			if _, found := edge.filtered[providerName]; found {
+				conversion.providers[providerName] = struct{}{}
+			} else {
+				conversion.dropped[providerName] = struct{}{}
+			}
+		}
+	}
+
+	for providerName := range conversion.providers {
+		delete(conversion.dropped, providerName)
+	}
+
+	minProviders, found := g.providerMinOverrides[quote]
+	if !found {
+		minProviders = 3
+	}
+
+	// like the minimum providers of the final rates, this includes the
+	// providers dropped by the deviation filter
+	if len(conversion.providers)+len(conversion.dropped) < minProviders {
+		return quoteConversion{}, false
+	}
+
+	rate, err := aggregator.ComputeVWAP(prices)
+	if err != nil {
+		return quoteConversion{}, false
+	}
+
+	conversion.rate = rate
+
+	return conversion, true
+}
+
+// denoms returns the denoms of the path, starting with the base of the
+// first edge.
+func (p conversionPath) denoms() []string {
+	denoms := []string{p.edges[0].pair.Base}
+	for _, edge := range p.edges {
+		denoms = append(denoms, edge.pair.Quote)
+	}
+
+	return denoms
+}
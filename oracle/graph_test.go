@@ -0,0 +1,157 @@
+package oracle
+
+import (
+	"testing"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConversionGraph(
+	tickers map[string]map[provider.Name]types.TickerPrice,
+	providerMinOverrides map[string]int,
+) *conversionGraph {
+	pairs := []types.CurrencyPair{}
+	for symbol := range tickers {
+		for _, quote := range []string{"USDC", "USDT", "USD", "OSMO", "ATOM"} {
+			if len(symbol) > len(quote) && symbol[len(symbol)-len(quote):] == quote {
+				pairs = append(pairs, types.CurrencyPair{
+					Base:  symbol[:len(symbol)-len(quote)],
+					Quote: quote,
+				})
+				break
+			}
+		}
+	}
+
+	return newConversionGraph(
+		zerolog.Nop(),
+		pairs,
+		tickers,
+		map[string]types.Deviation{},
+		providerMinOverrides,
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func testTicker(price string, volume string) types.TickerPrice {
+	return types.TickerPrice{
+		Price:  sdk.MustNewDecFromStr(price),
+		Volume: sdk.MustNewDecFromStr(volume),
+	}
+}
+
+func TestConversionGraph_Paths(t *testing.T) {
+	graph := newTestConversionGraph(
+		map[string]map[provider.Name]types.TickerPrice{
+			"ATOMOSMO": {provider.ProviderOsmosis: testTicker("20", "10")},
+			"OSMOATOM": {provider.ProviderOsmosis: testTicker("0.05", "200")},
+			"OSMOUSDC": {provider.ProviderOsmosis: testTicker("0.5", "100")},
+			"USDCUSD": {
+				provider.ProviderKraken:   testTicker("1", "1000"),
+				provider.ProviderCoinbase: testTicker("1", "1000"),
+			},
+			"ATOMUSD": {provider.ProviderKraken: testTicker("10.2", "5")},
+		},
+		map[string]int{},
+	)
+
+	paths := graph.paths("ATOM", nil)
+	require.Len(t, paths, 2)
+	require.Equal(t, []string{"ATOM", "USD"}, paths[0].denoms())
+	require.Equal(t, sdk.MustNewDecFromStr("10.2"), paths[0].rate)
+	require.Equal(t, []string{"ATOM", "OSMO", "USDC", "USD"}, paths[1].denoms())
+	require.Equal(t, sdk.MustNewDecFromStr("10"), paths[1].rate)
+	require.Equal(t, 1, paths[1].sources)
+	// 10 ATOM valued at the rate of the path
+	require.Equal(t, sdk.MustNewDecFromStr("100"), paths[1].liquidity)
+
+	// the cycle back to ATOM is not followed
+	paths = graph.paths("OSMO", map[string]struct{}{"ATOM": {}})
+	require.Len(t, paths, 1)
+	require.Equal(t, []string{"OSMO", "USDC", "USD"}, paths[0].denoms())
+
+	// same length and sources: more liquidity first
+	paths = graph.paths("OSMO", nil)
+	require.Len(t, paths, 2)
+	require.Equal(t, []string{"OSMO", "ATOM", "USD"}, paths[0].denoms())
+	require.Equal(t, []string{"OSMO", "USDC", "USD"}, paths[1].denoms())
+
+	require.Empty(t, graph.paths("USDT", nil))
+}
+
+func TestConversionGraph_PathsRanking(t *testing.T) {
+	graph := newTestConversionGraph(
+		map[string]map[provider.Name]types.TickerPrice{
+			"ATOMUSDC": {provider.ProviderOsmosis: testTicker("10", "100")},
+			"ATOMUSDT": {
+				provider.ProviderBinance: testTicker("10", "10"),
+				provider.ProviderKucoin:  testTicker("10", "10"),
+			},
+			"ATOMOSMO": {provider.ProviderOsmosis: testTicker("20", "1000")},
+			"OSMOUSD":  {provider.ProviderKraken: testTicker("0.5", "1000")},
+			"USDCUSD":  {provider.ProviderKraken: testTicker("1", "1000")},
+			"USDTUSD": {
+				provider.ProviderKraken:   testTicker("1", "1000"),
+				provider.ProviderCoinbase: testTicker("1", "1000"),
+			},
+		},
+		map[string]int{},
+	)
+
+	// same length: more sources first, then more liquidity
+	paths := graph.paths("ATOM", nil)
+	require.Len(t, paths, maxPaths)
+	require.Equal(t, []string{"ATOM", "USDT", "USD"}, paths[0].denoms())
+	require.Equal(t, []string{"ATOM", "OSMO", "USD"}, paths[1].denoms())
+	require.Equal(t, []string{"ATOM", "USDC", "USD"}, paths[2].denoms())
+}
+
+func TestConversionGraph_QuoteRate(t *testing.T) {
+	tickers := map[string]map[provider.Name]types.TickerPrice{
+		"ATOMUSD":  {provider.ProviderKraken: testTicker("10", "30")},
+		"ATOMUSDT": {provider.ProviderBinance: testTicker("11", "10")},
+		"USDTUSD":  {provider.ProviderKraken: testTicker("1", "1000")},
+	}
+
+	graph := newTestConversionGraph(tickers, map[string]int{})
+
+	// less than 3 providers
+	_, found := graph.quoteRate("ATOM", "STATOM")
+	require.False(t, found)
+
+	graph = newTestConversionGraph(tickers, map[string]int{"ATOM": 2})
+
+	conversion, found := graph.quoteRate("ATOM", "STATOM")
+	require.True(t, found)
+	require.Len(t, conversion.paths, 2)
+	// weighted by USD liquidity: (10 * 300 + 11 * 110) / 410
+	require.Equal(t, sdk.MustNewDecFromStr("10.268292682926829268"), conversion.rate)
+	require.Len(t, conversion.providers, 2)
+	require.Empty(t, conversion.dropped)
+
+	// paths through the converted denom are excluded
+	graph = newTestConversionGraph(tickers, map[string]int{"ATOM": 1})
+
+	conversion, found = graph.quoteRate("ATOM", "USDT")
+	require.True(t, found)
+	require.Len(t, conversion.paths, 1)
+	require.Equal(t, sdk.MustNewDecFromStr("10"), conversion.rate)
+
+	// cached per quote and converted base
+	conversion, found = graph.quoteRate("ATOM", "STATOM")
+	require.True(t, found)
+	require.Len(t, conversion.paths, 2)
+	require.Len(t, graph.quoteRates, 2)
+
+	graph.edges = nil
+	conversion, found = graph.quoteRate("ATOM", "USDT")
+	require.True(t, found)
+	require.Equal(t, sdk.MustNewDecFromStr("10"), conversion.rate)
+}
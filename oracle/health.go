@@ -0,0 +1,260 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// healthGauges are the values the health states are reported with.
+var healthGauges = map[string]float32{
+	types.HealthHealthy:     0,
+	types.HealthDegraded:    1,
+	types.HealthRecovering:  2,
+	types.HealthQuarantined: 3,
+}
+
+type (
+	// providerPoll is the outcome of getting the tickers of a provider in
+	// one price update: the error or the number of its pairs and of the
+	// tickers received for them.
+	providerPoll struct {
+		err     error
+		pairs   int
+		tickers int
+	}
+
+	// healthState holds the health of a provider and when its consecutive
+	// failures started.
+	healthState struct {
+		health       types.ProviderHealth
+		failingSince time.Time
+	}
+)
+
+// GetProviderHealth returns the health of all tracked providers, sorted by
+// provider.
+func (o *Oracle) GetProviderHealth() []types.ProviderHealth {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	health := make([]types.ProviderHealth, 0, len(o.healthStates))
+	for _, state := range o.healthStates {
+		health = append(health, state.health)
+	}
+
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Provider < health[j].Provider
+	})
+
+	return health
+}
+
+// quarantinedProviders returns the providers quarantined by their health.
+func (o *Oracle) quarantinedProviders() map[provider.Name]struct{} {
+	if !o.health.Enabled() {
+		return nil
+	}
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	quarantined := map[provider.Name]struct{}{}
+	for providerName, state := range o.healthStates {
+		if state.health.State == types.HealthQuarantined {
+			quarantined[providerName] = struct{}{}
+		}
+	}
+
+	return quarantined
+}
+
+// excludeQuarantined returns the tickers of all providers not quarantined.
+func excludeQuarantined(
+	tickers map[provider.Name]types.TickerPrice,
+	quarantined map[provider.Name]struct{},
+) map[provider.Name]types.TickerPrice {
+	healthy := make(map[provider.Name]types.TickerPrice, len(tickers))
+	for providerName, ticker := range tickers {
+		if _, found := quarantined[providerName]; !found {
+			healthy[providerName] = ticker
+		}
+	}
+
+	return healthy
+}
+
+// recordHealth updates the health of all polled providers. Tickers dropped
+// by the deviation filter count as rejected. It must not be called
+// concurrently.
+func (o *Oracle) recordHealth(
+	polls map[provider.Name]providerPoll,
+	audits map[string]types.PriceAudit,
+	now time.Time,
+) {
+	if !o.health.Enabled() {
+		return
+	}
+
+	rejected := map[string]int{}
+	for _, audit := range audits {
+		for _, ticker := range audit.Tickers {
+			if ticker.Dropped == types.AuditDroppedDeviation {
+				rejected[ticker.Provider]++
+			}
+		}
+	}
+
+	for providerName, poll := range polls {
+		o.updateHealth(providerName, poll.failure(rejected[providerName.String()]), now)
+	}
+}
+
+// failure returns why the poll failed or an empty string if it succeeded.
+func (p providerPoll) failure(rejected int) string {
+	if p.err != nil {
+		return p.err.Error()
+	}
+
+	if rejected > p.tickers {
+		rejected = p.tickers
+	}
+
+	missing := p.pairs - p.tickers
+	if (missing+rejected)*2 <= p.pairs {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"%d of %d pairs missing or stale, %d rejected by the deviation filter",
+		missing, p.pairs, rejected,
+	)
+}
+
+// updateHealth moves the provider through the health states by the outcome
+// of its last poll.
+func (o *Oracle) updateHealth(providerName provider.Name, failure string, now time.Time) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.healthStates == nil {
+		o.healthStates = map[provider.Name]*healthState{}
+	}
+
+	state, found := o.healthStates[providerName]
+	if !found {
+		state = &healthState{
+			health: types.ProviderHealth{
+				Provider: providerName.String(),
+				State:    types.HealthHealthy,
+				Since:    now,
+			},
+		}
+		o.healthStates[providerName] = state
+	}
+
+	previous := state.health.State
+	next := state.next(failure, o.health, now)
+
+	if next != previous {
+		state.health.State = next
+		state.health.Since = now
+		o.healthTransition(providerName, previous, state.health)
+	}
+
+	telemetry.SetGaugeWithLabels(
+		[]string{"provider", "health"},
+		healthGauges[next],
+		[]metrics.Label{telemetry.NewLabel("provider", providerName.String())},
+	)
+}
+
+// next records the outcome of a poll and returns the resulting state.
+// Quarantined providers are only probed once they have been quarantined
+// for the probe interval, failures before are counted, but nothing else.
+func (s *healthState) next(failure string, health types.Health, now time.Time) string {
+	state := s.health.State
+
+	if failure == "" {
+		if state == types.HealthQuarantined &&
+			now.Sub(s.health.Since) < health.ProbeInterval {
+			return state
+		}
+
+		s.health.Failures = 0
+
+		switch state {
+		case types.HealthQuarantined:
+			return types.HealthRecovering
+		case types.HealthRecovering:
+			if now.Sub(s.health.Since) < health.RecoverAfter {
+				return state
+			}
+		}
+		return types.HealthHealthy
+	}
+
+	if s.health.Failures == 0 {
+		s.failingSince = now
+	}
+	s.health.Failures++
+	s.health.LastError = failure
+
+	switch {
+	case state == types.HealthQuarantined || state == types.HealthRecovering:
+		return types.HealthQuarantined
+	case s.health.Failures < health.DegradeAfter:
+		return state
+	case now.Sub(s.failingSince) >= health.QuarantineAfter:
+		return types.HealthQuarantined
+	}
+	return types.HealthDegraded
+}
+
+// healthTransition logs and counts a change of the health of a provider and
+// throttles the polls of the provider while it is quarantined. It must be
+// called with the mutex locked.
+func (o *Oracle) healthTransition(
+	providerName provider.Name,
+	previous string,
+	health types.ProviderHealth,
+) {
+	telemetry.IncrCounterWithLabels(
+		[]string{"provider", "health", "transitions"},
+		1,
+		[]metrics.Label{
+			telemetry.NewLabel("provider", providerName.String()),
+			telemetry.NewLabel("state", health.State),
+		},
+	)
+
+	event := o.logger.Info()
+	if health.State == types.HealthDegraded || health.State == types.HealthQuarantined {
+		event = o.logger.Warn()
+	}
+	event.
+		Str("provider", providerName.String()).
+		Str("from", previous).
+		Str("to", health.State).
+		Int("failures", health.Failures).
+		Str("last_error", health.LastError).
+		Msg("provider health changed")
+
+	throttled, ok := o.priceProviders[providerName].(provider.ThrottledProvider)
+	if !ok {
+		return
+	}
+
+	switch {
+	case health.State == types.HealthQuarantined:
+		throttled.Throttle(o.health.ProbeInterval)
+	case previous == types.HealthQuarantined:
+		throttled.Throttle(0)
+	}
+}
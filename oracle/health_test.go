@@ -0,0 +1,220 @@
+package oracle
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type throttledProvider struct {
+	provider.Provider
+	throttle time.Duration
+}
+
+func (p *throttledProvider) Throttle(interval time.Duration) {
+	p.throttle = interval
+}
+
+func TestProviderHealth(t *testing.T) {
+	kraken := &throttledProvider{}
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		health: types.Health{
+			DegradeAfter:    3,
+			QuarantineAfter: 5 * time.Minute,
+			RecoverAfter:    2 * time.Minute,
+			ProbeInterval:   time.Minute,
+		},
+		priceProviders: map[provider.Name]provider.Provider{
+			provider.ProviderKraken: kraken,
+		},
+	}
+
+	now := time.Now()
+	failed := map[provider.Name]providerPoll{
+		provider.ProviderKraken: {err: fmt.Errorf("provider timed out: kraken"), pairs: 2},
+	}
+	succeeded := map[provider.Name]providerPoll{
+		provider.ProviderKraken: {pairs: 2, tickers: 2},
+	}
+
+	state := func() types.ProviderHealth {
+		health := oracle.GetProviderHealth()
+		require.Len(t, health, 1)
+		return health[0]
+	}
+
+	oracle.recordHealth(succeeded, nil, now)
+	require.Equal(t, types.HealthHealthy, state().State)
+
+	// degraded after consecutive failures
+	oracle.recordHealth(failed, nil, now)
+	oracle.recordHealth(failed, nil, now.Add(time.Second))
+	require.Equal(t, types.HealthHealthy, state().State)
+	oracle.recordHealth(failed, nil, now.Add(2*time.Second))
+	require.Equal(t, types.HealthDegraded, state().State)
+	require.Equal(t, 3, state().Failures)
+	require.Equal(t, "provider timed out: kraken", state().LastError)
+
+	// a success resets the failures
+	oracle.recordHealth(succeeded, nil, now.Add(3*time.Second))
+	require.Equal(t, types.HealthHealthy, state().State)
+	require.Zero(t, state().Failures)
+
+	// quarantined after failing for the quarantine duration
+	for i := 0; i <= 5; i++ {
+		oracle.recordHealth(failed, nil, now.Add(time.Duration(i)*time.Minute))
+	}
+	require.Equal(t, types.HealthQuarantined, state().State)
+	require.Equal(t, now.Add(5*time.Minute), state().Since)
+	require.Equal(t, time.Minute, kraken.throttle)
+
+	require.Equal(
+		t,
+		map[provider.Name]struct{}{provider.ProviderKraken: {}},
+		oracle.quarantinedProviders(),
+	)
+
+	// not probed before the probe interval
+	oracle.recordHealth(succeeded, nil, now.Add(5*time.Minute+30*time.Second))
+	require.Equal(t, types.HealthQuarantined, state().State)
+
+	oracle.recordHealth(succeeded, nil, now.Add(6*time.Minute))
+	require.Equal(t, types.HealthRecovering, state().State)
+	require.Zero(t, kraken.throttle)
+
+	// a failure while recovering quarantines again
+	oracle.recordHealth(failed, nil, now.Add(7*time.Minute))
+	require.Equal(t, types.HealthQuarantined, state().State)
+	require.Equal(t, time.Minute, kraken.throttle)
+
+	oracle.recordHealth(succeeded, nil, now.Add(8*time.Minute))
+	require.Equal(t, types.HealthRecovering, state().State)
+	oracle.recordHealth(succeeded, nil, now.Add(9*time.Minute))
+	require.Equal(t, types.HealthRecovering, state().State)
+	oracle.recordHealth(succeeded, nil, now.Add(10*time.Minute))
+	require.Equal(t, types.HealthHealthy, state().State)
+
+	// disabled
+	oracle = &Oracle{logger: zerolog.Nop()}
+	oracle.recordHealth(failed, nil, now)
+	require.Empty(t, oracle.GetProviderHealth())
+	require.Empty(t, oracle.quarantinedProviders())
+}
+
+func TestProviderHealth_Quarantined(t *testing.T) {
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderKraken: {
+			"ATOMUSD": {Price: sdk.NewDec(20), Volume: sdk.NewDec(10)},
+			"OSMOUSD": {Price: sdk.NewDec(1), Volume: sdk.NewDec(10)},
+			"USDTUSD": {Price: sdk.NewDec(2), Volume: sdk.NewDec(1000)},
+		},
+		provider.ProviderBinance: {
+			"ATOMUSD":  {Price: sdk.NewDec(10), Volume: sdk.NewDec(10)},
+			"KUJIUSDT": {Price: sdk.NewDec(1), Volume: sdk.NewDec(10)},
+		},
+		provider.ProviderCoinbase: {
+			"USDTUSD": {Price: sdk.NewDec(1), Volume: sdk.NewDec(1000)},
+		},
+	}
+
+	atomUsd := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+	osmoUsd := types.CurrencyPair{Base: "OSMO", Quote: "USD"}
+	usdtUsd := types.CurrencyPair{Base: "USDT", Quote: "USD"}
+	kujiUsdt := types.CurrencyPair{Base: "KUJI", Quote: "USDT"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {atomUsd, osmoUsd, usdtUsd},
+		provider.ProviderBinance:  {atomUsd, kujiUsdt},
+		provider.ProviderCoinbase: {usdtUsd},
+	}
+
+	rates, audits, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			ProviderMinOverrides: map[string]int{"ATOM": 1, "OSMO": 1, "USDT": 1, "KUJI": 1},
+			Quarantined:          map[provider.Name]struct{}{provider.ProviderKraken: {}},
+		},
+	)
+	require.NoError(t, err)
+
+	// the quarantined ticker is kept in the audit, but not used
+	require.Equal(t, sdk.NewDec(10), rates["ATOM"])
+	require.Len(t, audits["ATOM"].Tickers, 2)
+	for _, ticker := range audits["ATOM"].Tickers {
+		if ticker.Provider == provider.ProviderKraken.String() {
+			require.Equal(t, types.AuditDroppedHealth, ticker.Dropped)
+		} else {
+			require.Empty(t, ticker.Dropped)
+		}
+	}
+
+	// nor used to convert the prices quoted in USDT
+	require.Equal(t, sdk.OneDec(), rates["USDT"])
+	require.Equal(t, sdk.OneDec(), rates["KUJI"])
+	require.Equal(t, []string{provider.ProviderCoinbase.String()}, audits["KUJI"].Conversions[0].Providers)
+
+	// denoms without any other provider are withheld
+	require.NotContains(t, rates, "OSMO")
+	require.Equal(t, types.AuditDroppedHealth, audits["OSMO"].Tickers[0].Dropped)
+	require.Equal(t, "all providers quarantined by their health", audits["OSMO"].Error)
+}
+
+func TestProviderHealth_Rejections(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		health: types.Health{
+			DegradeAfter:    1,
+			QuarantineAfter: time.Hour,
+			RecoverAfter:    time.Minute,
+			ProbeInterval:   time.Minute,
+		},
+	}
+
+	audits := map[string]types.PriceAudit{
+		"ATOM": {
+			Denom: "ATOM",
+			Tickers: []types.AuditTicker{
+				{Provider: "kraken", Symbol: "ATOMUSDT", USDPrice: sdk.NewDec(20), Dropped: types.AuditDroppedDeviation},
+				{Provider: "binance", Symbol: "ATOMUSDT", USDPrice: sdk.NewDec(10)},
+			},
+		},
+	}
+
+	// one missing and one rejected ticker are no majority of four pairs
+	oracle.recordHealth(map[provider.Name]providerPoll{
+		provider.ProviderKraken: {pairs: 4, tickers: 3},
+	}, audits, time.Now())
+	require.Equal(t, types.HealthHealthy, oracle.GetProviderHealth()[0].State)
+
+	oracle.recordHealth(map[provider.Name]providerPoll{
+		provider.ProviderKraken: {pairs: 2, tickers: 1},
+	}, audits, time.Now())
+	health := oracle.GetProviderHealth()[0]
+	require.Equal(t, types.HealthDegraded, health.State)
+	require.Equal(t, "1 of 2 pairs missing or stale, 1 rejected by the deviation filter", health.LastError)
+}
+
+func TestProviderPoll_Failure(t *testing.T) {
+	require.Empty(t, providerPoll{pairs: 3, tickers: 2}.failure(0))
+	require.Empty(t, providerPoll{pairs: 2, tickers: 1}.failure(0))
+	require.NotEmpty(t, providerPoll{pairs: 3, tickers: 1}.failure(0))
+	require.NotEmpty(t, providerPoll{pairs: 3, tickers: 3}.failure(2))
+	require.Equal(t, "stale", providerPoll{err: fmt.Errorf("stale"), pairs: 1}.failure(0))
+
+	// rejections are capped at the tickers received
+	require.Equal(
+		t,
+		"2 of 2 pairs missing or stale, 0 rejected by the deviation filter",
+		providerPoll{pairs: 2}.failure(1),
+	)
+}
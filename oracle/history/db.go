@@ -12,11 +12,19 @@ import (
 
 type (
 	PriceHistory struct {
-		db      *sql.DB
-		insert  *sql.Stmt
-		query   *sql.Stmt
-		cleanup *sql.Stmt
-		logger  zerolog.Logger
+		db            *sql.DB
+		insert        *sql.Stmt
+		query         *sql.Stmt
+		cleanup       *sql.Stmt
+		setPrevote    *sql.Stmt
+		getPrevote    *sql.Stmt
+		deletePrevote *sql.Stmt
+
+		addDeviation      *sql.Stmt
+		getDeviations     *sql.Stmt
+		cleanupDeviations *sql.Stmt
+
+		logger zerolog.Logger
 	}
 )
 
@@ -86,7 +94,36 @@ func (p *PriceHistory) Init() error {
 	p.query = query
 	p.cleanup = cleanup
 
-	return nil
+	if err := p.initPrevotes(); err != nil {
+		return err
+	}
+
+	return p.initDeviations()
+}
+
+// Close closes all prepared statements and the database.
+func (p *PriceHistory) Close() error {
+	if p.db == nil {
+		return nil
+	}
+
+	for _, stmt := range []*sql.Stmt{
+		p.insert,
+		p.query,
+		p.cleanup,
+		p.setPrevote,
+		p.getPrevote,
+		p.deletePrevote,
+		p.addDeviation,
+		p.getDeviations,
+		p.cleanupDeviations,
+	} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+
+	return p.db.Close()
 }
 
 func (p *PriceHistory) AddTickerPrice(pair types.CurrencyPair, provider string, ticker types.TickerPrice) error {
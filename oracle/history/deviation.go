@@ -0,0 +1,131 @@
+package history
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func (p *PriceHistory) initDeviations() error {
+	_, err := p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS provider_deviations(
+        denom TEXT NOT NULL,
+        provider TEXT NOT NULL,
+        time INT NOT NULL,
+        deviation TEXT NOT NULL,
+        CONSTRAINT id PRIMARY KEY (denom, provider, time)
+    )`)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to create deviation table")
+		return err
+	}
+
+	addDeviation, err := p.db.Prepare(`
+		INSERT OR REPLACE INTO provider_deviations(denom, provider, time, deviation)
+        VALUES (?, ?, ?, ?)
+    `)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql deviation insert statement")
+		return err
+	}
+
+	getDeviations, err := p.db.Prepare(`
+		SELECT provider, deviation FROM provider_deviations
+        WHERE denom = ? AND time >= ?
+        ORDER BY time ASC
+    `)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql deviation query statement")
+		return err
+	}
+
+	cleanupDeviations, err := p.db.Prepare(`
+		DELETE FROM provider_deviations
+		WHERE denom = ? AND time < ?
+	`)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql deviation cleanup statement")
+		return err
+	}
+
+	p.addDeviation = addDeviation
+	p.getDeviations = getDeviations
+	p.cleanupDeviations = cleanupDeviations
+
+	return nil
+}
+
+// AddProviderDeviation stores how far the price of a provider deviated from
+// the final price of a denom in percent.
+func (p *PriceHistory) AddProviderDeviation(
+	denom string,
+	provider string,
+	timestamp time.Time,
+	deviation sdk.Dec,
+) error {
+	_, err := p.addDeviation.Exec(denom, provider, timestamp.Unix(), deviation.String())
+	if err != nil {
+		p.logger.Error().
+			Err(err).
+			Str("denom", denom).
+			Str("provider", provider).
+			Msg("failed to store provider deviation")
+	}
+	return err
+}
+
+// GetProviderDeviations returns the deviations of all providers of a denom
+// since the given time, oldest first. Older deviations are removed.
+func (p *PriceHistory) GetProviderDeviations(
+	denom string,
+	start time.Time,
+) (map[string][]sdk.Dec, error) {
+	logger := p.logger.With().Str("denom", denom).Logger()
+
+	_, err := p.cleanupDeviations.Exec(denom, start.Unix())
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to remove old provider deviations")
+	}
+
+	rows, err := p.getDeviations.Query(denom, start.Unix())
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to query stored provider deviations")
+		return nil, err
+	}
+	defer rows.Close()
+
+	deviations := map[string][]sdk.Dec{}
+	for rows.Next() {
+		var providerName, value string
+		if err := rows.Scan(&providerName, &value); err != nil {
+			logger.Error().
+				Err(err).
+				Msg("failed to parse deviation query results")
+			return nil, err
+		}
+
+		deviation, err := sdk.NewDecFromStr(value)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str("provider", providerName).
+				Msg("failed to parse stored deviation")
+			continue
+		}
+
+		deviations[providerName] = append(deviations[providerName], deviation)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to read all stored deviations")
+		return nil, err
+	}
+
+	return deviations, nil
+}
@@ -0,0 +1,44 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceHistory_deviations(t *testing.T) {
+	h, err := NewPriceHistory(":memory:", zerolog.Nop())
+	require.NoError(t, err)
+
+	deviations, err := h.GetProviderDeviations("ATOM", time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Empty(t, deviations)
+
+	for i := int64(1); i <= 4; i++ {
+		require.NoError(t, h.AddProviderDeviation("ATOM", "binance", time.Unix(i, 0), sdk.NewDec(i)))
+	}
+	require.NoError(t, h.AddProviderDeviation("ATOM", "kraken", time.Unix(4, 0), sdk.OneDec()))
+	require.NoError(t, h.AddProviderDeviation("KUJI", "kraken", time.Unix(4, 0), sdk.OneDec()))
+
+	// the same time replaces the stored deviation
+	require.NoError(t, h.AddProviderDeviation("ATOM", "kraken", time.Unix(4, 0), sdk.NewDec(2)))
+
+	deviations, err = h.GetProviderDeviations("ATOM", time.Unix(3, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]sdk.Dec{
+		"binance": {sdk.NewDec(3), sdk.NewDec(4)},
+		"kraken":  {sdk.NewDec(2)},
+	}, deviations)
+
+	// older deviations have been removed
+	deviations, err = h.GetProviderDeviations("ATOM", time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Len(t, deviations["binance"], 2)
+
+	deviations, err = h.GetProviderDeviations("KUJI", time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Len(t, deviations["kraken"], 1)
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"database/sql"
+	"errors"
+)
+
+type (
+	// Prevote defines a prevote that has been broadcasted but not yet
+	// revealed. It is journaled so that a restart between the prevote and
+	// the vote doesn't forfeit the vote.
+	Prevote struct {
+		ChainID           string
+		Validator         string
+		Salt              string
+		ExchangeRates     string
+		SubmitBlockHeight int64
+		VotePeriod        int64
+	}
+)
+
+func (p *PriceHistory) initPrevotes() error {
+	// prevotes used to be keyed by validator only, they are only valid for
+	// one vote period, so the old table is simply replaced
+	var legacy int
+	err := p.db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('oracle_prevotes')
+        WHERE NOT EXISTS (
+            SELECT 1 FROM pragma_table_info('oracle_prevotes') WHERE name = 'chain_id'
+        )
+    `).Scan(&legacy)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to inspect prevote table")
+		return err
+	}
+
+	if legacy > 0 {
+		p.logger.Info().Msg("replacing prevote table without chain id")
+		if _, err := p.db.Exec(`DROP TABLE oracle_prevotes`); err != nil {
+			p.logger.Error().Err(err).Msg("failed to drop legacy prevote table")
+			return err
+		}
+	}
+
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS oracle_prevotes(
+        chain_id TEXT NOT NULL,
+        validator TEXT NOT NULL,
+        salt TEXT NOT NULL,
+        exchange_rates TEXT NOT NULL,
+        submit_height INT NOT NULL,
+        vote_period INT NOT NULL,
+        CONSTRAINT id PRIMARY KEY (chain_id, validator)
+    )`)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to create prevote table")
+		return err
+	}
+
+	setPrevote, err := p.db.Prepare(`
+		INSERT OR REPLACE INTO oracle_prevotes(chain_id, validator, salt, exchange_rates, submit_height, vote_period)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql prevote insert statement")
+		return err
+	}
+
+	getPrevote, err := p.db.Prepare(`
+		SELECT salt, exchange_rates, submit_height, vote_period FROM oracle_prevotes
+        WHERE chain_id = ? AND validator = ?
+    `)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql prevote query statement")
+		return err
+	}
+
+	deletePrevote, err := p.db.Prepare(`
+		DELETE FROM oracle_prevotes WHERE chain_id = ? AND validator = ?
+	`)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to prepare sql prevote delete statement")
+		return err
+	}
+
+	p.setPrevote = setPrevote
+	p.getPrevote = getPrevote
+	p.deletePrevote = deletePrevote
+
+	return nil
+}
+
+// SetPrevote journals the latest prevote of a validator on a chain,
+// replacing any previously stored one.
+func (p *PriceHistory) SetPrevote(prevote Prevote) error {
+	_, err := p.setPrevote.Exec(
+		prevote.ChainID,
+		prevote.Validator,
+		prevote.Salt,
+		prevote.ExchangeRates,
+		prevote.SubmitBlockHeight,
+		prevote.VotePeriod,
+	)
+	if err != nil {
+		p.logger.Error().
+			Err(err).
+			Str("chain_id", prevote.ChainID).
+			Str("validator", prevote.Validator).
+			Msg("failed to store prevote")
+	}
+	return err
+}
+
+// GetPrevote returns the journaled prevote of a validator on a chain. The
+// second return value is false if no prevote is stored.
+func (p *PriceHistory) GetPrevote(chainID string, validator string) (Prevote, bool, error) {
+	prevote := Prevote{ChainID: chainID, Validator: validator}
+
+	err := p.getPrevote.QueryRow(chainID, validator).Scan(
+		&prevote.Salt,
+		&prevote.ExchangeRates,
+		&prevote.SubmitBlockHeight,
+		&prevote.VotePeriod,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Prevote{}, false, nil
+	}
+	if err != nil {
+		p.logger.Error().
+			Err(err).
+			Str("chain_id", chainID).
+			Str("validator", validator).
+			Msg("failed to query stored prevote")
+		return Prevote{}, false, err
+	}
+
+	return prevote, true, nil
+}
+
+// DeletePrevote removes the journaled prevote of a validator on a chain,
+// once it has been revealed or can't be revealed anymore.
+func (p *PriceHistory) DeletePrevote(chainID string, validator string) error {
+	_, err := p.deletePrevote.Exec(chainID, validator)
+	if err != nil {
+		p.logger.Error().
+			Err(err).
+			Str("chain_id", chainID).
+			Str("validator", validator).
+			Msg("failed to delete stored prevote")
+	}
+	return err
+}
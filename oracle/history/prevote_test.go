@@ -0,0 +1,107 @@
+package history
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceHistory_prevotes(t *testing.T) {
+	h, err := NewPriceHistory(":memory:", zerolog.Nop())
+	require.NoError(t, err)
+
+	chainID := "kaiyo-1"
+	validator := "kujiravaloper1test"
+
+	_, found, err := h.GetPrevote(chainID, validator)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	prevote := Prevote{
+		ChainID:           chainID,
+		Validator:         validator,
+		Salt:              "abcdef",
+		ExchangeRates:     "1.000000000000000000USDT",
+		SubmitBlockHeight: 1234,
+		VotePeriod:        308,
+	}
+	require.NoError(t, h.SetPrevote(prevote))
+
+	stored, found, err := h.GetPrevote(chainID, validator)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, prevote, stored)
+
+	// a newer prevote replaces the stored one
+	prevote.Salt = "123456"
+	prevote.SubmitBlockHeight = 1238
+	prevote.VotePeriod = 309
+	require.NoError(t, h.SetPrevote(prevote))
+
+	stored, found, err = h.GetPrevote(chainID, validator)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, prevote, stored)
+
+	// the same validator on another chain has its own prevote
+	testnet := prevote
+	testnet.ChainID = "harpoon-4"
+	testnet.Salt = "fedcba"
+	require.NoError(t, h.SetPrevote(testnet))
+
+	stored, found, err = h.GetPrevote(chainID, validator)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, prevote, stored)
+
+	require.NoError(t, h.DeletePrevote(chainID, validator))
+
+	stored, found, err = h.GetPrevote(testnet.ChainID, validator)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, testnet, stored)
+
+	_, found, err = h.GetPrevote(chainID, validator)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestPriceHistory_legacyPrevotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.db")
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE oracle_prevotes(
+        validator TEXT NOT NULL,
+        salt TEXT NOT NULL,
+        exchange_rates TEXT NOT NULL,
+        submit_height INT NOT NULL,
+        vote_period INT NOT NULL,
+        CONSTRAINT id PRIMARY KEY (validator)
+    )`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	h, err := NewPriceHistory(path, zerolog.Nop())
+	require.NoError(t, err)
+	defer h.Close()
+
+	prevote := Prevote{
+		ChainID:           "kaiyo-1",
+		Validator:         "kujiravaloper1test",
+		Salt:              "abcdef",
+		ExchangeRates:     "1.000000000000000000USDT",
+		SubmitBlockHeight: 1234,
+		VotePeriod:        308,
+	}
+	require.NoError(t, h.SetPrevote(prevote))
+
+	stored, found, err := h.GetPrevote(prevote.ChainID, prevote.Validator)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, prevote, stored)
+}
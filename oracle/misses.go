@@ -0,0 +1,239 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+
+	"price-feeder/oracle/types"
+)
+
+// MissMonitor defines the configuration of the miss counter monitor. The
+// miss counter is checked every interval and an alert is sent to the webhook,
+// if it increased by at least the alert threshold since the last check.
+type MissMonitor struct {
+	Interval       time.Duration
+	AlertThreshold uint64
+	AlertWebhook   string
+}
+
+// GetMissCounter returns the last known miss counter of the validator and
+// whether it has been fetched yet.
+func (v *Voter) GetMissCounter() (types.MissCounter, bool) {
+	v.mtx.RLock()
+	defer v.mtx.RUnlock()
+
+	if v.missCounter == nil {
+		return types.MissCounter{}, false
+	}
+
+	return *v.missCounter, true
+}
+
+// GetMissCounterOnChain returns the current on-chain miss counter of the
+// validator.
+func (v *Voter) GetMissCounterOnChain(ctx context.Context) (uint64, error) {
+	grpcConn, err := v.dialGRPC()
+	if err != nil {
+		return 0, err
+	}
+
+	defer grpcConn.Close()
+	queryClient := oracletypes.NewQueryClient(grpcConn)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queryResponse, err := queryClient.MissCounter(ctx, &oracletypes.QueryMissCounterRequest{
+		ValidatorAddr: v.oracleClient.ValidatorAddrString,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get x/oracle miss counter: %w", err)
+	}
+
+	return queryResponse.MissCounter, nil
+}
+
+func (v *Voter) monitorMissCounter(ctx context.Context) {
+	var last *types.MissCounter
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(v.missMonitor.Interval):
+		}
+
+		missCounter, err := v.updateMissCounter(ctx)
+		if err != nil {
+			v.logger.Warn().Err(err).Msg("failed to update miss counter")
+			continue
+		}
+
+		v.logger.Info().
+			Uint64("misses", missCounter.Misses).
+			Uint64("max_misses", missCounter.MaxMisses).
+			Uint64("projected_misses", missCounter.ProjectedMisses).
+			Msg("updated miss counter")
+
+		if missCounter.SlashingRisk {
+			v.logger.Warn().
+				Uint64("projected_misses", missCounter.ProjectedMisses).
+				Uint64("max_misses", missCounter.MaxMisses).
+				Msg("validator at risk of being slashed")
+		}
+
+		// the miss counter is reset at the end of every slash window
+		if last != nil && missCounter.Misses >= last.Misses {
+			diff := missCounter.Misses - last.Misses
+			if v.missMonitor.AlertThreshold > 0 && diff >= v.missMonitor.AlertThreshold {
+				v.alertMisses(ctx, diff, missCounter)
+			}
+		}
+
+		last = &missCounter
+	}
+}
+
+func (v *Voter) updateMissCounter(ctx context.Context) (types.MissCounter, error) {
+	height, err := v.oracleClient.ChainHeight.GetChainHeight()
+	if err != nil {
+		return types.MissCounter{}, err
+	}
+
+	params, err := v.GetParams(ctx)
+	if err != nil {
+		return types.MissCounter{}, err
+	}
+
+	misses, err := v.GetMissCounterOnChain(ctx)
+	if err != nil {
+		return types.MissCounter{}, err
+	}
+
+	missCounter := ComputeMissCounter(misses, height, params)
+	missCounter.Validator = v.oracleClient.ValidatorAddrString
+	missCounter.UpdatedAt = time.Now()
+
+	v.mtx.Lock()
+	v.missCounter = &missCounter
+	v.mtx.Unlock()
+
+	labels := []metrics.Label{telemetry.NewLabel("validator", missCounter.Validator)}
+
+	telemetry.SetGaugeWithLabels(
+		[]string{"miss_counter", "misses"}, float32(missCounter.Misses), labels,
+	)
+	telemetry.SetGaugeWithLabels(
+		[]string{"miss_counter", "max_misses"}, float32(missCounter.MaxMisses), labels,
+	)
+	telemetry.SetGaugeWithLabels(
+		[]string{"miss_counter", "projected_misses"}, float32(missCounter.ProjectedMisses), labels,
+	)
+
+	risk := float32(0)
+	if missCounter.SlashingRisk {
+		risk = 1
+	}
+	telemetry.SetGaugeWithLabels([]string{"miss_counter", "slashing_risk"}, risk, labels)
+
+	return missCounter, nil
+}
+
+// alertMisses posts a slack compatible message to the configured webhook.
+func (v *Voter) alertMisses(ctx context.Context, diff uint64, missCounter types.MissCounter) {
+	text := fmt.Sprintf(
+		"%s missed %d oracle votes during the past %s (%d/%d misses in the current slash window)",
+		missCounter.Validator,
+		diff,
+		v.missMonitor.Interval,
+		missCounter.Misses,
+		missCounter.MaxMisses,
+	)
+
+	v.logger.Warn().Uint64("misses", diff).Msg(text)
+	telemetry.IncrCounter(1, "miss_counter", "alert")
+
+	if v.missMonitor.AlertWebhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		v.logger.Err(err).Msg("failed to encode miss counter alert")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, v.missMonitor.AlertWebhook, bytes.NewReader(body),
+	)
+	if err != nil {
+		v.logger.Err(err).Msg("failed to create miss counter alert")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		v.logger.Err(err).Msg("failed to send miss counter alert")
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		v.logger.Warn().
+			Int("status", resp.StatusCode).
+			Msg("miss counter alert rejected")
+	}
+}
+
+// ComputeMissCounter calculates the maximum and projected misses within the
+// current slash window. The slash window ends with the block for which
+// (height + 1) % slash_window == 0, validators are slashed, if their rate of
+// valid votes in that window is below min_valid_per_window.
+func ComputeMissCounter(
+	misses uint64,
+	height int64,
+	params oracletypes.Params,
+) types.MissCounter {
+	missCounter := types.MissCounter{
+		Height: height,
+		Misses: misses,
+	}
+
+	if params.VotePeriod == 0 || params.SlashWindow < params.VotePeriod {
+		return missCounter
+	}
+
+	votePeriods := params.SlashWindow / params.VotePeriod
+	missCounter.VotePeriods = votePeriods
+	missCounter.ElapsedVotePeriods = (uint64(height+1) % params.SlashWindow) / params.VotePeriod
+
+	// valid votes / vote periods >= min valid per window
+	minValid := params.MinValidPerWindow.MulInt64(int64(votePeriods)).Ceil().TruncateInt().Uint64()
+	if minValid < votePeriods {
+		missCounter.MaxMisses = votePeriods - minValid
+	}
+
+	missCounter.ProjectedMisses = misses
+	if missCounter.ElapsedVotePeriods > 0 {
+		projected := misses * votePeriods / missCounter.ElapsedVotePeriods
+		if projected > misses {
+			missCounter.ProjectedMisses = projected
+		}
+	}
+
+	missCounter.SlashingRisk = missCounter.ProjectedMisses > missCounter.MaxMisses
+
+	return missCounter
+}
@@ -0,0 +1,40 @@
+package oracle
+
+import (
+	"testing"
+
+	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeMissCounter(t *testing.T) {
+	params := oracletypes.Params{
+		VotePeriod:        10,
+		SlashWindow:       1000,
+		MinValidPerWindow: sdk.MustNewDecFromStr("0.05"),
+	}
+
+	// 100 vote periods per window, at least 5 valid votes
+	missCounter := ComputeMissCounter(10, 1199, params)
+	require.Equal(t, uint64(100), missCounter.VotePeriods)
+	require.Equal(t, uint64(20), missCounter.ElapsedVotePeriods)
+	require.Equal(t, uint64(95), missCounter.MaxMisses)
+	require.Equal(t, uint64(50), missCounter.ProjectedMisses)
+	require.False(t, missCounter.SlashingRisk)
+
+	missCounter = ComputeMissCounter(20, 1199, params)
+	require.Equal(t, uint64(100), missCounter.ProjectedMisses)
+	require.True(t, missCounter.SlashingRisk)
+
+	// first vote period of a new slash window
+	missCounter = ComputeMissCounter(0, 999, params)
+	require.Equal(t, uint64(0), missCounter.ElapsedVotePeriods)
+	require.Equal(t, uint64(0), missCounter.ProjectedMisses)
+	require.False(t, missCounter.SlashingRisk)
+
+	// max misses are rounded down
+	params.MinValidPerWindow = sdk.MustNewDecFromStr("0.055")
+	missCounter = ComputeMissCounter(0, 999, params)
+	require.Equal(t, uint64(94), missCounter.MaxMisses)
+}
@@ -6,8 +6,6 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
-	"math"
-	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -16,27 +14,32 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
-	"google.golang.org/grpc"
 
 	"price-feeder/config"
-	"price-feeder/oracle/client"
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/derivative"
 	"price-feeder/oracle/history"
 	"price-feeder/oracle/provider"
 	"price-feeder/oracle/types"
 	pfsync "price-feeder/pkg/sync"
 
-	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
-
 	"github.com/cosmos/cosmos-sdk/telemetry"
 )
 
-// We define tickerSleep as the minimum timeout between each oracle loop. We
-// define this value empirically based on enough time to collect exchange rates,
-// and broadcast pre-vote and vote transactions such that they're committed in
-// at least one block during each voting period.
+// The vote loops are driven by new blocks, so each block results in exactly
+// one tick. We define newBlockTimeout as the maximum time to wait for a new
+// block, before the chain height is considered stale and a warning is logged.
+//
+// All voters share the same prices, pricesMaxAge defines how long prices are
+// reused, before they get computed again. This way voters ticking on the same
+// block don't aggregate the provider prices more than once.
+//
+// On shutdown, stopTimeout defines how long to wait for the voters and
+// providers to exit, before the databases are closed anyway.
 const (
-	tickerSleep = 1000 * time.Millisecond
+	newBlockTimeout = 1 * time.Minute
+	pricesMaxAge    = 1 * time.Second
+	stopTimeout     = 15 * time.Second
 )
 
 type ProviderWeight struct {
@@ -44,6 +47,21 @@ type ProviderWeight struct {
 	Weight map[string]sdk.Dec
 }
 
+// ComputeOptions define how the prices of the providers are filtered,
+// weighted and combined into the price of each denom. The tickers of
+// quarantined providers are neither used for prices nor for conversions,
+// denoms without any other provider are withheld.
+type ComputeOptions struct {
+	Deviations           map[string]types.Deviation
+	ProviderMinOverrides map[string]int
+	ProviderWeights      map[string]ProviderWeight
+	Aggregators          map[string]aggregator.Aggregator
+	VolumeCaps           map[string]types.VolumeCap
+	Scores               map[string]map[string]types.ProviderScore
+	Pegs                 map[string]types.Peg
+	Quarantined          map[provider.Name]struct{}
+}
+
 // PreviousPrevote defines a structure for defining the previous prevote
 // submitted on-chain.
 type PreviousPrevote struct {
@@ -63,17 +81,18 @@ func NewPreviousPrevote() *PreviousPrevote {
 // Oracle implements the core component responsible for fetching exchange rates
 // for a given set of currency pairs and determining the correct exchange rates
 // to submit to the on-chain price oracle adhering the oracle specification.
+// The exchange rates are submitted by one or more voters.
 type Oracle struct {
-	logger zerolog.Logger
-	closer *pfsync.Closer
+	logger  zerolog.Logger
+	closer  *pfsync.Closer
+	stopped *pfsync.Closer
+	started bool
 
 	providerTimeout      time.Duration
 	providerPairs        map[provider.Name][]types.CurrencyPair
-	previousPrevote      *PreviousPrevote
-	previousVotePeriod   float64
 	priceProviders       map[provider.Name]provider.Provider
-	oracleClient         client.OracleClient
-	deviations           map[string]sdk.Dec
+	voters               []*Voter
+	deviations           map[string]types.Deviation
 	providerMinOverrides map[string]int
 	endpoints            map[provider.Name]provider.Endpoint
 	history              history.PriceHistory
@@ -82,35 +101,66 @@ type Oracle struct {
 	derivativeSymbols    map[string]struct{}
 	contractAddresses    map[string]map[string]string
 	providerWeights      map[string]ProviderWeight
+	aggregators          map[string]aggregator.Aggregator
+	volumeCaps           map[string]types.VolumeCap
+	auditRetention       int
+	breakers             map[string]types.CircuitBreaker
+	breakerStates        map[string]*breakerState
+	reputation           types.Reputation
+	consistency          types.Consistency
+	pegs                 map[string]types.Peg
+	depegged             map[string]bool
+	fallbacks            map[string]types.Fallback
+	lastGoodPrices       map[string]types.FallbackPrice
+	health               types.Health
+	healthStates         map[provider.Name]*healthState
 	decimals             map[string]map[string]int
 	periods              map[string]map[string]int
 	volumeDatabase       *sql.DB
 
+	// syncMtx serializes the price updates of all voters
+	syncMtx sync.Mutex
+
 	mtx             sync.RWMutex
 	lastPriceSyncTS time.Time
 	prices          map[string]sdk.Dec
-	paramCache      ParamCache
-	healthchecks    map[string]http.Client
+	providerPrices  provider.AggregatedProviderPrices
+	audits          []types.PriceAudits
+	scores          map[string]map[string]types.ProviderScore
+	fallbackPrices  []types.FallbackPrice
+}
+
+// Options configure the oracle. Only the currency pairs are required, all
+// other features are disabled when left empty.
+type Options struct {
+	ProviderTimeout      time.Duration
+	Deviations           map[string]types.Deviation
+	ProviderMinOverrides map[string]int
+	Endpoints            map[provider.Name]provider.Endpoint
+	Derivatives          map[string]derivative.Derivative
+	DerivativePairs      map[string][]types.CurrencyPair
+	DerivativeDenoms     map[string]struct{}
+	History              history.PriceHistory
+	ContractAddresses    map[string]map[string]string
+	ProviderWeights      map[string]ProviderWeight
+	Aggregators          map[string]aggregator.Aggregator
+	VolumeCaps           map[string]types.VolumeCap
+	AuditRetention       int
+	Breakers             map[string]types.CircuitBreaker
+	Reputation           types.Reputation
+	Consistency          types.Consistency
+	Pegs                 map[string]types.Peg
+	Fallbacks            map[string]types.Fallback
+	Health               types.Health
+	Decimals             map[string]map[string]int
+	Periods              map[string]map[string]int
+	VolumeDatabase       *sql.DB
 }
 
 func New(
 	logger zerolog.Logger,
-	oc client.OracleClient,
 	currencyPairs []config.CurrencyPair,
-	providerTimeout time.Duration,
-	deviations map[string]sdk.Dec,
-	providerMinOverrides map[string]int,
-	endpoints map[provider.Name]provider.Endpoint,
-	derivatives map[string]derivative.Derivative,
-	derivativePairs map[string][]types.CurrencyPair,
-	derivativeDenoms map[string]struct{},
-	healthchecksConfig []config.Healthchecks,
-	history history.PriceHistory,
-	contractAddresses map[string]map[string]string,
-	providerWeights map[string]ProviderWeight,
-	decimals map[string]map[string]int,
-	periods map[string]map[string]int,
-	volumeDatabase *sql.DB,
+	options Options,
 ) *Oracle {
 	providerPairs := make(map[provider.Name][]types.CurrencyPair)
 	for _, pair := range currencyPairs {
@@ -121,76 +171,124 @@ func New(
 			})
 		}
 	}
-	healthchecks := make(map[string]http.Client, len(healthchecksConfig))
-	for _, healthcheck := range healthchecksConfig {
-		timeout, err := time.ParseDuration(healthcheck.Timeout)
-		if err != nil {
-			logger.Warn().
-				Str("timeout", healthcheck.Timeout).
-				Msg("failed to parse healthcheck timeout, skipping configuration")
-		} else {
-			healthchecks[healthcheck.URL] = http.Client{
-				Timeout: timeout,
-			}
-		}
-	}
-
 	return &Oracle{
 		logger:               logger.With().Str("module", "oracle").Logger(),
 		closer:               pfsync.NewCloser(),
-		oracleClient:         oc,
+		stopped:              pfsync.NewCloser(),
 		providerPairs:        providerPairs,
 		priceProviders:       make(map[provider.Name]provider.Provider),
-		previousPrevote:      nil,
-		providerTimeout:      providerTimeout,
-		deviations:           deviations,
-		providerMinOverrides: providerMinOverrides,
-		paramCache:           ParamCache{},
-		endpoints:            endpoints,
-		healthchecks:         healthchecks,
-		derivatives:          derivatives,
-		derivativePairs:      derivativePairs,
-		derivativeSymbols:    derivativeDenoms,
-		history:              history,
-		contractAddresses:    contractAddresses,
-		providerWeights:      providerWeights,
-		decimals:             decimals,
-		periods:              periods,
-		volumeDatabase:       volumeDatabase,
+		providerTimeout:      options.ProviderTimeout,
+		deviations:           options.Deviations,
+		providerMinOverrides: options.ProviderMinOverrides,
+		endpoints:            options.Endpoints,
+		derivatives:          options.Derivatives,
+		derivativePairs:      options.DerivativePairs,
+		derivativeSymbols:    options.DerivativeDenoms,
+		history:              options.History,
+		contractAddresses:    options.ContractAddresses,
+		providerWeights:      options.ProviderWeights,
+		aggregators:          options.Aggregators,
+		volumeCaps:           options.VolumeCaps,
+		auditRetention:       options.AuditRetention,
+		breakers:             options.Breakers,
+		reputation:           options.Reputation,
+		consistency:          options.Consistency,
+		pegs:                 options.Pegs,
+		fallbacks:            options.Fallbacks,
+		health:               options.Health,
+		decimals:             options.Decimals,
+		periods:              options.Periods,
+		volumeDatabase:       options.VolumeDatabase,
 	}
 }
 
-// Start starts the oracle process in a blocking fashion.
+// Start starts the vote loops of all voters in a blocking fashion. It returns
+// once the context is cancelled or Stop is called, after all voters and
+// providers have exited.
 func (o *Oracle) Start(ctx context.Context) error {
-	for {
+	o.mtx.Lock()
+	select {
+	case <-o.closer.Done():
+		o.mtx.Unlock()
+		return nil
+	default:
+	}
+	o.started = true
+	o.mtx.Unlock()
+
+	defer o.stopped.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
 		select {
+		case <-o.closer.Done():
+			cancel()
 		case <-ctx.Done():
-			o.closer.Close()
+		}
+	}()
 
-		default:
-			o.logger.Debug().Msg("starting oracle tick")
+	g, ctx := errgroup.WithContext(ctx)
 
-			startTime := time.Now()
+	for _, voter := range o.voters {
+		voter := voter
+		g.Go(func() error {
+			return voter.Start(ctx)
+		})
+	}
 
-			if err := o.tick(ctx); err != nil {
-				telemetry.IncrCounter(1, "failure", "tick")
-				o.logger.Err(err).Msg("oracle tick failed")
-			}
+	err := g.Wait()
 
-			o.lastPriceSyncTS = time.Now()
+	// the providers share the context of the voters
+	cancel()
+	o.waitProviders()
 
-			telemetry.MeasureSince(startTime, "runtime", "tick")
-			telemetry.IncrCounter(1, "new", "tick")
+	return err
+}
 
-			time.Sleep(tickerSleep)
+// Stop stops the oracle process and waits up to stopTimeout for it to
+// gracefully exit. The databases are closed afterwards.
+func (o *Oracle) Stop() error {
+	o.mtx.Lock()
+	o.closer.Close()
+	started := o.started
+	o.mtx.Unlock()
+
+	var err error
+	if started {
+		select {
+		case <-o.stopped.Done():
+		case <-time.After(stopTimeout):
+			err = fmt.Errorf("oracle didn't stop within %s", stopTimeout)
 		}
 	}
+
+	if closeErr := o.history.Close(); closeErr != nil {
+		o.logger.Err(closeErr).Msg("failed to close price history db")
+	}
+
+	if o.volumeDatabase != nil {
+		if closeErr := o.volumeDatabase.Close(); closeErr != nil {
+			o.logger.Err(closeErr).Msg("failed to close volume db")
+		}
+	}
+
+	return err
 }
 
-// Stop stops the oracle process and waits for it to gracefully exit.
-func (o *Oracle) Stop() {
-	o.closer.Close()
-	<-o.closer.Done()
+// waitProviders blocks until the background routines of all providers have
+// exited.
+func (o *Oracle) waitProviders() {
+	o.syncMtx.Lock()
+	defer o.syncMtx.Unlock()
+
+	for providerName, priceProvider := range o.priceProviders {
+		priceProvider.Wait()
+		o.logger.Debug().
+			Str("provider", providerName.String()).
+			Msg("provider stopped")
+	}
 }
 
 // GetLastPriceSyncTimestamp returns the latest timestamp at which prices where
@@ -202,6 +300,32 @@ func (o *Oracle) GetLastPriceSyncTimestamp() time.Time {
 	return o.lastPriceSyncTS
 }
 
+// GetMissCounters returns the last known miss counters of all voters, that
+// have been fetched yet.
+func (o *Oracle) GetMissCounters() []types.MissCounter {
+	missCounters := []types.MissCounter{}
+	for _, voter := range o.voters {
+		if missCounter, ok := voter.GetMissCounter(); ok {
+			missCounters = append(missCounters, missCounter)
+		}
+	}
+
+	return missCounters
+}
+
+// GetBalances returns the last known balances of all voters, that have been
+// fetched yet.
+func (o *Oracle) GetBalances() []types.FeederBalance {
+	balances := []types.FeederBalance{}
+	for _, voter := range o.voters {
+		if balance, ok := voter.GetBalance(); ok {
+			balances = append(balances, balance)
+		}
+	}
+
+	return balances
+}
+
 // GetPrices returns a copy of the current prices fetched from the oracle's
 // set of exchange rate providers.
 func (o *Oracle) GetPrices() sdk.DecCoins {
@@ -217,6 +341,36 @@ func (o *Oracle) GetPrices() sdk.DecCoins {
 	return prices
 }
 
+// GetAudits returns the price audits of the last price updates, oldest
+// first. If a denom is given, only its audits are returned.
+func (o *Oracle) GetAudits(denom string) []types.PriceAudits {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	audits := make([]types.PriceAudits, len(o.audits))
+	for i, audit := range o.audits {
+		if denom != "" {
+			audit = audit.Filter(denom)
+		}
+		audits[i] = audit
+	}
+
+	return audits
+}
+
+// addAudits retains the audits of a price update, dropping the oldest ones
+// beyond the audit retention. It must be called with the mutex locked.
+func (o *Oracle) addAudits(audits types.PriceAudits) {
+	if o.auditRetention < 1 {
+		return
+	}
+
+	o.audits = append(o.audits, audits)
+	if len(o.audits) > o.auditRetention {
+		o.audits = o.audits[len(o.audits)-o.auditRetention:]
+	}
+}
+
 // SetPrices retrieves all the prices and candles from our set of providers as
 // determined in the config. If candles are available, uses TVWAP in order
 // to determine prices. If candles are not available, uses the most recent prices
@@ -227,6 +381,7 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 	mtx := new(sync.Mutex)
 	requiredRates := make(map[string]struct{})
 	providerPrices := provider.AggregatedProviderPrices{}
+	polls := map[provider.Name]providerPoll{}
 
 	for providerName, currencyPairs := range o.providerPairs {
 		providerName := providerName
@@ -242,7 +397,7 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 			endpoint.Decimals = decimals
 			endpoint.Periods = periods
 
-			newProvider, err := NewProvider(
+			newProvider, err := provider.New(
 				o.volumeDatabase,
 				ctx,
 				providerName,
@@ -281,14 +436,14 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 				}
 			}()
 
+			var err error
 			select {
 			case <-ch:
 				break
-			case err := <-errCh:
-				return err
+			case err = <-errCh:
 			case <-time.After(o.providerTimeout):
 				telemetry.IncrCounter(1, "failure", "provider", "type", "timeout")
-				return fmt.Errorf("provider timed out: %s", providerName)
+				err = fmt.Errorf("provider timed out: %s", providerName)
 			}
 
 			// flatten and collect prices based on the base currency per provider
@@ -297,6 +452,11 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 			mtx.Lock()
 			defer mtx.Unlock()
 
+			if err != nil {
+				polls[providerName] = providerPoll{err: err, pairs: len(currencyPairs)}
+				return err
+			}
+
 			filteredPairs := []types.CurrencyPair{}
 			for _, pair := range currencyPairs {
 				ticker, ok := prices[pair.String()]
@@ -310,6 +470,11 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 				}
 			}
 
+			polls[providerName] = providerPoll{
+				pairs:   len(currencyPairs),
+				tickers: len(filteredPairs),
+			}
+
 			for _, pair := range filteredPairs {
 				ticker := prices[pair.String()]
 				_, isDerivative := o.derivativeSymbols[pair.String()]
@@ -361,18 +526,52 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 		}
 	}
 
-	computedPrices, err := GetComputedPrices(
+	o.mtx.RLock()
+	scores := o.scores
+	o.mtx.RUnlock()
+
+	if scores == nil && o.reputation.Enabled() {
+		scores = o.computeScores(time.Now())
+	}
+
+	options := o.computeOptions(scores)
+	computedPrices, audits, err := GetComputedPrices(
 		o.logger,
 		providerPrices,
 		o.providerPairs,
-		o.deviations,
-		o.providerMinOverrides,
-		o.providerWeights,
+		options,
 	)
 	if err != nil {
 		return err
 	}
 
+	computedPrices, audits, withheld, err := o.applyConsistency(
+		providerPrices, computedPrices, audits, options,
+	)
+	if err != nil {
+		return err
+	}
+
+	o.recordHealth(polls, audits, time.Now())
+
+	if o.reputation.Enabled() {
+		now := time.Now()
+		o.recordDeviations(computedPrices, audits, now)
+		scores = o.computeScores(now)
+	}
+
+	o.checkPegs(audits)
+
+	computed := make(map[string]sdk.Dec, len(computedPrices))
+	for denom, price := range computedPrices {
+		computed[denom] = price
+	}
+
+	computedPrices = o.applyCircuitBreakers(computedPrices, audits, time.Now())
+	computedPrices, fallbackPrices := o.applyFallbacks(
+		computedPrices, computed, withheld, audits, time.Now(),
+	)
+
 	if len(computedPrices) != len(requiredRates) {
 		missingPrices := []string{}
 		for base := range requiredRates {
@@ -385,251 +584,56 @@ func (o *Oracle) SetPrices(ctx context.Context) error {
 		o.logger.Error().Msg(
 			"unable to get prices for: " + strings.Join(missingPrices, ", "),
 		)
-	}
-
-	o.prices = computedPrices
-
-	return nil
-}
-
-// GetComputedPrices gets the candle and ticker prices and computes it.
-// It returns candles' TVWAP if possible, if not possible (not available
-// or due to some staleness) it will use the most recent ticker prices
-// and the VWAP formula instead.
-func GetComputedPrices(
-	logger zerolog.Logger,
-	providerPrices provider.AggregatedProviderPrices,
-	providerPairs map[provider.Name][]types.CurrencyPair,
-	deviations map[string]sdk.Dec,
-	providerMinOverrides map[string]int,
-	providerWeights map[string]ProviderWeight,
-) (prices map[string]sdk.Dec, err error) {
-	rates, err := convertTickersToUSD(
-		logger,
-		providerPrices,
-		providerPairs,
-		deviations,
-		providerMinOverrides,
-		providerWeights,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return rates, nil
-}
-
-// GetParamCache returns the last updated parameters of the x/oracle module
-// if the current ParamCache is outdated, we will query it again.
-func (o *Oracle) GetParamCache(ctx context.Context, currentBlockHeigh int64) (oracletypes.Params, error) {
-	if !o.paramCache.IsOutdated(currentBlockHeigh) {
-		return *o.paramCache.params, nil
-	}
-
-	params, err := o.GetParams(ctx)
-	if err != nil {
-		return oracletypes.Params{}, err
-	}
 
-	o.checkWhitelist(params)
-	o.paramCache.Update(currentBlockHeigh, params)
-	return params, nil
-}
-
-// GetParams returns the current on-chain parameters of the x/oracle module.
-func (o *Oracle) GetParams(ctx context.Context) (oracletypes.Params, error) {
-	grpcConn, err := grpc.Dial(
-		o.oracleClient.GRPCEndpoint,
-		// the Cosmos SDK doesn't support any transport security mechanism
-		grpc.WithInsecure(),
-		grpc.WithContextDialer(dialerFunc),
-	)
-	if err != nil {
-		return oracletypes.Params{}, fmt.Errorf("failed to dial Cosmos gRPC service: %w", err)
+		for _, denom := range missingPrices {
+			if audit, found := audits[denom]; found {
+				o.logger.Warn().
+					Str("denom", denom).
+					Interface("audit", audit).
+					Msg("price audit")
+			}
+		}
 	}
 
-	defer grpcConn.Close()
-	queryClient := oracletypes.NewQueryClient(grpcConn)
-
-	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
-	defer cancel()
-
-	queryResponse, err := queryClient.Params(ctx, &oracletypes.QueryParamsRequest{})
-	if err != nil {
-		return oracletypes.Params{}, fmt.Errorf("failed to get x/oracle params: %w", err)
+	for denom, audit := range audits {
+		o.logger.Debug().
+			Str("denom", denom).
+			Interface("audit", audit).
+			Msg("price audit")
 	}
 
-	return queryResponse.Params, nil
-}
-
-func NewProvider(
-	db *sql.DB,
-	ctx context.Context,
-	providerName provider.Name,
-	logger zerolog.Logger,
-	endpoint provider.Endpoint,
-	providerPairs ...types.CurrencyPair,
-) (provider.Provider, error) {
-	endpoint.Name = providerName
-	providerLogger := logger.With().Str("provider", providerName.String()).Logger()
-	switch providerName {
-
-	case
-		provider.ProviderAstroportNeutron,
-		provider.ProviderAstroportTerra2,
-		provider.ProviderAstroportInjective:
-		return provider.NewAstroportProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case
-		provider.ProviderBinance,
-		provider.ProviderBinanceUS:
-		return provider.NewBinanceProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBingx:
-		return provider.NewBingxProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBitfinex:
-		return provider.NewBitfinexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBitget:
-		return provider.NewBitgetProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBitstamp:
-		return provider.NewBitstampProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBkex:
-		return provider.NewBkexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBitmart:
-		return provider.NewBitmartProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderBybit:
-		return provider.NewBybitProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderCamelotV2, provider.ProviderCamelotV3:
-		return provider.NewCamelotProvider(db, ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderCoinbase:
-		return provider.NewCoinbaseProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderCoinex:
-		return provider.NewCoinexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderCrypto:
-		return provider.NewCryptoProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderCurve:
-		return provider.NewCurveProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderDexter:
-		return provider.NewDexterProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderFin:
-		return provider.NewFinProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderFinV2:
-		return provider.NewFinV2Provider(db, ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderGate:
-		return provider.NewGateProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderHelix:
-		return provider.NewHelixProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderHitBtc:
-		return provider.NewHitBtcProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderHuobi:
-		return provider.NewHuobiProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderIdxOsmosis:
-		return provider.NewIdxProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderKraken:
-		return provider.NewKrakenProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderKucoin:
-		return provider.NewKucoinProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderLbank:
-		return provider.NewLbankProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderMaya:
-		return provider.NewMayaProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderMexc:
-		return provider.NewMexcProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderMock:
-		return provider.NewMockProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderOkx:
-		return provider.NewOkxProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderOsmosis:
-		return provider.NewOsmosisProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderOsmosisV2:
-		return provider.NewOsmosisV2Provider(db, ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderPancakeV3Bsc:
-		return provider.NewPancakeProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderPhemex:
-		return provider.NewPhemexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderPionex:
-		return provider.NewPionexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderPoloniex:
-		return provider.NewPoloniexProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderPyth:
-		return provider.NewPythProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderShade:
-		return provider.NewShadeProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderUniswapV3:
-		return provider.NewUniswapV3Provider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderUnstake:
-		return provider.NewUnstakeProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderVelodromeV2:
-		return provider.NewVelodromeV2Provider(ctx, providerLogger, endpoint, providerPairs...)
-	case
-		provider.ProviderWhitewhaleCmdx,
-		provider.ProviderWhitewhaleHuahua,
-		provider.ProviderWhitewhaleInj,
-		provider.ProviderWhitewhaleJuno,
-		provider.ProviderWhitewhaleLunc,
-		provider.ProviderWhitewhaleLuna,
-		provider.ProviderWhitewhaleSei,
-		provider.ProviderWhitewhaleWhale:
-		return provider.NewWhitewhaleProvider(db, ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderXt:
-		return provider.NewXtProvider(ctx, providerLogger, endpoint, providerPairs...)
-	case provider.ProviderZero:
-		return provider.NewZeroProvider(ctx, providerLogger, endpoint, providerPairs...)
-
-	}
-	return nil, fmt.Errorf("provider %s not found", providerName)
-}
+	o.mtx.Lock()
+	o.prices = computedPrices
+	o.providerPrices = providerPrices
+	o.scores = scores
+	o.fallbackPrices = fallbackPrices
+	o.addAudits(types.PriceAudits{
+		Time:   time.Now(),
+		Audits: audits,
+	})
+	o.mtx.Unlock()
 
-func (o *Oracle) checkWhitelist(params oracletypes.Params) {
-	for _, denom := range params.Whitelist {
-		symbol := strings.ToUpper(denom.Name)
-		if _, ok := o.prices[symbol]; !ok {
-			o.logger.Warn().Str("denom", symbol).Msg("price missing for required denom")
-		}
-	}
+	return nil
 }
 
-func (o *Oracle) tick(ctx context.Context) error {
-	o.logger.Info().Msg("executing oracle tick")
+// initProviders creates and starts all providers, if that hasn't happened
+// yet.
+func (o *Oracle) initProviders(ctx context.Context) {
+	o.syncMtx.Lock()
+	defer o.syncMtx.Unlock()
 
-	// Create and start all provider routines immediately
 	if len(o.priceProviders) == 0 {
-		o.SetPrices(ctx)
-	}
-
-	blockHeight, err := o.oracleClient.ChainHeight.GetChainHeight()
-	if err != nil {
-		return err
-	}
-	if blockHeight < 1 {
-		return fmt.Errorf("expected positive block height")
-	}
-
-	oracleParams, err := o.GetParamCache(ctx, blockHeight)
-	if err != nil {
-		return err
+		_ = o.SetPrices(ctx)
 	}
+}
 
-	// Get oracle vote period, next block height, current vote period, and index
-	// in the vote period.
-	oracleVotePeriod := int64(oracleParams.VotePeriod)
-	nextBlockHeight := blockHeight + 1
-	currentVotePeriod := math.Floor(float64(nextBlockHeight) / float64(oracleVotePeriod))
-	indexInVotePeriod := nextBlockHeight % oracleVotePeriod
-
-	o.logger.Debug().
-		Int64("vote_period", oracleVotePeriod).
-		Float64("previous_vote_period", o.previousVotePeriod).
-		Float64("current_vote_period", currentVotePeriod).
-		Int64("indexInVotePeriod", indexInVotePeriod).
-		Msg("")
-
-	// Skip until new voting period. Specifically, skip when:
-	// index [0, oracleVotePeriod - 1] > oracleVotePeriod - 2 OR index is 0
-	if (o.previousVotePeriod != 0 && currentVotePeriod == o.previousVotePeriod) ||
-		(indexInVotePeriod > 0 && oracleVotePeriod-indexInVotePeriod > 4) {
-		// oracleVotePeriod-indexInVotePeriod < 2 || (indexInVotePeriod > 0 && indexInVotePeriod < int64(float64(oracleVotePeriod)*0.75)) {
-		o.logger.Info().
-			Msg("skipping until next voting period")
+// syncPrices updates the prices, unless they have just been updated for
+// another voter. It's safe to be called by multiple voters concurrently.
+func (o *Oracle) syncPrices(ctx context.Context) error {
+	o.syncMtx.Lock()
+	defer o.syncMtx.Unlock()
 
+	if time.Since(o.GetLastPriceSyncTimestamp()) < pricesMaxAge {
 		return nil
 	}
 
@@ -637,99 +641,61 @@ func (o *Oracle) tick(ctx context.Context) error {
 		return err
 	}
 
-	// If we're past the voting period we needed to hit, reset and submit another
-	// prevote.
-	if o.previousVotePeriod != 0 && currentVotePeriod-o.previousVotePeriod != 1 {
-		o.logger.Info().
-			Msg("missing vote during voting period")
-		telemetry.IncrCounter(1, "vote", "failure", "missed")
-
-		o.previousVotePeriod = 0
-		o.previousPrevote = nil
-		return nil
-	}
-
-	salt, err := GenerateSalt(32)
-	if err != nil {
-		return err
-	}
-
-	valAddr, err := sdk.ValAddressFromBech32(o.oracleClient.ValidatorAddrString)
-	if err != nil {
-		return err
-	}
-
-	exchangeRatesStr := GenerateExchangeRatesString(o.GetPrices())
-	hash := oracletypes.GetAggregateVoteHash(salt, exchangeRatesStr, valAddr)
-	preVoteMsg := &oracletypes.MsgAggregateExchangeRatePrevote{
-		Hash:      hash.String(), // hash of prices from the oracle
-		Feeder:    o.oracleClient.OracleAddrString,
-		Validator: valAddr.String(),
-	}
-
-	isPrevoteOnlyTx := o.previousPrevote == nil
-	if isPrevoteOnlyTx {
-		// This timeout could be as small as oracleVotePeriod-indexInVotePeriod,
-		// but we give it some extra time just in case.
-		//
-		// Ref : https://github.com/terra-money/oracle-feeder/blob/baef2a4a02f57a2ffeaa207932b2e03d7fb0fb25/feeder/src/vote.ts#L222
-		o.logger.Info().
-			Str("hash", hash.String()).
-			Str("validator", preVoteMsg.Validator).
-			Str("feeder", preVoteMsg.Feeder).
-			Msg("broadcasting pre-vote")
-		if err := o.oracleClient.BroadcastTx(nextBlockHeight, oracleVotePeriod*2, preVoteMsg); err != nil {
-			return err
-		}
+	o.mtx.Lock()
+	o.lastPriceSyncTS = time.Now()
+	o.mtx.Unlock()
 
-		currentHeight, err := o.oracleClient.ChainHeight.GetChainHeight()
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		o.previousVotePeriod = math.Floor(float64(currentHeight) / float64(oracleVotePeriod))
-		o.previousPrevote = &PreviousPrevote{
-			Salt:              salt,
-			ExchangeRates:     exchangeRatesStr,
-			SubmitBlockHeight: currentHeight,
-		}
-	} else {
-		// otherwise, we're in the next voting period and thus we vote
-		voteMsg := &oracletypes.MsgAggregateExchangeRateVote{
-			Salt:          o.previousPrevote.Salt,
-			ExchangeRates: o.previousPrevote.ExchangeRates,
-			Feeder:        o.oracleClient.OracleAddrString,
-			Validator:     valAddr.String(),
-		}
+// getProviderPrices returns the provider prices the current prices have been
+// computed from.
+func (o *Oracle) getProviderPrices() provider.AggregatedProviderPrices {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
 
-		o.logger.Info().
-			Str("exchange_rates", voteMsg.ExchangeRates).
-			Str("validator", voteMsg.Validator).
-			Str("feeder", voteMsg.Feeder).
-			Msg("broadcasting vote")
-		if err := o.oracleClient.BroadcastTx(
-			nextBlockHeight,
-			oracleVotePeriod-indexInVotePeriod,
-			voteMsg,
-		); err != nil {
-			return err
-		}
+	return o.providerPrices
+}
 
-		o.previousPrevote = nil
-		o.previousVotePeriod = 0
-		o.healthchecksPing()
+// GetComputedPrices gets the candle and ticker prices and computes it.
+// It returns candles' TVWAP if possible, if not possible (not available
+// or due to some staleness) it will use the most recent ticker prices
+// and the aggregator of each denom (VWAP by default) instead, weighting
+// providers by their capped USD volume and reputation scores and applying
+// the peg policies of stablecoins. The audits
+// explain how the price of each denom has been computed.
+func GetComputedPrices(
+	logger zerolog.Logger,
+	providerPrices provider.AggregatedProviderPrices,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	options ComputeOptions,
+) (prices map[string]sdk.Dec, audits map[string]types.PriceAudit, err error) {
+	rates, audits, err := convertTickersToUSD(
+		logger,
+		providerPrices,
+		providerPairs,
+		options,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return nil
+	return rates, audits, nil
 }
 
-func (o *Oracle) healthchecksPing() {
-	for url, client := range o.healthchecks {
-		o.logger.Info().Msg("updating healthcheck status")
-		_, err := client.Get(url)
-		if err != nil {
-			o.logger.Warn().Msg("healthcheck ping failed")
-		}
+// computeOptions returns the options the prices are computed with, using the
+// given reputation scores and the providers currently quarantined by their
+// health.
+func (o *Oracle) computeOptions(scores map[string]map[string]types.ProviderScore) ComputeOptions {
+	return ComputeOptions{
+		Deviations:           o.deviations,
+		ProviderMinOverrides: o.providerMinOverrides,
+		ProviderWeights:      o.providerWeights,
+		Aggregators:          o.aggregators,
+		VolumeCaps:           o.volumeCaps,
+		Scores:               scores,
+		Pegs:                 o.pegs,
+		Quarantined:          o.quarantinedProviders(),
 	}
 }
 
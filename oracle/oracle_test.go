@@ -43,6 +43,8 @@ func (m mockProvider) CurrencyPairToProviderPair(pair types.CurrencyPair) string
 	return ""
 }
 
+func (m mockProvider) Wait() {}
+
 // func (m mockProvider) ProviderPairToCurrencyPair(pair string) types.CurrencyPair {
 // 	return types.CurrencyPair{}
 // }
@@ -67,7 +69,6 @@ func (ots *OracleTestSuite) SetupSuite() {
 	ots.NoError(err)
 	ots.oracle = New(
 		zerolog.Nop(),
-		client.OracleClient{},
 		[]config.CurrencyPair{
 			{
 				Base:      "UMEE",
@@ -95,22 +96,24 @@ func (ots *OracleTestSuite) SetupSuite() {
 				Providers: []provider.Name{provider.ProviderCoinbase},
 			},
 		},
-		time.Millisecond*100,
-		make(map[string]sdk.Dec),
-		make(map[string]int),
-		make(map[provider.Name]provider.Endpoint),
-		map[string]derivative.Derivative{},
-		map[string][]types.CurrencyPair{},
-		map[string]struct{}{},
+		Options{
+			ProviderTimeout:      time.Millisecond * 100,
+			Deviations:           make(map[string]types.Deviation),
+			ProviderMinOverrides: make(map[string]int),
+			Endpoints:            make(map[provider.Name]provider.Endpoint),
+			Derivatives:          map[string]derivative.Derivative{},
+			DerivativePairs:      map[string][]types.CurrencyPair{},
+			DerivativeDenoms:     map[string]struct{}{},
+			History:              history,
+		},
+	)
+	ots.oracle.NewVoter(
+		client.OracleClient{},
 		[]config.Healthchecks{
 			{URL: "https://hc-ping.com/HEALTHCHECK-UUID", Timeout: "200ms"},
 		},
-		history,
-		nil,
-		nil,
-		nil,
-		nil,
-		nil,
+		MissMonitor{},
+		BalanceMonitor{},
 	)
 }
 
@@ -121,12 +124,15 @@ func TestServiceTestSuite(t *testing.T) {
 func (ots *OracleTestSuite) TestStop() {
 	ots.Eventually(
 		func() bool {
-			ots.oracle.Stop()
+			ots.Require().NoError(ots.oracle.Stop())
 			return true
 		},
 		5*time.Second,
 		time.Second,
 	)
+
+	// a stopped oracle doesn't start its voters anymore
+	ots.Require().NoError(ots.oracle.Start(context.Background()))
 }
 
 func (ots *OracleTestSuite) TestGetLastPriceSyncTimestamp() {
@@ -218,7 +224,7 @@ func (ots *OracleTestSuite) TestPrices() {
 
 	prices = ots.oracle.GetPrices()
 	ots.Require().Len(prices, 4)
-	ots.Require().Equal(sdk.MustNewDecFromStr("3.710916056220858266"), prices.AmountOf("UMEE"))
+	ots.Require().Equal(sdk.MustNewDecFromStr("3.710942777612422485"), prices.AmountOf("UMEE"))
 	ots.Require().Equal(sdk.MustNewDecFromStr("3.717"), prices.AmountOf("XBT"))
 	ots.Require().Equal(sdk.MustNewDecFromStr("1"), prices.AmountOf("USDC"))
 	ots.Require().Equal(sdk.MustNewDecFromStr("1"), prices.AmountOf("USDT"))
@@ -397,13 +403,13 @@ func TestSuccessGetComputedPricesTickers(t *testing.T) {
 		"ATOM": 1,
 	}
 
-	prices, err := GetComputedPrices(
+	prices, _, err := GetComputedPrices(
 		zerolog.Nop(),
 		providerPrices,
 		providerPair,
-		make(map[string]sdk.Dec),
-		providerMinOverrides,
-		nil,
+		ComputeOptions{
+			ProviderMinOverrides: providerMinOverrides,
+		},
 	)
 
 	require.NoError(t, err, "It should successfully get computed ticker prices")
@@ -480,22 +486,53 @@ func TestGetComputedPricesTickersConversion(t *testing.T) {
 		"BTC": 1,
 	}
 
-	prices, err := GetComputedPrices(
+	prices, _, err := GetComputedPrices(
 		zerolog.Nop(),
 		providerPrices,
 		providerPair,
-		make(map[string]sdk.Dec),
-		providerMinOverrides,
-		nil,
+		ComputeOptions{
+			ProviderMinOverrides: providerMinOverrides,
+		},
 	)
 
 	require.NoError(t, err,
 		"It should successfully filter out bad tickers and convert everything to USD",
 	)
+	// both tickers have the same volume, so they are weighted by price
+	btcEthUsdPrice := ethUsdPrice.Mul(btcEthPrice)
 	require.Equal(t,
-
-		ethUsdPrice.Mul(
-			btcEthPrice).Add(btcUsdPrice).Quo(sdk.MustNewDecFromStr("2")),
+		btcEthUsdPrice.Mul(btcEthUsdPrice).Add(btcUsdPrice.Mul(btcUsdPrice)).
+			Quo(btcEthUsdPrice.Add(btcUsdPrice)),
 		prices[btcEthPair.Base],
 	)
 }
+
+func TestGetAudits(t *testing.T) {
+	oracle := &Oracle{auditRetention: 2}
+
+	for i := 0; i < 3; i++ {
+		price := sdk.NewDec(int64(i))
+		oracle.addAudits(types.PriceAudits{
+			Time: time.Unix(int64(i), 0),
+			Audits: map[string]types.PriceAudit{
+				"ATOM": {Denom: "ATOM", Price: &price},
+				"KUJI": {Denom: "KUJI", Error: "not enough tickers"},
+			},
+		})
+	}
+
+	audits := oracle.GetAudits("")
+	require.Len(t, audits, 2)
+	require.Equal(t, time.Unix(1, 0), audits[0].Time)
+	require.Equal(t, time.Unix(2, 0), audits[1].Time)
+	require.Len(t, audits[1].Audits, 2)
+
+	audits = oracle.GetAudits("KUJI")
+	require.Len(t, audits, 2)
+	require.Len(t, audits[1].Audits, 1)
+	require.Equal(t, "not enough tickers", audits[1].Audits["KUJI"].Error)
+
+	oracle = &Oracle{}
+	oracle.addAudits(types.PriceAudits{})
+	require.Empty(t, oracle.GetAudits(""))
+}
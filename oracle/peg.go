@@ -0,0 +1,61 @@
+package oracle
+
+import (
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// checkPegs alerts when a pegged stablecoin depegs or recovers its peg, as
+// detected while computing its price. It must not be called concurrently.
+func (o *Oracle) checkPegs(audits map[string]types.PriceAudit) {
+	if len(o.pegs) == 0 {
+		return
+	}
+
+	if o.depegged == nil {
+		o.depegged = map[string]bool{}
+	}
+
+	for denom, peg := range o.pegs {
+		audit, found := audits[denom]
+		if !found || audit.Peg == nil || audit.Peg.MarketPrice == nil {
+			continue
+		}
+
+		depegged := audit.Peg.Depegged
+		marketPrice := audit.Peg.MarketPrice.String()
+
+		gauge := float32(0)
+		if depegged {
+			gauge = 1
+		}
+		telemetry.SetGaugeWithLabels(
+			[]string{"peg", "depegged"},
+			gauge,
+			[]metrics.Label{telemetry.NewLabel("denom", denom)},
+		)
+
+		if depegged == o.depegged[denom] {
+			continue
+		}
+		o.depegged[denom] = depegged
+
+		if depegged {
+			o.logger.Warn().
+				Str("denom", denom).
+				Str("market_price", marketPrice).
+				Str("threshold", peg.DepegThreshold.String()).
+				Str("conversion", peg.DepegConversion).
+				Msg("stablecoin depegged, price follows the market")
+			continue
+		}
+
+		o.logger.Info().
+			Str("denom", denom).
+			Str("market_price", marketPrice).
+			Str("mode", peg.Mode).
+			Msg("stablecoin pegged again")
+	}
+}
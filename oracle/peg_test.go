@@ -0,0 +1,207 @@
+package oracle
+
+import (
+	"testing"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertTickersToUsdPegs(t *testing.T) {
+	usdtUsd := types.CurrencyPair{Base: "USDT", Quote: "USD"}
+	atomUsdt := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:  {usdtUsd},
+		provider.ProviderBinance: {atomUsdt},
+	}
+
+	providerPrices := func(usdt string) provider.AggregatedProviderPrices {
+		providerPrices := provider.AggregatedProviderPrices{
+			provider.ProviderBinance: {"ATOMUSDT": testTicker("10", "100")},
+		}
+		if usdt != "" {
+			providerPrices[provider.ProviderKraken] = map[string]types.TickerPrice{
+				"USDTUSD": testTicker(usdt, "1000"),
+			}
+		}
+		return providerPrices
+	}
+
+	testCases := []struct {
+		name     string
+		peg      types.Peg
+		usdt     string
+		price    string
+		atom     string
+		depegged bool
+	}{
+		{
+			name:  "fixed",
+			peg:   types.Peg{Mode: types.PegModeFixed},
+			usdt:  "0.95",
+			price: "1",
+			atom:  "10",
+		},
+		{
+			name:  "fixed without market",
+			peg:   types.Peg{Mode: types.PegModeFixed},
+			price: "1",
+			atom:  "10",
+		},
+		{
+			name:  "market",
+			peg:   types.Peg{Mode: types.PegModeMarket},
+			usdt:  "0.998",
+			price: "0.998",
+			atom:  "9.98",
+		},
+		{
+			name:  "within band",
+			peg:   types.Peg{Mode: types.PegModeBand, Band: sdk.MustNewDecFromStr("0.5")},
+			usdt:  "0.996",
+			price: "1",
+			atom:  "10",
+		},
+		{
+			name:  "outside band",
+			peg:   types.Peg{Mode: types.PegModeBand, Band: sdk.MustNewDecFromStr("0.5")},
+			usdt:  "0.99",
+			price: "0.99",
+			atom:  "9.9",
+		},
+		{
+			name: "depegged converted at peg",
+			peg: types.Peg{
+				Mode:            types.PegModeFixed,
+				DepegThreshold:  sdk.NewDec(2),
+				DepegConversion: types.PegConversionPeg,
+			},
+			usdt:     "0.9",
+			price:    "0.9",
+			atom:     "10",
+			depegged: true,
+		},
+		{
+			name: "depegged converted at market",
+			peg: types.Peg{
+				Mode:            types.PegModeFixed,
+				DepegThreshold:  sdk.NewDec(2),
+				DepegConversion: types.PegConversionMarket,
+			},
+			usdt:     "0.9",
+			price:    "0.9",
+			atom:     "9",
+			depegged: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rates, audits, err := convertTickersToUSD(
+				zerolog.Nop(),
+				providerPrices(tc.usdt),
+				providerPairs,
+				ComputeOptions{
+					ProviderMinOverrides: map[string]int{"ATOM": 1, "USDT": 1},
+					Pegs:                 map[string]types.Peg{"USDT": tc.peg},
+				},
+			)
+			require.NoError(t, err)
+
+			require.Equal(t, sdk.MustNewDecFromStr(tc.price), rates["USDT"])
+			require.Equal(t, sdk.MustNewDecFromStr(tc.atom), rates["ATOM"])
+
+			audit := audits["USDT"]
+			require.Empty(t, audit.Error)
+			require.Equal(t, tc.peg.Mode, audit.Peg.Mode)
+			require.Equal(t, tc.depegged, audit.Peg.Depegged)
+
+			conversion := audits["ATOM"].Conversions[0]
+			require.Equal(t, tc.peg.Mode, conversion.Peg.Mode)
+			require.Equal(t, tc.depegged, conversion.Peg.Depegged)
+
+			if tc.usdt == "" {
+				require.Nil(t, audit.Peg.MarketPrice)
+				require.Nil(t, conversion.Peg.MarketPrice)
+				require.Empty(t, conversion.Paths)
+				require.Contains(t, audit.Warnings[0], "no market price, pegged at 1 USD")
+				return
+			}
+
+			require.Equal(t, sdk.MustNewDecFromStr(tc.usdt), *audit.Peg.MarketPrice)
+			require.Equal(t, sdk.MustNewDecFromStr(tc.usdt), *conversion.Peg.MarketPrice)
+		})
+	}
+}
+
+func TestConvertTickersToUsdPegsWithoutMarket(t *testing.T) {
+	usdcUsd := types.CurrencyPair{Base: "USDC", Quote: "USD"}
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderKraken: {"ATOMUSD": testTicker("10", "100")},
+	}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderKraken:   {{Base: "ATOM", Quote: "USD"}},
+		provider.ProviderCoinbase: {usdcUsd},
+	}
+
+	// only stablecoins fixed at 1 USD are priced without a market price
+	rates, audits, err := convertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		ComputeOptions{
+			ProviderMinOverrides: map[string]int{"ATOM": 1, "USDC": 1},
+			Pegs: map[string]types.Peg{
+				"USDC": {Mode: types.PegModeBand, Band: sdk.OneDec()},
+				"USDT": {Mode: types.PegModeFixed},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	require.Equal(t, "no USD rate found", audits["USDC"].Error)
+	require.NotContains(t, audits, "USDT")
+}
+
+func TestCheckPegs(t *testing.T) {
+	oracle := &Oracle{
+		logger: zerolog.Nop(),
+		pegs: map[string]types.Peg{
+			"USDT": {
+				Mode:            types.PegModeFixed,
+				DepegThreshold:  sdk.NewDec(2),
+				DepegConversion: types.PegConversionPeg,
+			},
+		},
+	}
+
+	peg := oracle.pegs["USDT"]
+	audits := func(market string) map[string]types.PriceAudit {
+		auditPeg := peg.Audit(sdk.MustNewDecFromStr(market))
+		return map[string]types.PriceAudit{
+			"USDT": {Denom: "USDT", Peg: &auditPeg},
+		}
+	}
+
+	oracle.checkPegs(audits("0.99"))
+	require.False(t, oracle.depegged["USDT"])
+
+	oracle.checkPegs(audits("0.97"))
+	require.True(t, oracle.depegged["USDT"])
+
+	// a missing market price keeps the state
+	oracle.checkPegs(map[string]types.PriceAudit{
+		"USDT": {Denom: "USDT", Peg: &types.AuditPeg{Mode: types.PegModeFixed}},
+	})
+	require.True(t, oracle.depegged["USDT"])
+
+	oracle.checkPegs(audits("1.01"))
+	require.False(t, oracle.depegged["USDT"])
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -87,6 +88,37 @@ type (
 	}
 )
 
+func init() {
+	constructor := func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewAstroportProvider(ctx, logger, endpoint, pairs...)
+	}
+
+	Register(Registration{
+		Name:         ProviderAstroportInjective,
+		Defaults:     astroportInjectiveDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderAstroportNeutron,
+		Defaults:     astroportNeutronDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderAstroportTerra2,
+		Defaults:     astroportTerra2DefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewAstroportProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -110,7 +142,7 @@ func NewAstroportProvider(
 
 	provider.denoms = provider.getDenoms()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,8 +2,10 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"math/rand"
+	"strings"
 	"time"
 
 	"price-feeder/oracle/types"
@@ -14,14 +16,18 @@ import (
 var (
 	_                       Provider = (*BinanceProvider)(nil)
 	binanceDefaultEndpoints          = Endpoint{
-		Name:         ProviderBinance,
-		Urls:         []string{"https://api.binance.com"},
-		PollInterval: 6 * time.Second,
+		Name:          ProviderBinance,
+		Urls:          []string{"https://api.binance.com"},
+		PollInterval:  6 * time.Second,
+		Websocket:     "stream.binance.com:9443",
+		WebsocketPath: "/ws",
 	}
 	binanceUSDefaultEndpoints = Endpoint{
-		Name:         ProviderBinanceUS,
-		Urls:         []string{"https://api.binance.us"},
-		PollInterval: 6 * time.Second,
+		Name:          ProviderBinanceUS,
+		Urls:          []string{"https://api.binance.us"},
+		PollInterval:  6 * time.Second,
+		Websocket:     "stream.binance.us:9443",
+		WebsocketPath: "/ws",
 	}
 )
 
@@ -40,8 +46,47 @@ type (
 		LastPrice string `json:"lastPrice"` // Last price ex.: 0.0025
 		Volume    string `json:"volume"`    // Total traded base asset volume ex.: 20
 	}
+
+	BinanceMiniTicker struct {
+		Event  string `json:"e"` // Event type ex.: 24hrMiniTicker
+		Time   int64  `json:"E"` // Event time ex.: 1672515782136
+		Symbol string `json:"s"` // Symbol ex.: BTCUSDT
+		Price  string `json:"c"` // Close price ex.: 0.0025
+		Volume string `json:"v"` // Total traded base asset volume ex.: 20
+	}
+
+	BinanceSubscriptionMsg struct {
+		Method string   `json:"method"` // ex.: SUBSCRIBE
+		Params []string `json:"params"` // ex.: ["btcusdt@miniTicker"]
+		ID     uint16   `json:"id"`     // ex.: 1
+	}
 )
 
+func init() {
+	constructor := func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewBinanceProvider(ctx, logger, endpoint, pairs...)
+	}
+
+	Register(Registration{
+		Name:         ProviderBinance,
+		Defaults:     binanceDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+	})
+	Register(Registration{
+		Name:         ProviderBinanceUS,
+		Defaults:     binanceUSDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+	})
+}
+
 func NewBinanceProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -55,8 +100,8 @@ func NewBinanceProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
 	if endpoints.Name == ProviderBinance {
@@ -81,7 +126,7 @@ func NewBinanceProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBinanceSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -127,6 +172,49 @@ func (p *BinanceProvider) Poll() error {
 	return nil
 }
 
+func (p *BinanceProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	symbols := p.getSymbols(pairs...)
+	if len(symbols) == 0 {
+		return []interface{}{}
+	}
+
+	params := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		params[i] = strings.ToLower(symbol) + "@miniTicker"
+	}
+
+	return []interface{}{
+		BinanceSubscriptionMsg{
+			Method: "SUBSCRIBE",
+			Params: params,
+			ID:     1,
+		},
+	}
+}
+
+func (p *BinanceProvider) messageReceived(messageType int, bz []byte) {
+	var ticker BinanceMiniTicker
+	err := json.Unmarshal(bz, &ticker)
+	if err != nil || ticker.Event != "24hrMiniTicker" {
+		// subscription responses
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.isPair(ticker.Symbol) {
+		return
+	}
+
+	p.setStreamedTickerPrice(
+		ticker.Symbol,
+		strToDec(ticker.Price),
+		strToDec(ticker.Volume),
+		time.UnixMilli(ticker.Time),
+	)
+}
+
 func (p *BinanceProvider) GetAvailablePairs() (map[string]struct{}, error) {
 	tickers, err := p.getTickers()
 	if err != nil {
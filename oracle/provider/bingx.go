@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -36,6 +37,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderBingx,
+		Defaults: bingxDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBingxProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewBingxProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -55,7 +73,7 @@ func NewBingxProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBingxSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strings"
 	"time"
@@ -30,6 +31,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderBitfinex,
+		Defaults: bitfinexDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBitfinexProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewBitfinexProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -49,7 +67,7 @@ func NewBitfinexProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBitfinexSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strconv"
 	"time"
@@ -43,6 +44,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderBitget,
+		Defaults: bitgetDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBitgetProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewBitgetProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -62,7 +80,7 @@ func NewBitgetProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBitgetSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -44,6 +45,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderBitmart,
+		Defaults: bitmartDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBitmartProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewBitmartProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -63,7 +81,7 @@ func NewBitmartProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBitmartSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -35,6 +36,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderBitstamp,
+		Defaults: bitstampDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBitstampProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewBitstampProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -54,7 +72,7 @@ func NewBitstampProvider(
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBitstampSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
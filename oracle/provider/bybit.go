@@ -2,20 +2,32 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"price-feeder/oracle/types"
 
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 )
 
+// bybitMaxSubscriptionArgs is the maximum number of topics per subscription
+// message.
+const bybitMaxSubscriptionArgs = 10
+
 var (
 	_                     Provider = (*BybitProvider)(nil)
 	bybitDefaultEndpoints          = Endpoint{
-		Name:         ProviderBybit,
-		Urls:         []string{"https://api.bybit.com", "https://api.bytick.com"},
-		PollInterval: 2 * time.Second,
+		Name:          ProviderBybit,
+		Urls:          []string{"https://api.bybit.com", "https://api.bytick.com"},
+		PollInterval:  2 * time.Second,
+		Websocket:     "stream.bybit.com",
+		WebsocketPath: "/v5/public/spot",
+		PingDuration:  20 * time.Second,
+		PingType:      websocket.TextMessage,
+		PingMessage:   `{"op":"ping"}`,
 	}
 )
 
@@ -41,8 +53,36 @@ type (
 		Price  string `json:"lastPrice"` // ex.: "21127.86"
 		Volume string `json:"volume24h"` // ex.: "211.378621"
 	}
+
+	BybitTickerMsg struct {
+		Topic string      `json:"topic"` // ex.: "tickers.BTCUSDT"
+		Time  int64       `json:"ts"`    // ex.: 1673853746003
+		Data  BybitTicker `json:"data"`
+	}
+
+	BybitSubscriptionMsg struct {
+		Op   string   `json:"op"`   // ex.: "subscribe"
+		Args []string `json:"args"` // ex.: ["tickers.BTCUSDT"]
+	}
 )
 
+func init() {
+	Register(Registration{
+		Name:         ProviderBybit,
+		Defaults:     bybitDefaultEndpoints,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewBybitProvider(ctx, logger, endpoint, pairs...)
+		},
+	})
+}
+
 func NewBybitProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -55,14 +95,14 @@ func NewBybitProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToBybitSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -108,6 +148,49 @@ func (p *BybitProvider) Poll() error {
 	return nil
 }
 
+func (p *BybitProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	msgs := []interface{}{}
+
+	args := []string{}
+	for _, symbol := range p.getSymbols(pairs...) {
+		args = append(args, "tickers."+symbol)
+
+		if len(args) == bybitMaxSubscriptionArgs {
+			msgs = append(msgs, BybitSubscriptionMsg{Op: "subscribe", Args: args})
+			args = []string{}
+		}
+	}
+
+	if len(args) > 0 {
+		msgs = append(msgs, BybitSubscriptionMsg{Op: "subscribe", Args: args})
+	}
+
+	return msgs
+}
+
+func (p *BybitProvider) messageReceived(messageType int, bz []byte) {
+	var msg BybitTickerMsg
+	err := json.Unmarshal(bz, &msg)
+	if err != nil || !strings.HasPrefix(msg.Topic, "tickers.") {
+		// subscription and pong responses
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.isPair(msg.Data.Symbol) {
+		return
+	}
+
+	p.setStreamedTickerPrice(
+		msg.Data.Symbol,
+		strToDec(msg.Data.Price),
+		strToDec(msg.Data.Volume),
+		time.UnixMilli(msg.Time),
+	)
+}
+
 func (p *BybitProvider) GetAvailablePairs() (map[string]struct{}, error) {
 	tickers, err := p.getTickers()
 	if err != nil {
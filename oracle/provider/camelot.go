@@ -47,6 +47,31 @@ type (
 	}
 )
 
+func init() {
+	constructor := func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewCamelotProvider(db, ctx, logger, endpoint, pairs...)
+	}
+
+	Register(Registration{
+		Name:         ProviderCamelotV2,
+		Defaults:     camelotV2DefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderCamelotV3,
+		Defaults:     camelotV3DefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+}
+
 func NewCamelotProvider(
 	db *sql.DB,
 	ctx context.Context,
@@ -86,7 +111,7 @@ func NewCamelotProvider(
 
 	provider.init()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -0,0 +1,26 @@
+package provider
+
+const (
+	// SourceClassCEX is the source class of centralized exchanges and price
+	// services mostly based on them.
+	SourceClassCEX = "cex"
+	// SourceClassDEX is the source class of providers using on-chain data.
+	SourceClassDEX = "dex"
+)
+
+// IsSourceClass returns whether the class is a known source class.
+func IsSourceClass(class string) bool {
+	return class == SourceClassCEX || class == SourceClassDEX
+}
+
+// SourceClass returns whether the provider is a centralized exchange or an
+// on-chain source, as declared on its registration, so price moves can be
+// confirmed by independent sources. Unregistered providers are treated as
+// centralized exchanges.
+func (n Name) SourceClass() string {
+	registration, found := registry[n]
+	if !found {
+		return SourceClassCEX
+	}
+	return registration.Capabilities.Class
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -14,8 +15,9 @@ import (
 var (
 	_                        Provider = (*CoinbaseProvider)(nil)
 	coinbaseDefaultEndpoints          = Endpoint{
-		Name: ProviderCoinbase,
-		Urls: []string{"https://api.exchange.coinbase.com"},
+		Name:      ProviderCoinbase,
+		Urls:      []string{"https://api.exchange.coinbase.com"},
+		Websocket: "ws-feed.exchange.coinbase.com",
 	}
 )
 
@@ -37,8 +39,40 @@ type (
 	CoinbaseTradingPair struct {
 		Symbol string `json:"id"` // ex.: "ADA-BTC"
 	}
+
+	CoinbaseTickerMsg struct {
+		Type    string `json:"type"`       // ex.: "ticker"
+		Message string `json:"message"`    // ex.: "Failed to subscribe"
+		Symbol  string `json:"product_id"` // ex.: "BTC-USD"
+		Price   string `json:"price"`      // ex.: "24014.11"
+		Volume  string `json:"volume_24h"` // ex.: "7421.5009"
+		Time    string `json:"time"`       // ex.: "2022-10-19T23:28:22.061769Z"
+	}
+
+	CoinbaseSubscriptionMsg struct {
+		Type       string   `json:"type"`        // ex.: "subscribe"
+		ProductIDs []string `json:"product_ids"` // ex.: ["BTC-USD"]
+		Channels   []string `json:"channels"`    // ex.: ["ticker"]
+	}
 )
 
+func init() {
+	Register(Registration{
+		Name:         ProviderCoinbase,
+		Defaults:     coinbaseDefaultEndpoints,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewCoinbaseProvider(ctx, logger, endpoint, pairs...)
+		},
+	})
+}
+
 func NewCoinbaseProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -51,8 +85,8 @@ func NewCoinbaseProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
 	availablePairs, _ := provider.GetAvailablePairs()
@@ -60,14 +94,17 @@ func NewCoinbaseProvider(
 
 	interval := time.Duration(len(provider.getAllPairs())/10*2+1) * time.Second
 
-	go startPolling(provider, interval, logger)
+	provider.startPolling(provider, interval, logger)
 	return provider, nil
 }
 
 func (p *CoinbaseProvider) Poll() error {
 	i := 0
 	for symbol, pair := range p.getAllPairs() {
+		p.wg.Add(1)
 		go func(p *CoinbaseProvider, symbol string, pair types.CurrencyPair) {
+			defer p.wg.Done()
+
 			path := fmt.Sprintf("/products/%s/ticker", symbol)
 			content, err := p.httpGet(path)
 			if err != nil {
@@ -97,7 +134,9 @@ func (p *CoinbaseProvider) Poll() error {
 		i = i + 1
 		if i == 10 {
 			i = 0
-			time.Sleep(time.Millisecond * 1200)
+			if !p.sleep(time.Millisecond * 1200) {
+				break
+			}
 		}
 	}
 
@@ -105,6 +144,60 @@ func (p *CoinbaseProvider) Poll() error {
 	return nil
 }
 
+func (p *CoinbaseProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	symbols := p.getSymbols(pairs...)
+	if len(symbols) == 0 {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		CoinbaseSubscriptionMsg{
+			Type:       "subscribe",
+			ProductIDs: symbols,
+			Channels:   []string{"ticker"},
+		},
+	}
+}
+
+func (p *CoinbaseProvider) messageReceived(messageType int, bz []byte) {
+	var msg CoinbaseTickerMsg
+	err := json.Unmarshal(bz, &msg)
+	if err != nil {
+		return
+	}
+
+	if msg.Type == "error" {
+		p.logger.Warn().
+			Str("message", msg.Message).
+			Msg("websocket error")
+		return
+	}
+
+	if msg.Type != "ticker" {
+		// subscriptions
+		return
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, msg.Time)
+	if err != nil {
+		timestamp = time.Now()
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.isPair(msg.Symbol) {
+		return
+	}
+
+	p.setStreamedTickerPrice(
+		msg.Symbol,
+		strToDec(msg.Price),
+		strToDec(msg.Volume),
+		timestamp,
+	)
+}
+
 func (p *CoinbaseProvider) GetAvailablePairs() (map[string]struct{}, error) {
 	content, err := p.httpGet("/products")
 	if err != nil {
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -33,6 +34,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderDexter,
+		Defaults: dexterDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewDexterProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewDexterProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -56,7 +74,7 @@ func NewDexterProvider(
 
 	provider.denoms = provider.getDenoms()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -56,6 +56,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderFinV2,
+		Defaults: finV2DefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewFinV2Provider(db, ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+}
+
 func NewFinV2Provider(
 	db *sql.DB,
 	ctx context.Context,
@@ -79,7 +96,7 @@ func NewFinV2Provider(
 
 	provider.delta = map[string]int64{}
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 
 	return provider, nil
 }
@@ -213,7 +230,9 @@ func (p *FinV2Provider) updateVolumes() {
 
 	for _, height := range missing {
 		volume, err := p.getVolume(height)
-		time.Sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause))
+		if !p.sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause)) {
+			break
+		}
 		if err != nil {
 			p.error(err)
 			continue
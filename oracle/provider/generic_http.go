@@ -0,0 +1,475 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	genericHTTPDefaultPollInterval = 10 * time.Second
+	genericHTTPDefaultSymbol       = "{base}{quote}"
+)
+
+var (
+	_                           Provider = (*GenericHTTPProvider)(nil)
+	genericHTTPDefaultEndpoints          = Endpoint{
+		Name:         ProviderGenericHTTP,
+		PollInterval: genericHTTPDefaultPollInterval,
+	}
+)
+
+type (
+	// GenericHTTPProvider defines an Oracle provider for simple REST
+	// endpoints returning JSON, declared entirely in the config.
+	//
+	// The request path either contains {symbol}, {base} or {quote}, in which
+	// case every pair is requested on its own, or returns the tickers of all
+	// pairs at once.
+	GenericHTTPProvider struct {
+		provider
+		settings GenericHTTP
+	}
+
+	// GenericHTTP defines the request path and where to find the values in
+	// the response. Paths into the response are dot separated keys or array
+	// indices, ex. "data.0.last".
+	GenericHTTP struct {
+		// Path of the request, ex. "/api/v1/ticker?symbol={symbol}".
+		Path string
+		// TickersPath points to the array or object of all tickers, if the
+		// response is not for a single pair.
+		TickersPath string
+		// SymbolPath points to the symbol of a ticker. If it is empty, the
+		// keys of the tickers object are used as symbols.
+		SymbolPath    string
+		PricePath     string
+		VolumePath    string
+		TimestampPath string
+		// Symbol is the template of the provider symbols, ex. "{base}-{quote}".
+		Symbol string
+		// Symbols maps denoms to the ones used by the provider.
+		Symbols map[string]string
+		// Class is the source class of the source, SourceClassCEX if empty.
+		Class string
+	}
+)
+
+func init() {
+	Register(Registration{
+		Name:     ProviderGenericHTTP,
+		Defaults: genericHTTPDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewGenericHTTPProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
+func NewGenericHTTPProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoints Endpoint,
+	pairs ...types.CurrencyPair,
+) (*GenericHTTPProvider, error) {
+	if endpoints.GenericHTTP == nil {
+		return nil, fmt.Errorf("no generic http settings for %s", endpoints.Name)
+	}
+
+	provider := &GenericHTTPProvider{
+		settings: *endpoints.GenericHTTP,
+	}
+
+	if provider.settings.Symbol == "" {
+		provider.settings.Symbol = genericHTTPDefaultSymbol
+	}
+
+	provider.Init(
+		ctx,
+		endpoints,
+		logger,
+		pairs,
+		nil,
+		nil,
+	)
+
+	if provider.endpoints.PollInterval == 0 {
+		provider.endpoints.PollInterval = genericHTTPDefaultPollInterval
+	}
+
+	var availablePairs map[string]struct{}
+	if provider.isPerPair() {
+		availablePairs = map[string]struct{}{}
+		for _, pair := range pairs {
+			availablePairs[provider.currencyPairToSymbol(pair)] = struct{}{}
+		}
+	} else {
+		availablePairs, _ = provider.GetAvailablePairs()
+	}
+
+	provider.setPairs(pairs, availablePairs, provider.currencyPairToSymbol)
+
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
+	return provider, nil
+}
+
+func (p *GenericHTTPProvider) Poll() error {
+	if p.isPerPair() {
+		return p.pollPairs()
+	}
+
+	tickers, err := p.getTickers()
+	if err != nil {
+		return err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	now := time.Now()
+
+	for symbol, ticker := range tickers {
+		if !p.isPair(symbol) {
+			continue
+		}
+
+		p.setTicker(symbol, ticker, now)
+	}
+
+	p.logger.Debug().Msg("updated tickers")
+	return nil
+}
+
+func (p *GenericHTTPProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	if p.isPerPair() {
+		symbols := map[string]struct{}{}
+		for symbol := range p.getAllPairs() {
+			symbols[symbol] = struct{}{}
+		}
+		return symbols, nil
+	}
+
+	tickers, err := p.getTickers()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := map[string]struct{}{}
+	for symbol := range tickers {
+		symbols[symbol] = struct{}{}
+	}
+
+	return symbols, nil
+}
+
+// pollPairs requests the ticker of every pair on its own. The paths into
+// the response are relative to its root.
+func (p *GenericHTTPProvider) pollPairs() error {
+	p.mtx.RLock()
+	pairs := map[string]types.CurrencyPair{}
+	for symbol, pair := range p.pairs {
+		pairs[symbol] = pair
+	}
+	p.mtx.RUnlock()
+
+	tickers := map[string]interface{}{}
+	for symbol, pair := range pairs {
+		content, err := p.httpGet(p.requestPath(pair))
+		if err != nil {
+			p.logger.Warn().
+				Err(err).
+				Str("pair", pair.String()).
+				Msg("failed to request ticker")
+			continue
+		}
+
+		ticker, err := decodeJSON(content)
+		if err != nil {
+			p.logger.Warn().
+				Err(err).
+				Str("pair", pair.String()).
+				Msg("failed to decode ticker")
+			continue
+		}
+
+		tickers[symbol] = ticker
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	now := time.Now()
+
+	for symbol, ticker := range tickers {
+		p.setTicker(symbol, ticker, now)
+	}
+
+	p.logger.Debug().Msg("updated tickers")
+	return nil
+}
+
+// getTickers returns the tickers of the response by their symbol.
+func (p *GenericHTTPProvider) getTickers() (map[string]interface{}, error) {
+	content, err := p.httpGet(p.settings.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeJSON(content)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsonPathValue(response, p.settings.TickersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := map[string]interface{}{}
+
+	switch value := value.(type) {
+	case []interface{}:
+		if p.settings.SymbolPath == "" {
+			return nil, fmt.Errorf("symbol path required for an array of tickers")
+		}
+		for _, ticker := range value {
+			symbol, err := jsonPathValue(ticker, p.settings.SymbolPath)
+			if err != nil {
+				return nil, err
+			}
+			tickers[fmt.Sprint(symbol)] = ticker
+		}
+
+	case map[string]interface{}:
+		for key, ticker := range value {
+			symbol := key
+			if p.settings.SymbolPath != "" {
+				value, err := jsonPathValue(ticker, p.settings.SymbolPath)
+				if err != nil {
+					return nil, err
+				}
+				symbol = fmt.Sprint(value)
+			}
+			tickers[symbol] = ticker
+		}
+
+	default:
+		return nil, fmt.Errorf("tickers are neither an array nor an object")
+	}
+
+	return tickers, nil
+}
+
+// setTicker reads the price, volume and timestamp of the ticker. It must be
+// called with the lock held.
+func (p *GenericHTTPProvider) setTicker(
+	symbol string,
+	ticker interface{},
+	now time.Time,
+) {
+	price, volume, timestamp, err := p.parseTicker(ticker, now)
+	if err != nil {
+		p.logger.Warn().
+			Err(err).
+			Str("symbol", symbol).
+			Msg("failed to parse ticker")
+		return
+	}
+
+	p.setTickerPrice(symbol, price, volume, timestamp)
+}
+
+func (p *GenericHTTPProvider) parseTicker(
+	ticker interface{},
+	now time.Time,
+) (sdk.Dec, sdk.Dec, time.Time, error) {
+	value, err := jsonPathValue(ticker, p.settings.PricePath)
+	if err != nil {
+		return sdk.Dec{}, sdk.Dec{}, now, err
+	}
+
+	price, err := jsonDec(value)
+	if err != nil {
+		return sdk.Dec{}, sdk.Dec{}, now, err
+	}
+
+	volume := sdk.ZeroDec()
+	if p.settings.VolumePath != "" {
+		value, err = jsonPathValue(ticker, p.settings.VolumePath)
+		if err != nil {
+			return sdk.Dec{}, sdk.Dec{}, now, err
+		}
+
+		volume, err = jsonDec(value)
+		if err != nil {
+			return sdk.Dec{}, sdk.Dec{}, now, err
+		}
+	}
+
+	timestamp := now
+	if p.settings.TimestampPath != "" {
+		value, err = jsonPathValue(ticker, p.settings.TimestampPath)
+		if err != nil {
+			return sdk.Dec{}, sdk.Dec{}, now, err
+		}
+
+		timestamp, err = jsonTime(value)
+		if err != nil {
+			return sdk.Dec{}, sdk.Dec{}, now, err
+		}
+	}
+
+	return price, volume, timestamp, nil
+}
+
+// isPerPair returns whether every pair has to be requested on its own.
+func (p *GenericHTTPProvider) isPerPair() bool {
+	for _, placeholder := range []string{"{symbol}", "{base}", "{quote}"} {
+		if strings.Contains(p.settings.Path, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *GenericHTTPProvider) requestPath(pair types.CurrencyPair) string {
+	return strings.NewReplacer(
+		"{symbol}", p.currencyPairToSymbol(pair),
+		"{base}", p.mapDenom(pair.Base),
+		"{quote}", p.mapDenom(pair.Quote),
+	).Replace(p.settings.Path)
+}
+
+func (p *GenericHTTPProvider) currencyPairToSymbol(pair types.CurrencyPair) string {
+	return strings.NewReplacer(
+		"{base}", p.mapDenom(pair.Base),
+		"{quote}", p.mapDenom(pair.Quote),
+	).Replace(p.settings.Symbol)
+}
+
+func (p *GenericHTTPProvider) mapDenom(denom string) string {
+	mapped, found := p.settings.Symbols[denom]
+	if !found {
+		return denom
+	}
+	return mapped
+}
+
+// decodeJSON decodes the content keeping numbers as json.Number, so prices
+// don't lose precision.
+func decodeJSON(content []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// jsonPathValue returns the value at the dot separated path of keys and
+// array indices. An empty path returns the value itself.
+func jsonPathValue(value interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return value, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			next, found := current[key]
+			if !found {
+				return nil, fmt.Errorf("key %s of %s not found", key, path)
+			}
+			value = next
+
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, fmt.Errorf("index %s of %s not found", key, path)
+			}
+			value = current[index]
+
+		default:
+			return nil, fmt.Errorf("cannot resolve %s of %s", key, path)
+		}
+	}
+
+	return value, nil
+}
+
+// jsonDec converts a JSON number or numeric string to sdk.Dec.
+func jsonDec(value interface{}) (sdk.Dec, error) {
+	var str string
+	switch value := value.(type) {
+	case json.Number:
+		str = value.String()
+	case string:
+		str = value
+	default:
+		return sdk.Dec{}, fmt.Errorf("%v is not a number", value)
+	}
+
+	if strings.ContainsAny(str, "eE") {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+		return floatToDec(f), nil
+	}
+
+	// sdk.NewDecFromStr fails if decimal precision is greater than 18
+	split := strings.Split(str, ".")
+	if len(split) == 2 && len(split[1]) > 18 {
+		str = split[0] + "." + split[1][:18]
+	}
+
+	return sdk.NewDecFromStr(str)
+}
+
+// jsonTime converts a RFC3339 timestamp or a unix timestamp in seconds or
+// milliseconds to time.Time.
+func jsonTime(value interface{}) (time.Time, error) {
+	var str string
+	switch value := value.(type) {
+	case json.Number:
+		str = value.String()
+	case string:
+		timestamp, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return timestamp, nil
+		}
+		str = value
+	default:
+		return time.Time{}, fmt.Errorf("%v is not a timestamp", value)
+	}
+
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not a timestamp", str)
+	}
+
+	// unix timestamps in seconds won't reach 1e12 for another 30000 years
+	if f >= 1e12 {
+		return time.UnixMilli(int64(f)), nil
+	}
+
+	return time.UnixMilli(int64(f * 1000)), nil
+}
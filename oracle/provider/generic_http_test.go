@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericHTTPProvider_Tickers(t *testing.T) {
+	now := time.Now()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/api/tickers", req.URL.String())
+		timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+		rw.Write([]byte(`{"data": [
+			{"s": "ATOM_USDT", "p": "12.3456", "v": 1000, "t": ` + timestamp + `},
+			{"s": "USDT_BTC", "p": 0.00005, "v": "10", "t": ` + timestamp + `},
+			{"s": "POL_USDT", "p": 0.5, "v": "10", "t": ` + timestamp + `},
+			{"s": "FOO_USDT", "p": "foo", "t": ` + timestamp + `},
+			{"s": "BAR_USDT", "p": 1}
+		]}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pairs := []types.CurrencyPair{
+		testAtomUsdtCurrencyPair,
+		testBtcUsdtCurrencyPair,
+		{Base: "MATIC", Quote: "USDT"},
+	}
+
+	provider, err := NewGenericHTTPProvider(
+		ctx,
+		zerolog.Nop(),
+		Endpoint{
+			Name: "foobar",
+			Urls: []string{server.URL},
+			GenericHTTP: &GenericHTTP{
+				Path:          "/api/tickers",
+				TickersPath:   "data",
+				SymbolPath:    "s",
+				PricePath:     "p",
+				VolumePath:    "v",
+				TimestampPath: "t",
+				Symbol:        "{base}_{quote}",
+				Symbols:       map[string]string{"MATIC": "POL"},
+			},
+		},
+		pairs...,
+	)
+	require.NoError(t, err)
+	defer provider.Wait()
+	defer cancel()
+
+	require.NoError(t, provider.Poll())
+
+	prices, err := provider.GetTickerPrices(pairs...)
+	require.NoError(t, err)
+	require.Len(t, prices, 3)
+
+	require.Equal(t, testAtomPriceDec, prices["ATOMUSDT"].Price)
+	require.Equal(t, sdk.NewDec(1000), prices["ATOMUSDT"].Volume)
+	require.Equal(t, now.UnixMilli(), prices["ATOMUSDT"].Time.UnixMilli())
+
+	require.Equal(t, sdk.NewDec(20000), prices["BTCUSDT"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("0.0005"), prices["BTCUSDT"].Volume)
+
+	require.Equal(t, sdk.MustNewDecFromStr("0.5"), prices["MATICUSDT"].Price)
+}
+
+func TestGenericHTTPProvider_Pairs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/ticker?market=atom-usdt":
+			rw.Write([]byte(`{"result": [{"last": "12.3456", "time": "2024-01-02T03:04:05Z"}]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pairs := []types.CurrencyPair{testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair}
+
+	provider, err := NewGenericHTTPProvider(
+		ctx,
+		zerolog.Nop(),
+		Endpoint{
+			Name: ProviderGenericHTTP,
+			Urls: []string{server.URL},
+			GenericHTTP: &GenericHTTP{
+				Path:          "/ticker?market={symbol}",
+				PricePath:     "result.0.last",
+				TimestampPath: "result.0.time",
+				Symbol:        "{base}-{quote}",
+				Symbols:       map[string]string{"ATOM": "atom", "USDT": "usdt"},
+			},
+		},
+		pairs...,
+	)
+	require.NoError(t, err)
+	defer provider.Wait()
+	defer cancel()
+
+	require.NoError(t, provider.Poll())
+
+	provider.mtx.RLock()
+	ticker := provider.tickers["ATOMUSDT"]
+	_, found := provider.tickers["BTCUSDT"]
+	provider.mtx.RUnlock()
+
+	require.Equal(t, testAtomPriceDec, ticker.Price)
+	require.True(t, ticker.Volume.IsZero())
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ticker.Time.UTC())
+	require.False(t, found)
+}
+
+func TestGenericHTTPProvider_MissingSettings(t *testing.T) {
+	_, err := NewGenericHTTPProvider(
+		context.Background(),
+		zerolog.Nop(),
+		Endpoint{Name: ProviderGenericHTTP},
+	)
+	require.Error(t, err)
+
+	_, err = New(nil, context.Background(), "foobar", zerolog.Nop(), Endpoint{})
+	require.EqualError(t, err, "provider foobar not found")
+}
+
+func TestJsonPathValue(t *testing.T) {
+	value, err := decodeJSON([]byte(`{"data": {"tickers": [{"price": 1.5}]}}`))
+	require.NoError(t, err)
+
+	price, err := jsonPathValue(value, "data.tickers.0.price")
+	require.NoError(t, err)
+	require.Equal(t, json.Number("1.5"), price)
+
+	for _, path := range []string{"data.foo", "data.tickers.1", "data.tickers.foo", "data.tickers.0.price.foo"} {
+		_, err = jsonPathValue(value, path)
+		require.Error(t, err, path)
+	}
+}
+
+func TestJsonDec(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected sdk.Dec
+	}{
+		{json.Number("12.3456"), testAtomPriceDec},
+		{"12.3456", testAtomPriceDec},
+		{json.Number("1e-5"), sdk.MustNewDecFromStr("0.00001")},
+		{"3.323454654756344465786786524", sdk.MustNewDecFromStr("3.323454654756344465")},
+	}
+
+	for _, tc := range testCases {
+		dec, err := jsonDec(tc.value)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.expected, dec, tc.value)
+	}
+
+	for _, value := range []interface{}{"foo", "NaN", true, nil} {
+		_, err := jsonDec(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestJsonTime(t *testing.T) {
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, value := range []interface{}{
+		"2024-01-02T03:04:05Z",
+		json.Number("1704164645"),
+		json.Number("1704164645000"),
+		"1704164645",
+	} {
+		timestamp, err := jsonTime(value)
+		require.NoError(t, err, value)
+		require.True(t, expected.Equal(timestamp), value)
+	}
+
+	_, err := jsonTime("yesterday")
+	require.Error(t, err)
+}
@@ -2,11 +2,13 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
 	"price-feeder/oracle/types"
 
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 )
 
@@ -16,6 +18,12 @@ var (
 		Name:         ProviderKraken,
 		Urls:         []string{"https://api.kraken.com"},
 		PollInterval: 2 * time.Second,
+		Websocket:    "ws.kraken.com",
+		PingDuration: defaultPingDuration,
+		PingType:     websocket.TextMessage,
+		PingMessage:  `{"event":"ping"}`,
+		// public endpoints allow about one request per second
+		RateLimit: RateLimit{Rate: 1, Burst: 5},
 	}
 )
 
@@ -24,8 +32,13 @@ type (
 	// public API.
 	//
 	// REF: https://docs.kraken.com/rest
+	// REF: https://docs.kraken.com/websockets
 	KrakenProvider struct {
 		provider
+		// websocket pair names by rest symbol and vice versa,
+		// ex.: "XBT/USD" and "XXBTZUSD"
+		websocketNames map[string]string
+		restSymbols    map[string]string
 	}
 
 	KrakenTickerResponse struct {
@@ -44,8 +57,35 @@ type (
 	KrakenPair struct {
 		WsName string `json:"wsname"` // ex.: "XBT/USD"
 	}
+
+	KrakenSubscriptionMsg struct {
+		Event        string                    `json:"event"` // ex.: "subscribe"
+		Pair         []string                  `json:"pair"`  // ex.: ["XBT/USD"]
+		Subscription KrakenSubscriptionChannel `json:"subscription"`
+	}
+
+	KrakenSubscriptionChannel struct {
+		Name string `json:"name"` // ex.: "ticker"
+	}
 )
 
+func init() {
+	Register(Registration{
+		Name:         ProviderKraken,
+		Defaults:     krakenDefaultEndpoints,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewKrakenProvider(ctx, logger, endpoint, pairs...)
+		},
+	})
+}
+
 func NewKrakenProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -58,14 +98,21 @@ func NewKrakenProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToKrakenSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	err := provider.setWebsocketNames()
+	if err != nil {
+		provider.logger.Warn().
+			Err(err).
+			Msg("failed to get websocket pair names")
+	}
+
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -111,6 +158,101 @@ func (p *KrakenProvider) Poll() error {
 	return nil
 }
 
+// setWebsocketNames gets the websocket names of all rest symbols, which
+// differ for some assets, ex.: "XXBTZUSD" is "XBT/USD".
+func (p *KrakenProvider) setWebsocketNames() error {
+	p.websocketNames = map[string]string{}
+	p.restSymbols = map[string]string{}
+
+	content, err := p.httpGet("/0/public/AssetPairs")
+	if err != nil {
+		return err
+	}
+
+	var pairs KrakenPairsResponse
+	err = json.Unmarshal(content, &pairs)
+	if err != nil {
+		return err
+	}
+
+	for symbol, pair := range pairs.Result {
+		if pair.WsName == "" {
+			continue
+		}
+		p.websocketNames[symbol] = pair.WsName
+		p.restSymbols[pair.WsName] = symbol
+	}
+
+	return nil
+}
+
+func (p *KrakenProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	names := []string{}
+	for _, symbol := range p.getSymbols(pairs...) {
+		name, found := p.websocketNames[symbol]
+		if !found {
+			p.logger.Warn().
+				Str("symbol", symbol).
+				Msg("no websocket name")
+			continue
+		}
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		KrakenSubscriptionMsg{
+			Event:        "subscribe",
+			Pair:         names,
+			Subscription: KrakenSubscriptionChannel{Name: "ticker"},
+		},
+	}
+}
+
+// messageReceived handles the ticker messages, which are arrays of the
+// channel id, the ticker, the channel name and the pair name. Other
+// messages, like heartbeats, are objects.
+func (p *KrakenProvider) messageReceived(messageType int, bz []byte) {
+	var msg []json.RawMessage
+	err := json.Unmarshal(bz, &msg)
+	if err != nil || len(msg) != 4 {
+		return
+	}
+
+	var channel, name string
+	var ticker KrakenTicker
+	if json.Unmarshal(msg[2], &channel) != nil || channel != "ticker" ||
+		json.Unmarshal(msg[3], &name) != nil ||
+		json.Unmarshal(msg[1], &ticker) != nil {
+		p.logger.Debug().
+			Str("msg", string(bz)).
+			Msg("unexpected websocket message")
+		return
+	}
+
+	symbol, found := p.restSymbols[name]
+	if !found {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.isPair(symbol) {
+		return
+	}
+
+	p.setStreamedTickerPrice(
+		symbol,
+		strToDec(ticker.Price[0]),
+		strToDec(ticker.Volume[1]),
+		time.Now(),
+	)
+}
+
 func (p *KrakenProvider) GetAvailablePairs() (map[string]struct{}, error) {
 	tickers, err := p.getTickers()
 	if err != nil {
@@ -2,20 +2,35 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"price-feeder/oracle/types"
 
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 )
 
+// kucoinMaxSubscriptionSymbols is the maximum number of symbols per
+// subscription topic.
+const kucoinMaxSubscriptionSymbols = 100
+
 var (
 	_                      Provider = (*KucoinProvider)(nil)
 	kucoinDefaultEndpoints          = Endpoint{
 		Name:         ProviderKucoin,
 		Urls:         []string{"https://api.kucoin.com"},
 		PollInterval: 2 * time.Second,
+		// the url including a token is requested before every connect
+		Websocket:    "ws-api-spot.kucoin.com",
+		PingDuration: 18 * time.Second,
+		PingType:     websocket.TextMessage,
+		PingMessage:  `{"id":"ping","type":"ping"}`,
 	}
 )
 
@@ -42,8 +57,62 @@ type (
 		Price  string `json:"last"`   // Last price ex.: 0.0025
 		Volume string `json:"vol"`    // Total traded base asset volume ex.: 1000
 	}
+
+	KucoinBulletResponse struct {
+		Data KucoinBulletData `json:"data"`
+	}
+
+	KucoinBulletData struct {
+		Token   string               `json:"token"`
+		Servers []KucoinBulletServer `json:"instanceServers"`
+	}
+
+	KucoinBulletServer struct {
+		Endpoint string `json:"endpoint"` // ex.: "wss://ws-api-spot.kucoin.com/"
+	}
+
+	KucoinSnapshotMsg struct {
+		Type    string             `json:"type"`    // ex.: "message"
+		Subject string             `json:"subject"` // ex.: "trade.snapshot"
+		Data    KucoinSnapshotData `json:"data"`
+	}
+
+	KucoinSnapshotData struct {
+		Data KucoinSnapshot `json:"data"`
+	}
+
+	KucoinSnapshot struct {
+		Symbol string  `json:"symbol"`          // ex.: "BTC-USDT"
+		Price  float64 `json:"lastTradedPrice"` // ex.: 0.0025
+		Volume float64 `json:"vol"`             // ex.: 1000
+		Time   int64   `json:"datetime"`        // ex.: 1548830120082
+	}
+
+	KucoinSubscriptionMsg struct {
+		ID       uint16 `json:"id"`       // ex.: 1
+		Type     string `json:"type"`     // ex.: "subscribe"
+		Topic    string `json:"topic"`    // ex.: "/market/snapshot:BTC-USDT,ETH-USDT"
+		Response bool   `json:"response"` // ex.: true
+	}
 )
 
+func init() {
+	Register(Registration{
+		Name:         ProviderKucoin,
+		Defaults:     kucoinDefaultEndpoints,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewKucoinProvider(ctx, logger, endpoint, pairs...)
+		},
+	})
+}
+
 func NewKucoinProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -56,14 +125,18 @@ func NewKucoinProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
+	if provider.websocket != nil {
+		provider.websocket.SetURLHandler(provider.getWebsocketURL)
+	}
+
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToKucoinSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -108,6 +181,83 @@ func (p *KucoinProvider) Poll() error {
 	return nil
 }
 
+// getWebsocketURL requests a public token and returns the websocket url
+// including it.
+func (p *KucoinProvider) getWebsocketURL() (url.URL, error) {
+	content, err := p.httpPost("/api/v1/bullet-public", nil)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	var response KucoinBulletResponse
+	err = json.Unmarshal(content, &response)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	if response.Data.Token == "" || len(response.Data.Servers) == 0 {
+		return url.URL{}, fmt.Errorf("no websocket token or server")
+	}
+
+	websocketURL, err := url.Parse(response.Data.Servers[0].Endpoint)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	query := websocketURL.Query()
+	query.Set("token", response.Data.Token)
+	query.Set("connectId", strconv.FormatInt(time.Now().UnixNano(), 10))
+	websocketURL.RawQuery = query.Encode()
+
+	return *websocketURL, nil
+}
+
+func (p *KucoinProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	msgs := []interface{}{}
+
+	symbols := p.getSymbols(pairs...)
+	for start := 0; start < len(symbols); start += kucoinMaxSubscriptionSymbols {
+		end := start + kucoinMaxSubscriptionSymbols
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+
+		msgs = append(msgs, KucoinSubscriptionMsg{
+			ID:       uint16(len(msgs) + 1),
+			Type:     "subscribe",
+			Topic:    "/market/snapshot:" + strings.Join(symbols[start:end], ","),
+			Response: true,
+		})
+	}
+
+	return msgs
+}
+
+func (p *KucoinProvider) messageReceived(messageType int, bz []byte) {
+	var msg KucoinSnapshotMsg
+	err := json.Unmarshal(bz, &msg)
+	if err != nil || msg.Type != "message" || msg.Subject != "trade.snapshot" {
+		// welcome, ack and pong messages
+		return
+	}
+
+	snapshot := msg.Data.Data
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.isPair(snapshot.Symbol) {
+		return
+	}
+
+	p.setStreamedTickerPrice(
+		snapshot.Symbol,
+		floatToDec(snapshot.Price),
+		floatToDec(snapshot.Volume),
+		time.UnixMilli(snapshot.Time),
+	)
+}
+
 func (p *KucoinProvider) GetAvailablePairs() (map[string]struct{}, error) {
 	tickers, err := p.getTickers()
 	if err != nil {
@@ -2,21 +2,29 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strconv"
 	"time"
 
 	"price-feeder/oracle/types"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 )
 
 var (
 	_                   Provider = (*OkxProvider)(nil)
 	okxDefaultEndpoints          = Endpoint{
-		Name:         ProviderOkx,
-		Urls:         []string{"https://www.okx.com", "https://aws.okx.com"},
-		PollInterval: 2 * time.Second,
+		Name:          ProviderOkx,
+		Urls:          []string{"https://www.okx.com", "https://aws.okx.com"},
+		PollInterval:  2 * time.Second,
+		Websocket:     "ws.okx.com:8443",
+		WebsocketPath: "/ws/v5/public",
+		PingDuration:  defaultPingDuration,
+		PingType:      websocket.TextMessage,
+		PingMessage:   "ping",
 	}
 )
 
@@ -41,8 +49,40 @@ type (
 		Volume string `json:"vol24h"` // Total traded base asset volume ex.: 1000
 		Time   string `json:"ts"`     // Timestamp ex.: 1675246930699
 	}
+
+	OkxTickersMsg struct {
+		Arg  OkxSubscriptionArg `json:"arg"`
+		Data []OkxTicker        `json:"data"`
+	}
+
+	OkxSubscriptionArg struct {
+		Channel string `json:"channel"` // ex.: tickers
+		InstID  string `json:"instId"`  // ex.: BTC-USDT
+	}
+
+	OkxSubscriptionMsg struct {
+		Op   string               `json:"op"` // ex.: subscribe
+		Args []OkxSubscriptionArg `json:"args"`
+	}
 )
 
+func init() {
+	Register(Registration{
+		Name:         ProviderOkx,
+		Defaults:     okxDefaultEndpoints,
+		Capabilities: Capabilities{Class: SourceClassCEX, Websocket: true},
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewOkxProvider(ctx, logger, endpoint, pairs...)
+		},
+	})
+}
+
 func NewOkxProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -55,14 +95,14 @@ func NewOkxProvider(
 		endpoints,
 		logger,
 		pairs,
-		nil,
-		nil,
+		provider.messageReceived,
+		provider.getSubscriptionMsgs,
 	)
 
 	availablePairs, _ := provider.GetAvailablePairs()
 	provider.setPairs(pairs, availablePairs, currencyPairToOkxSymbol)
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -90,27 +130,74 @@ func (p *OkxProvider) Poll() error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 	for _, ticker := range tickers.Data {
-		if !p.isPair(ticker.Symbol) {
-			continue
-		}
+		p.setTicker(ticker, p.setTickerPrice)
+	}
+	p.logger.Debug().Msg("updated tickers")
+	return nil
+}
+
+// setTicker sets the ticker price with the given setter. It must be called
+// with the lock held.
+func (p *OkxProvider) setTicker(
+	ticker OkxTicker,
+	setTickerPrice func(string, sdk.Dec, sdk.Dec, time.Time),
+) {
+	if !p.isPair(ticker.Symbol) {
+		return
+	}
+
+	timestamp, err := strconv.ParseInt(ticker.Time, 0, 64)
+	if err != nil {
+		p.logger.
+			Err(err).
+			Msg("failed parsing timestamp")
+		return
+	}
+
+	setTickerPrice(
+		ticker.Symbol,
+		strToDec(ticker.Price),
+		strToDec(ticker.Volume),
+		time.UnixMilli(timestamp),
+	)
+}
 
-		timestamp, err := strconv.ParseInt(ticker.Time, 0, 64)
-		if err != nil {
-			p.logger.
-				Err(err).
-				Msg("failed parsing timestamp")
-			continue
+func (p *OkxProvider) getSubscriptionMsgs(pairs ...types.CurrencyPair) []interface{} {
+	symbols := p.getSymbols(pairs...)
+	if len(symbols) == 0 {
+		return []interface{}{}
+	}
+
+	args := make([]OkxSubscriptionArg, len(symbols))
+	for i, symbol := range symbols {
+		args[i] = OkxSubscriptionArg{
+			Channel: "tickers",
+			InstID:  symbol,
 		}
+	}
 
-		p.setTickerPrice(
-			ticker.Symbol,
-			strToDec(ticker.Price),
-			strToDec(ticker.Volume),
-			time.UnixMilli(timestamp),
-		)
+	return []interface{}{
+		OkxSubscriptionMsg{
+			Op:   "subscribe",
+			Args: args,
+		},
+	}
+}
+
+func (p *OkxProvider) messageReceived(messageType int, bz []byte) {
+	var msg OkxTickersMsg
+	err := json.Unmarshal(bz, &msg)
+	if err != nil || msg.Arg.Channel != "tickers" {
+		// subscription responses
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, ticker := range msg.Data {
+		p.setTicker(ticker, p.setStreamedTickerPrice)
 	}
-	p.logger.Debug().Msg("updated tickers")
-	return nil
 }
 
 func (p *OkxProvider) GetAvailablePairs() (map[string]struct{}, error) {
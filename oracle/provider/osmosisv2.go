@@ -63,6 +63,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderOsmosisV2,
+		Defaults: osmosisv2DefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewOsmosisV2Provider(db, ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+}
+
 func NewOsmosisV2Provider(
 	db *sql.DB,
 	ctx context.Context,
@@ -89,7 +106,7 @@ func NewOsmosisV2Provider(
 		return nil, err
 	}
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 
 	return provider, nil
 }
@@ -293,7 +310,9 @@ func (p *OsmosisV2Provider) updateVolumes() {
 
 	for _, height := range missing {
 		volume, err := p.getVolume(height)
-		time.Sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause))
+		if !p.sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause)) {
+			break
+		}
 		if err != nil {
 			p.error(err)
 			continue
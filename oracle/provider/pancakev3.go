@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -71,6 +72,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderPancakeV3Bsc,
+		Defaults: PancakeV3BscDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewPancakeProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewPancakeProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -94,7 +112,7 @@ func NewPancakeProvider(
 
 	provider.init()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
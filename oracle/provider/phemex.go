@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"math"
 	"time"
@@ -64,6 +65,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderPhemex,
+		Defaults: phemexDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewPhemexProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewPhemexProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -119,7 +137,7 @@ func NewPhemexProvider(
 		len(provider.getAllPairs())*1700+2000,
 	) * time.Millisecond
 
-	go startPolling(provider, interval, logger)
+	provider.startPolling(provider, interval, logger)
 	return provider, nil
 }
 
@@ -140,7 +158,10 @@ func (p *PhemexProvider) getProducts() (PhemexProductsResponse, error) {
 
 func (p *PhemexProvider) Poll() error {
 	for symbol, pair := range p.getAllPairs() {
+		p.wg.Add(1)
 		go func(p *PhemexProvider, symbol string, pair types.CurrencyPair) {
+			defer p.wg.Done()
+
 			content, err := p.httpGet("/md/spot/ticker/24hr?symbol=" + symbol)
 			if err != nil {
 				p.logger.Error().
@@ -8,12 +8,14 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,6 +33,7 @@ import (
 const (
 	defaultTimeout       = 10 * time.Second
 	staleTickersCutoff   = 1 * time.Minute
+	staleStreamCutoff    = 15 * time.Second
 	providerCandlePeriod = 10 * time.Minute
 
 	ProviderAstroportInjective Name = "astroport_injective"
@@ -40,7 +43,6 @@ const (
 	ProviderBinanceUS          Name = "binanceus"
 	ProviderBingx              Name = "bingx"
 	ProviderBitfinex           Name = "bitfinex"
-	ProviderBitforex           Name = "bitforex"
 	ProviderBitget             Name = "bitget"
 	ProviderBitmart            Name = "bitmart"
 	ProviderBitstamp           Name = "bitstamp"
@@ -56,6 +58,7 @@ const (
 	ProviderFin                Name = "fin"
 	ProviderFinV2              Name = "finv2"
 	ProviderGate               Name = "gate"
+	ProviderGenericHTTP        Name = "generic_http"
 	ProviderHelix              Name = "helix"
 	ProviderHitBtc             Name = "hitbtc"
 	ProviderHuobi              Name = "huobi"
@@ -75,7 +78,6 @@ const (
 	ProviderPoloniex           Name = "poloniex"
 	ProviderPyth               Name = "pyth"
 	ProviderShade              Name = "shade"
-	ProviderStride             Name = "stride"
 	ProviderUniswapV3          Name = "uniswapv3"
 	ProviderUnstake            Name = "unstake"
 	ProviderVelodromeV2        Name = "velodromev2"
@@ -104,12 +106,17 @@ type (
 		SubscribeCurrencyPairs(...types.CurrencyPair) error
 		CurrencyPairToProviderPair(types.CurrencyPair) string
 		// ProviderPairToCurrencyPair(string) types.CurrencyPair
+
+		// Wait blocks until all background routines of the provider have
+		// stopped, which happens once its context is cancelled.
+		Wait()
 	}
 
 	CurrencyPairToProviderSymbol func(types.CurrencyPair) string
 
 	provider struct {
 		ctx       context.Context
+		wg        sync.WaitGroup
 		name      string
 		endpoints Endpoint
 		httpBase  string
@@ -118,9 +125,13 @@ type (
 		mtx       sync.RWMutex
 		pairs     map[string]types.CurrencyPair
 		inverse   map[string]types.CurrencyPair
+		available map[string]struct{}
+		toSymbol  CurrencyPairToProviderSymbol
 		tickers   map[string]types.TickerPrice
 		contracts map[string]string
 		websocket *WebsocketController
+		streamed  map[string]time.Time
+		throttle  time.Duration
 		db        *sql.DB
 		volumes   volume.VolumeHandler
 		height    uint64
@@ -131,6 +142,12 @@ type (
 		Poll() error
 	}
 
+	// ThrottledProvider is implemented by providers polling in the
+	// background, whose polls can be spread out.
+	ThrottledProvider interface {
+		Throttle(time.Duration)
+	}
+
 	// Name name of an oracle provider. Usually it is an exchange
 	// but this can be any provider name that can give token prices
 	// examples.: "binance", "osmosis", "kraken".
@@ -156,6 +173,8 @@ type (
 		VolumePause       int
 		Decimals          map[string]int
 		Periods           map[string]int
+		RateLimit         RateLimit
+		GenericHTTP       *GenericHTTP
 	}
 
 	EvmLog struct {
@@ -200,7 +219,8 @@ func (p *provider) Init(
 
 	p.contracts = endpoints.ContractAddresses
 
-	if p.endpoints.Websocket != "" {
+	// the websocket is started with the poll loop, once the pairs are set
+	if p.endpoints.Websocket != "" && websocketMessageHandler != nil {
 		websocketUrl := url.URL{
 			Scheme: "wss",
 			Host:   p.endpoints.Websocket,
@@ -218,7 +238,7 @@ func (p *provider) Init(
 			p.endpoints.PingMessage,
 			p.logger,
 		)
-		go p.websocket.Start()
+		p.streamed = map[string]time.Time{}
 	}
 
 	// set contract<>symbol mapping
@@ -297,21 +317,63 @@ func (p *provider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]type
 	return tickers, nil
 }
 
+// Wait blocks until the poll loop, the websocket and all pending volume
+// writes of the provider have stopped.
+func (p *provider) Wait() {
+	p.wg.Wait()
+
+	if p.websocket != nil {
+		p.websocket.Wait()
+	}
+
+	p.volumes.Wait()
+}
+
+// sleep pauses the current routine for the given duration. It returns false,
+// if the provider got stopped in the meantime.
+func (p *provider) sleep(duration time.Duration) bool {
+	select {
+	case <-p.ctx.Done():
+		return false
+	case <-time.After(duration):
+		return true
+	}
+}
+
 func (p *provider) SubscribeCurrencyPairs(pairs ...types.CurrencyPair) error {
+	// the subscribe handlers read the pairs, so the lock must be released
+	// before subscribing
 	p.mtx.Lock()
-	defer p.mtx.Unlock()
 	newPairs := p.addPairs(pairs...)
-	if p.endpoints.Websocket == "" {
+	p.mtx.Unlock()
+
+	if p.websocket == nil || len(newPairs) == 0 {
 		return nil
 	}
 	return p.websocket.AddPairs(newPairs)
 }
 
+// addPairs stores the pairs not known yet under their provider symbols, the
+// same way as setPairs, and returns them. It must be called with the lock
+// held.
 func (p *provider) addPairs(pairs ...types.CurrencyPair) []types.CurrencyPair {
+	if p.pairs == nil {
+		p.pairs = map[string]types.CurrencyPair{}
+		p.inverse = map[string]types.CurrencyPair{}
+	}
+
+	known := map[string]struct{}{}
+	for _, pair := range p.getAllPairs() {
+		known[pair.String()] = struct{}{}
+	}
+
 	newPairs := []types.CurrencyPair{}
 	for _, pair := range pairs {
-		_, ok := p.pairs[pair.String()]
-		if !ok {
+		if _, found := known[pair.String()]; found {
+			continue
+		}
+		if p.addPair(pair) {
+			known[pair.String()] = struct{}{}
 			newPairs = append(newPairs, pair)
 		}
 	}
@@ -591,7 +653,12 @@ func (p *provider) httpRequest(path string, method string, body []byte, headers
 }
 
 func (p *provider) makeHttpRequest(url string, method string, body []byte, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	ctx := p.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -600,13 +667,26 @@ func (p *provider) makeHttpRequest(url string, method string, body []byte, heade
 		req.Header.Set(key, value)
 	}
 
+	// all providers requesting the same host share its limiter
+	limiter := getHostLimiter(url, p.endpoints.RateLimit)
+	err = limiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := p.http.Do(req)
 	if err != nil {
+		if ctx.Err() == nil {
+			limiter.failure(time.Now())
+		}
 		p.logger.Warn().
 			Err(err).
 			Msg("http request failed")
 		return nil, err
 	}
+	defer res.Body.Close()
+
+	limiter.update(res, time.Now())
 
 	if res.StatusCode != 200 {
 		p.logger.Warn().
@@ -620,6 +700,7 @@ func (p *provider) makeHttpRequest(url string, method string, body []byte, heade
 				Str("url", url).
 				Str("retry_after", res.Header.Get("Retry-After")).
 				Msg("http ratelimited")
+			return nil, fmt.Errorf("%w: http request returned status %d", ErrRateLimited, res.StatusCode)
 		}
 		return nil, fmt.Errorf("http request returned invalid status")
 	}
@@ -634,120 +715,15 @@ func (p *provider) makeHttpRequest(url string, method string, body []byte, heade
 	return content, nil
 }
 
+// SetDefaults completes the endpoint with the defaults the provider has
+// been registered with.
 func (e *Endpoint) SetDefaults() {
-	var defaults Endpoint
-	switch e.Name {
-	case ProviderAstroportInjective:
-		defaults = astroportInjectiveDefaultEndpoints
-	case ProviderAstroportNeutron:
-		defaults = astroportNeutronDefaultEndpoints
-	case ProviderAstroportTerra2:
-		defaults = astroportTerra2DefaultEndpoints
-	case ProviderBinance:
-		defaults = binanceDefaultEndpoints
-	case ProviderBitfinex:
-		defaults = bitfinexDefaultEndpoints
-	case ProviderBinanceUS:
-		defaults = binanceUSDefaultEndpoints
-	case ProviderBingx:
-		defaults = bingxDefaultEndpoints
-	case ProviderBitget:
-		defaults = bitgetDefaultEndpoints
-	case ProviderBitmart:
-		defaults = bitmartDefaultEndpoints
-	case ProviderBitstamp:
-		defaults = bitstampDefaultEndpoints
-	case ProviderBkex:
-		defaults = bkexDefaultEndpoints
-	case ProviderBybit:
-		defaults = bybitDefaultEndpoints
-	case ProviderCamelotV2:
-		defaults = camelotV2DefaultEndpoints
-	case ProviderCamelotV3:
-		defaults = camelotV3DefaultEndpoints
-	case ProviderCoinbase:
-		defaults = coinbaseDefaultEndpoints
-	case ProviderCoinex:
-		defaults = coinexDefaultEndpoints
-	case ProviderCrypto:
-		defaults = cryptoDefaultEndpoints
-	case ProviderCurve:
-		defaults = curveDefaultEndpoints
-	case ProviderDexter:
-		defaults = dexterDefaultEndpoints
-	case ProviderFin:
-		defaults = finDefaultEndpoints
-	case ProviderFinV2:
-		defaults = finV2DefaultEndpoints
-	case ProviderGate:
-		defaults = gateDefaultEndpoints
-	case ProviderHelix:
-		defaults = helixDefaultEndpoints
-	case ProviderHitBtc:
-		defaults = hitbtcDefaultEndpoints
-	case ProviderIdxOsmosis:
-		defaults = idxOsmosisDefaultEndpoints
-	case ProviderHuobi:
-		defaults = huobiDefaultEndpoints
-	case ProviderKraken:
-		defaults = krakenDefaultEndpoints
-	case ProviderKucoin:
-		defaults = kucoinDefaultEndpoints
-	case ProviderLbank:
-		defaults = lbankDefaultEndpoints
-	case ProviderMexc:
-		defaults = mexcDefaultEndpoints
-	case ProviderMaya:
-		defaults = mayaDefaultEndpoints
-	case ProviderMock:
-		defaults = mockDefaultEndpoints
-	case ProviderOkx:
-		defaults = okxDefaultEndpoints
-	case ProviderOsmosis:
-		defaults = osmosisDefaultEndpoints
-	case ProviderOsmosisV2:
-		defaults = osmosisv2DefaultEndpoints
-	case ProviderPancakeV3Bsc:
-		defaults = PancakeV3BscDefaultEndpoints
-	case ProviderPhemex:
-		defaults = phemexDefaultEndpoints
-	case ProviderPionex:
-		defaults = pionexDefaultEndpoints
-	case ProviderPoloniex:
-		defaults = poloniexDefaultEndpoints
-	case ProviderPyth:
-		defaults = pythDefaultEndpoints
-	case ProviderShade:
-		defaults = shadeDefaultEndpoints
-	case ProviderUniswapV3:
-		defaults = uniswapv3DefaultEndpoints
-	case ProviderUnstake:
-		defaults = unstakeDefaultEndpoints
-	case ProviderVelodromeV2:
-		defaults = velodromev2DefaultEndpoints
-	case ProviderWhitewhaleCmdx:
-		defaults = whitewhaleCmdxDefaultEndpoints
-	case ProviderWhitewhaleHuahua:
-		defaults = whitewhaleHuahuaDefaultEndpoints
-	case ProviderWhitewhaleInj:
-		defaults = whitewhaleInjDefaultEndpoints
-	case ProviderWhitewhaleJuno:
-		defaults = whitewhaleJunoDefaultEndpoints
-	case ProviderWhitewhaleLunc:
-		defaults = whitewhaleLuncDefaultEndpoints
-	case ProviderWhitewhaleLuna:
-		defaults = whitewhaleLunaDefaultEndpoints
-	case ProviderWhitewhaleSei:
-		defaults = whitewhaleSeiDefaultEndpoints
-	case ProviderWhitewhaleWhale:
-		defaults = whitewhaleWhaleDefaultEndpoints
-	case ProviderXt:
-		defaults = xtDefaultEndpoints
-	case ProviderZero:
-		defaults = zeroDefaultEndpoints
-	default:
+	registration, found := Lookup(e.Name)
+	if !found {
 		return
 	}
+
+	defaults := registration.Defaults
 	if e.Urls == nil {
 		urls := defaults.Urls
 		rand.Seed(time.Now().UnixNano())
@@ -795,17 +771,94 @@ func (e *Endpoint) SetDefaults() {
 	if e.VolumePause <= 0 {
 		e.VolumePause = defaults.VolumePause
 	}
+
+	if e.RateLimit.Rate <= 0 {
+		e.RateLimit.Rate = defaults.RateLimit.Rate
+	}
+	if e.RateLimit.Burst <= 0 {
+		e.RateLimit.Burst = defaults.RateLimit.Burst
+	}
 }
 
-func startPolling(p PollingProvider, interval time.Duration, logger zerolog.Logger) {
-	logger.Debug().Dur("interval", interval).Msg("starting poll loop")
-	for {
-		err := p.Poll()
-		if err != nil {
-			logger.Error().Err(err).Msg("failed to poll")
-		}
-		time.Sleep(interval)
+// startPolling calls Poll of the given provider every interval, until the
+// provider's context is cancelled. If the provider streams its tickers, the
+// websocket is started and Poll is only called while the stream is stale.
+func (p *provider) startPolling(
+	poller PollingProvider,
+	interval time.Duration,
+	logger zerolog.Logger,
+) {
+	if p.websocket != nil {
+		p.websocket.Start()
 	}
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		logger.Debug().Dur("interval", interval).Msg("starting poll loop")
+		streaming := false
+		var lastPoll time.Time
+		for {
+			if p.isStreaming(time.Now()) {
+				if !streaming {
+					logger.Info().Msg("websocket streaming, rest polling paused")
+				}
+				streaming = true
+
+				if !p.sleep(interval) {
+					logger.Debug().Msg("stopping poll loop")
+					return
+				}
+				continue
+			}
+
+			if streaming {
+				logger.Warn().Msg("websocket stream stale, falling back to rest polling")
+			}
+			streaming = false
+
+			if p.isThrottled(lastPoll, time.Now()) {
+				if !p.sleep(interval) {
+					logger.Debug().Msg("stopping poll loop")
+					return
+				}
+				continue
+			}
+
+			lastPoll = time.Now()
+			err := poller.Poll()
+			if errors.Is(err, ErrRateLimited) {
+				logger.Warn().Err(err).Msg("poll skipped")
+			} else if err != nil && p.ctx.Err() == nil {
+				logger.Error().Err(err).Msg("failed to poll")
+			}
+
+			if !p.sleep(interval) {
+				logger.Debug().Msg("stopping poll loop")
+				return
+			}
+		}
+	}()
+}
+
+// Throttle sets the minimum interval between the polls of the provider, e.g.
+// while it is quarantined. Zero restores the configured poll interval.
+func (p *provider) Throttle(interval time.Duration) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.throttle = interval
+}
+
+// isThrottled returns whether the provider has been polled within its
+// throttled interval.
+func (p *provider) isThrottled(lastPoll time.Time, now time.Time) bool {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	return p.throttle > 0 && now.Sub(lastPoll) < p.throttle
 }
 
 func (p *provider) setPairs(
@@ -815,50 +868,58 @@ func (p *provider) setPairs(
 ) error {
 	p.pairs = map[string]types.CurrencyPair{}
 	p.inverse = map[string]types.CurrencyPair{}
-
-	if toProviderSymbol == nil {
-		toProviderSymbol = func(pair types.CurrencyPair) string {
-			return pair.String()
-		}
-	}
+	p.available = availablePairs
+	p.toSymbol = toProviderSymbol
 
 	if availablePairs == nil {
 		p.logger.Warn().Msg("available pairs not provided")
+	}
 
-		for _, pair := range pairs {
-			// If availablePairs is nil, GetAvailablePairs() is probably
-			// not implemented for this provider
-			inverted := pair.Swap()
+	for _, pair := range pairs {
+		p.addPair(pair)
+	}
 
-			p.pairs[toProviderSymbol(pair)] = pair
-			p.inverse[toProviderSymbol(inverted)] = pair
+	return nil
+}
+
+// addPair stores the pair under its provider symbol, or under the symbol of
+// the inverted pair if only that one is available. It returns false if the
+// provider supports neither.
+func (p *provider) addPair(pair types.CurrencyPair) bool {
+	toProviderSymbol := p.toSymbol
+	if toProviderSymbol == nil {
+		toProviderSymbol = func(pair types.CurrencyPair) string {
+			return pair.String()
 		}
-		return nil
 	}
 
-	for _, pair := range pairs {
-		inverted := pair.Swap()
+	inverted := pair.Swap()
 
-		providerSymbol := toProviderSymbol(inverted)
-		_, found := availablePairs[providerSymbol]
-		if found {
-			p.inverse[providerSymbol] = pair
-			continue
-		}
+	if p.available == nil {
+		// If availablePairs is nil, GetAvailablePairs() is probably
+		// not implemented for this provider
+		p.pairs[toProviderSymbol(pair)] = pair
+		p.inverse[toProviderSymbol(inverted)] = pair
+		return true
+	}
 
-		symbol := pair.String()
-		providerSymbol = toProviderSymbol(pair)
-		_, found = availablePairs[providerSymbol]
-		if found {
-			p.pairs[providerSymbol] = pair
-			continue
-		}
+	providerSymbol := toProviderSymbol(inverted)
+	_, found := p.available[providerSymbol]
+	if found {
+		p.inverse[providerSymbol] = pair
+		return true
+	}
 
-		p.logger.Error().
-			Msgf("%s is not supported by this provider", symbol)
+	providerSymbol = toProviderSymbol(pair)
+	_, found = p.available[providerSymbol]
+	if found {
+		p.pairs[providerSymbol] = pair
+		return true
 	}
 
-	return nil
+	p.logger.Error().
+		Msgf("%s is not supported by this provider", pair.String())
+	return false
 }
 
 func (p *provider) setTickerPrice(
@@ -924,6 +985,75 @@ func (p *provider) setTickerPrice(
 	)
 }
 
+// setStreamedTickerPrice sets the ticker price received from the websocket
+// and keeps track of the freshness of the stream. It must be called with the
+// lock held.
+func (p *provider) setStreamedTickerPrice(
+	symbol string,
+	price sdk.Dec,
+	volume sdk.Dec,
+	timestamp time.Time,
+) {
+	p.setTickerPrice(symbol, price, volume, timestamp)
+
+	pair, found := p.inverse[symbol]
+	if !found {
+		pair, found = p.pairs[symbol]
+	}
+	if found {
+		p.streamed[pair.String()] = time.Now()
+	}
+
+	telemetryWebsocketMessage(p.endpoints.Name, MessageTypeTicker)
+}
+
+// isStreaming returns whether the websocket delivered the tickers of all
+// pairs within the staleStreamCutoff.
+func (p *provider) isStreaming(now time.Time) bool {
+	if p.websocket == nil {
+		return false
+	}
+
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	pairs := p.getAllPairs()
+	if len(pairs) == 0 {
+		return false
+	}
+
+	for _, pair := range pairs {
+		streamed, found := p.streamed[pair.String()]
+		if !found || now.Sub(streamed) > staleStreamCutoff {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getSymbols returns the sorted provider symbols of the pairs, as set by
+// setPairs, to subscribe to.
+func (p *provider) getSymbols(pairs ...types.CurrencyPair) []string {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	wanted := map[string]struct{}{}
+	for _, pair := range pairs {
+		wanted[pair.String()] = struct{}{}
+	}
+
+	symbols := []string{}
+	for symbol, pair := range p.getAllPairs() {
+		if _, found := wanted[pair.String()]; found {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
 func (p *provider) isPair(symbol string) bool {
 	if _, found := p.pairs[symbol]; found {
 		return true
@@ -1,12 +1,15 @@
 package provider
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"price-feeder/oracle/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +88,58 @@ func TestStrToDec(t *testing.T) {
 		require.Equal(t, sdk.Dec{}, dec)
 	})
 }
+
+type testPoller struct {
+	polls atomic.Int32
+}
+
+func (p *testPoller) Poll() error {
+	p.polls.Add(1)
+	return nil
+}
+
+func TestProvider_startPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &provider{ctx: ctx}
+	poller := &testPoller{}
+
+	p.startPolling(poller, time.Millisecond, zerolog.Nop())
+	require.Eventually(t, func() bool {
+		return poller.polls.Load() > 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	p.Wait()
+
+	polls := poller.polls.Load()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, polls, poller.polls.Load())
+}
+
+func TestProvider_Throttle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &provider{ctx: ctx}
+	p.Throttle(time.Hour)
+	poller := &testPoller{}
+
+	// the first poll is never throttled
+	p.startPolling(poller, time.Millisecond, zerolog.Nop())
+	require.Eventually(t, func() bool {
+		return poller.polls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(1), poller.polls.Load())
+
+	p.Throttle(0)
+	require.Eventually(t, func() bool {
+		return poller.polls.Load() > 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	p.Wait()
+}
@@ -0,0 +1,342 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// hostRequestRate and hostRequestBurst limit the requests to hosts of
+	// endpoints without a rate limit of their own.
+	hostRequestRate  = 10 // requests per second
+	hostRequestBurst = 20
+
+	// rateLimitBackoff is the minimum pause after a 429 response without
+	// Retry-After header, banBackoff the one after a 418 response, which
+	// Binance uses for IP bans.
+	rateLimitBackoff = 30 * time.Second
+	banBackoff       = 5 * time.Minute
+
+	// failureBackoff doubles on every consecutive failure of a host, up to
+	// maxFailureBackoff.
+	failureBackoff    = 1 * time.Second
+	maxFailureBackoff = 5 * time.Minute
+
+	// weightThreshold is the share of a weight limit at which requests are
+	// paused until the weight window ends.
+	weightThreshold = 0.9
+)
+
+// ErrRateLimited is returned for requests to hosts that are paused after
+// rate limiting responses or repeated failures.
+var ErrRateLimited = errors.New("rate limited")
+
+var (
+	// weightHeaders are the headers exchanges report the request weight used
+	// in the current window with.
+	weightHeaders = []weightHeader{
+		// Binance
+		{Header: "X-Mbx-Used-Weight-1m", Limit: 6000, Window: time.Minute},
+	}
+
+	// quotaHeaders are the headers exchanges report the requests remaining
+	// in the current window with.
+	quotaHeaders = []quotaHeader{
+		// Kucoin, the reset is in milliseconds from now
+		{
+			Limit:     "Gw-Ratelimit-Limit",
+			Remaining: "Gw-Ratelimit-Remaining",
+			Reset:     "Gw-Ratelimit-Reset",
+			ResetAt: func(reset int64, now time.Time) time.Time {
+				return now.Add(time.Duration(reset) * time.Millisecond)
+			},
+		},
+		// Bybit, the reset is a timestamp in milliseconds
+		{
+			Limit:     "X-Bapi-Limit",
+			Remaining: "X-Bapi-Limit-Status",
+			Reset:     "X-Bapi-Limit-Reset-Timestamp",
+			ResetAt: func(reset int64, _ time.Time) time.Time {
+				return time.UnixMilli(reset)
+			},
+		},
+	}
+
+	hostLimiters    = map[string]*hostLimiter{}
+	hostLimitersMtx sync.Mutex
+)
+
+type (
+	// RateLimit defines how many requests per second are sent to each host
+	// of an endpoint, with bursts of up to Burst requests. Zero values use
+	// the defaults of the provider or, if it has none, of all hosts.
+	RateLimit struct {
+		Rate  float64
+		Burst int
+	}
+
+	weightHeader struct {
+		Header string
+		Limit  float64
+		Window time.Duration
+	}
+
+	quotaHeader struct {
+		Limit     string
+		Remaining string
+		Reset     string
+		ResetAt   func(reset int64, now time.Time) time.Time
+	}
+
+	// hostLimiter throttles the requests to a host with a token bucket and
+	// pauses them after rate limiting responses or failures. It is shared by
+	// all providers requesting the host.
+	hostLimiter struct {
+		mtx      sync.Mutex
+		host     string
+		rate     float64
+		burst    float64
+		tokens   float64
+		updated  time.Time
+		paused   time.Time
+		failures int
+	}
+)
+
+// getHostLimiter returns the limiter of the host of the url. Providers
+// sharing a host with different rate limits share the lowest of them.
+func getHostLimiter(rawUrl string, rateLimit RateLimit) *hostLimiter {
+	host := rawUrl
+	parsed, err := url.Parse(rawUrl)
+	if err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	rate, burst := rateLimit.Rate, float64(rateLimit.Burst)
+	if rate <= 0 {
+		rate = hostRequestRate
+	}
+	if burst <= 0 {
+		burst = hostRequestBurst
+	}
+
+	hostLimitersMtx.Lock()
+	defer hostLimitersMtx.Unlock()
+
+	limiter, found := hostLimiters[host]
+	if !found {
+		limiter = newHostLimiter(host, rate, burst, time.Now())
+		hostLimiters[host] = limiter
+		return limiter
+	}
+
+	limiter.mtx.Lock()
+	defer limiter.mtx.Unlock()
+
+	limiter.rate = math.Min(limiter.rate, rate)
+	limiter.burst = math.Min(limiter.burst, burst)
+	limiter.tokens = math.Min(limiter.tokens, limiter.burst)
+
+	return limiter
+}
+
+func newHostLimiter(host string, rate, burst float64, now time.Time) *hostLimiter {
+	return &hostLimiter{
+		host:    host,
+		rate:    rate,
+		burst:   burst,
+		tokens:  burst,
+		updated: now,
+	}
+}
+
+// wait blocks until a request to the host is allowed. It fails immediately
+// if the host is paused, so alternate endpoints can be tried.
+func (l *hostLimiter) wait(ctx context.Context) error {
+	delay, err := l.reserve(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket and returns how long to wait for
+// it to become available.
+func (l *hostLimiter) reserve(now time.Time) (time.Duration, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if now.Before(l.paused) {
+		return 0, fmt.Errorf(
+			"%w: %s paused until %s",
+			ErrRateLimited, l.host, l.paused.Format(time.RFC3339),
+		)
+	}
+
+	elapsed := now.Sub(l.updated).Seconds()
+	if elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+		l.updated = now
+	}
+
+	l.tokens--
+	telemetryHostTokens(l.host, l.tokens)
+
+	if l.tokens >= 0 {
+		return 0, nil
+	}
+
+	seconds := -l.tokens / l.rate
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// update pauses the host according to the response.
+func (l *hostLimiter) update(res *http.Response, now time.Time) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode == http.StatusTeapot:
+		l.failures++
+		telemetryHostRateLimited(l.host, res.StatusCode)
+
+		until, found := parseRetryAfter(res.Header.Get("Retry-After"), now)
+		if !found {
+			backoff := rateLimitBackoff
+			if res.StatusCode == http.StatusTeapot {
+				backoff = banBackoff
+			}
+			until = now.Add(maxDuration(backoff, l.backoff()))
+		}
+		l.pause(until)
+
+	case res.StatusCode >= 500:
+		l.failures++
+		l.pause(now.Add(l.backoff()))
+
+	default:
+		l.failures = 0
+
+		for _, weight := range weightHeaders {
+			used, err := strconv.ParseFloat(res.Header.Get(weight.Header), 64)
+			if err != nil || used < weight.Limit*weightThreshold {
+				continue
+			}
+			l.pause(now.Truncate(weight.Window).Add(weight.Window))
+		}
+
+		for _, quota := range quotaHeaders {
+			if until, found := quota.exhausted(res.Header, now); found {
+				l.pause(until)
+			}
+		}
+	}
+
+	telemetryHostPaused(l.host, now.Before(l.paused))
+}
+
+// exhausted returns until when the host must be paused, if the remaining
+// requests reported by the headers are below the weight threshold.
+func (q quotaHeader) exhausted(header http.Header, now time.Time) (time.Time, bool) {
+	limit, err := strconv.ParseFloat(header.Get(q.Limit), 64)
+	if err != nil || limit <= 0 {
+		return time.Time{}, false
+	}
+
+	remaining, err := strconv.ParseFloat(header.Get(q.Remaining), 64)
+	if err != nil || remaining > limit*(1-weightThreshold) {
+		return time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(header.Get(q.Reset), 10, 64)
+	if err != nil || reset < 0 {
+		return time.Time{}, false
+	}
+
+	return q.ResetAt(reset, now), true
+}
+
+// failure pauses the host after a failed request.
+func (l *hostLimiter) failure(now time.Time) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.failures++
+	l.pause(now.Add(l.backoff()))
+
+	telemetryHostPaused(l.host, true)
+}
+
+// backoff returns the pause after the current number of consecutive
+// failures.
+func (l *hostLimiter) backoff() time.Duration {
+	if l.failures < 1 {
+		return 0
+	}
+
+	backoff := failureBackoff
+	for i := 1; i < l.failures && backoff < maxFailureBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxFailureBackoff {
+		return maxFailureBackoff
+	}
+
+	return backoff
+}
+
+// pause extends the pause of the host, it never shortens it.
+func (l *hostLimiter) pause(until time.Time) {
+	if until.After(l.paused) {
+		l.paused = until
+	}
+}
+
+// parseRetryAfter returns the time of a Retry-After header in seconds or as
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
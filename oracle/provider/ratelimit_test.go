@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLimiter_Reserve(t *testing.T) {
+	now := time.Now()
+	limiter := newHostLimiter("foo", hostRequestRate, hostRequestBurst, now)
+
+	for i := 0; i < hostRequestBurst; i++ {
+		delay, err := limiter.reserve(now)
+		require.NoError(t, err)
+		require.Zero(t, delay)
+	}
+
+	delay, err := limiter.reserve(now)
+	require.NoError(t, err)
+	require.Equal(t, time.Second/hostRequestRate, delay)
+
+	// the reserved token is paid back first
+	delay, err = limiter.reserve(now.Add(time.Second / hostRequestRate))
+	require.NoError(t, err)
+	require.Equal(t, time.Second/hostRequestRate, delay)
+
+	delay, err = limiter.reserve(now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Zero(t, delay)
+	require.Equal(t, float64(hostRequestBurst-1), limiter.tokens)
+
+	limiter.pause(now.Add(2 * time.Minute))
+	_, err = limiter.reserve(now.Add(time.Minute))
+	require.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestHostLimiter_Update(t *testing.T) {
+	now := time.Now()
+	response := func(code int, headers map[string]string) *http.Response {
+		res := &http.Response{StatusCode: code, Header: http.Header{}}
+		for key, value := range headers {
+			res.Header.Set(key, value)
+		}
+		return res
+	}
+
+	limiter := &hostLimiter{host: "foo"}
+	limiter.update(response(429, map[string]string{"Retry-After": "60"}), now)
+	require.Equal(t, now.Add(time.Minute), limiter.paused)
+
+	// pauses are never shortened
+	limiter.update(response(429, map[string]string{"Retry-After": "1"}), now)
+	require.Equal(t, now.Add(time.Minute), limiter.paused)
+
+	limiter = &hostLimiter{host: "foo"}
+	limiter.update(response(429, nil), now)
+	require.Equal(t, now.Add(rateLimitBackoff), limiter.paused)
+
+	limiter = &hostLimiter{host: "foo"}
+	limiter.update(response(418, nil), now)
+	require.Equal(t, now.Add(banBackoff), limiter.paused)
+
+	// consecutive failures back off exponentially
+	limiter = &hostLimiter{host: "foo"}
+	for i, backoff := range []time.Duration{1, 2, 4, 8} {
+		limiter.update(response(502, nil), now.Add(time.Duration(i)*time.Hour))
+		require.Equal(t, now.Add(time.Duration(i)*time.Hour+backoff*time.Second), limiter.paused)
+	}
+
+	limiter.failure(now.Add(4 * time.Hour))
+	require.Equal(t, now.Add(4*time.Hour+16*time.Second), limiter.paused)
+
+	limiter.failures = 100
+	require.Equal(t, maxFailureBackoff, limiter.backoff())
+
+	// successful responses reset the failures
+	limiter.update(response(200, nil), now.Add(5*time.Hour))
+	require.Zero(t, limiter.failures)
+	require.Equal(t, now.Add(4*time.Hour+16*time.Second), limiter.paused)
+
+	limiter = &hostLimiter{host: "foo"}
+	limiter.update(response(200, map[string]string{"X-MBX-USED-WEIGHT-1M": "1000"}), now)
+	require.True(t, limiter.paused.IsZero())
+
+	limiter.update(response(200, map[string]string{"X-MBX-USED-WEIGHT-1M": "5900"}), now)
+	require.Equal(t, now.Truncate(time.Minute).Add(time.Minute), limiter.paused)
+
+	// kucoin
+	kucoin := func(remaining string) *http.Response {
+		return response(200, map[string]string{
+			"gw-ratelimit-limit":     "2000",
+			"gw-ratelimit-remaining": remaining,
+			"gw-ratelimit-reset":     "15000",
+		})
+	}
+
+	limiter = &hostLimiter{host: "foo"}
+	limiter.update(kucoin("1000"), now)
+	require.True(t, limiter.paused.IsZero())
+
+	limiter.update(kucoin("150"), now)
+	require.Equal(t, now.Add(15*time.Second), limiter.paused)
+
+	// bybit
+	reset := now.Add(time.Second).Truncate(time.Millisecond)
+	limiter = &hostLimiter{host: "foo"}
+	limiter.update(response(200, map[string]string{
+		"X-Bapi-Limit":                 "20",
+		"X-Bapi-Limit-Status":          "1",
+		"X-Bapi-Limit-Reset-Timestamp": fmt.Sprint(reset.UnixMilli()),
+	}), now)
+	require.True(t, reset.Equal(limiter.paused))
+}
+
+func TestGetHostLimiter(t *testing.T) {
+	limiter := getHostLimiter("https://ratelimit.test/api", RateLimit{})
+	require.Equal(t, float64(hostRequestRate), limiter.rate)
+	require.Equal(t, float64(hostRequestBurst), limiter.burst)
+
+	// the lowest rate limit of all providers sharing a host applies
+	require.Same(t, limiter, getHostLimiter("https://ratelimit.test/other", RateLimit{Rate: 1, Burst: 5}))
+	getHostLimiter("https://ratelimit.test", RateLimit{Rate: 2, Burst: 10})
+	require.Equal(t, float64(1), limiter.rate)
+	require.Equal(t, float64(5), limiter.burst)
+	require.Equal(t, float64(5), limiter.tokens)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	until, found := parseRetryAfter("120", now)
+	require.True(t, found)
+	require.Equal(t, now.Add(2*time.Minute), until)
+
+	until, found = parseRetryAfter("Tue, 02 Jan 2024 03:10:00 GMT", now)
+	require.True(t, found)
+	require.True(t, until.Equal(time.Date(2024, 1, 2, 3, 10, 0, 0, time.UTC)))
+
+	for _, value := range []string{"", "-1", "soon"} {
+		_, found = parseRetryAfter(value, now)
+		require.False(t, found, value)
+	}
+}
+
+func TestMakeHttpRequest_SharedHostLimiter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Set("Retry-After", "60")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	newProvider := func() *provider {
+		return &provider{
+			ctx:       context.Background(),
+			http:      newDefaultHTTPClient(),
+			logger:    zerolog.Nop(),
+			endpoints: Endpoint{Urls: []string{server.URL}},
+			httpBase:  server.URL,
+		}
+	}
+
+	_, err := newProvider().httpGet("/ticker")
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// other providers requesting the same host are paused as well
+	_, err = newProvider().httpGet("/other")
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
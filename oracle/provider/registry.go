@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"price-feeder/oracle/types"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Constructor creates a provider for the given pairs. The volume
+	// database is only passed on to providers storing volumes.
+	Constructor func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error)
+
+	// Capabilities describes the source class of a provider and what it
+	// relies on: a websocket stream, the volume database or contract
+	// addresses to query prices.
+	Capabilities struct {
+		Class     string // SourceClassCEX or SourceClassDEX
+		Websocket bool
+		Volume    bool
+		Contracts bool
+	}
+
+	// Registration holds everything needed to configure and create a
+	// provider.
+	Registration struct {
+		Name         Name
+		Defaults     Endpoint
+		Constructor  Constructor
+		Capabilities Capabilities
+	}
+)
+
+// registry holds all providers, which register themselves on init.
+var registry = map[Name]Registration{}
+
+// Register adds a provider to the registry. It panics if the registration
+// is incomplete or the name is registered twice, as both are programming
+// errors.
+func Register(registration Registration) {
+	if registration.Name == "" || registration.Constructor == nil {
+		panic("provider registration requires a name and a constructor")
+	}
+
+	if !IsSourceClass(registration.Capabilities.Class) {
+		panic(fmt.Sprintf("provider %s has no valid source class", registration.Name))
+	}
+
+	if _, found := registry[registration.Name]; found {
+		panic(fmt.Sprintf("provider %s registered twice", registration.Name))
+	}
+
+	registration.Defaults.Name = registration.Name
+	registry[registration.Name] = registration
+}
+
+// Lookup returns the registration of the provider.
+func Lookup(name Name) (Registration, bool) {
+	registration, found := registry[name]
+	return registration, found
+}
+
+// IsRegistered returns whether the provider is registered.
+func IsRegistered(name Name) bool {
+	_, found := registry[name]
+	return found
+}
+
+// Registered returns the names of all registered providers, sorted.
+func Registered() []Name {
+	names := make([]Name, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+// New creates the provider of the given name with the endpoint, which is
+// completed with the provider's defaults. Unregistered names are created as
+// generic http providers, if the endpoint declares them.
+func New(
+	db *sql.DB,
+	ctx context.Context,
+	name Name,
+	logger zerolog.Logger,
+	endpoint Endpoint,
+	pairs ...types.CurrencyPair,
+) (Provider, error) {
+	registration, found := registry[name]
+	if !found && endpoint.GenericHTTP != nil {
+		// long-tail sources declared in the config under their own name
+		registration, found = registry[ProviderGenericHTTP]
+	}
+	if !found {
+		return nil, fmt.Errorf("provider %s not found", name)
+	}
+
+	endpoint.Name = name
+	providerLogger := logger.With().Str("provider", name.String()).Logger()
+
+	return registration.Constructor(db, ctx, providerLogger, endpoint, pairs...)
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"price-feeder/oracle/types"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	names := Registered()
+	require.NotEmpty(t, names)
+
+	for _, name := range names {
+		registration, found := Lookup(name)
+		require.True(t, found)
+		require.Equal(t, name, registration.Name)
+		require.Equal(t, name, registration.Defaults.Name)
+	}
+
+	for _, name := range []Name{ProviderOsmosis, ProviderBkex, ProviderWhitewhaleSei} {
+		require.True(t, IsRegistered(name), name)
+	}
+
+	// accepted by the config once, but never implemented
+	require.False(t, IsRegistered("stride"))
+	require.False(t, IsRegistered("bitforex"))
+
+	registration, _ := Lookup(ProviderFinV2)
+	require.True(t, registration.Capabilities.Volume)
+	require.True(t, registration.Capabilities.Contracts)
+
+	registration, _ = Lookup(ProviderKraken)
+	require.Equal(t, Capabilities{Class: SourceClassCEX, Websocket: true}, registration.Capabilities)
+
+	registration, _ = Lookup(ProviderMexc)
+	require.Equal(t, Capabilities{Class: SourceClassCEX}, registration.Capabilities)
+
+	for _, name := range names {
+		registration, _ := Lookup(name)
+		require.True(t, IsSourceClass(registration.Capabilities.Class), name)
+	}
+
+	require.Equal(t, SourceClassDEX, ProviderOsmosis.SourceClass())
+	require.Equal(t, SourceClassCEX, ProviderKraken.SourceClass())
+	require.Equal(t, SourceClassCEX, Name("foobar").SourceClass())
+}
+
+func TestRegister(t *testing.T) {
+	constructor := func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return nil, nil
+	}
+
+	require.Panics(t, func() {
+		Register(Registration{Name: ProviderBinance, Constructor: constructor})
+	})
+	require.Panics(t, func() {
+		Register(Registration{Name: "foobar"})
+	})
+	require.Panics(t, func() {
+		Register(Registration{Name: "foobar", Constructor: constructor})
+	})
+
+	_, err := New(nil, context.Background(), "foobar", zerolog.Nop(), Endpoint{})
+	require.EqualError(t, err, "provider foobar not found")
+}
+
+func TestEndpoint_SetDefaults(t *testing.T) {
+	endpoint := Endpoint{
+		Name:              ProviderKraken,
+		ContractAddresses: map[string]string{},
+	}
+	endpoint.SetDefaults()
+	require.Equal(t, krakenDefaultEndpoints.Urls, endpoint.Urls)
+	require.Equal(t, krakenDefaultEndpoints.PollInterval, endpoint.PollInterval)
+	require.Equal(t, RateLimit{Rate: 1, Burst: 5}, endpoint.RateLimit)
+
+	// configured rate limits are kept
+	endpoint = Endpoint{
+		Name:              ProviderKraken,
+		ContractAddresses: map[string]string{},
+		RateLimit:         RateLimit{Rate: 0.5},
+	}
+	endpoint.SetDefaults()
+	require.Equal(t, RateLimit{Rate: 0.5, Burst: 5}, endpoint.RateLimit)
+
+	// unknown providers are left untouched
+	endpoint = Endpoint{Name: "foobar"}
+	endpoint.SetDefaults()
+	require.Empty(t, endpoint.Urls)
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"price-feeder/oracle/types"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// initStreamTest sets up the provider to handle websocket messages of the
+// ATOM/USDT and BTC/USDT pairs, without connecting anywhere.
+func initStreamTest(
+	p *provider,
+	name Name,
+	availablePairs []string,
+	toProviderSymbol CurrencyPairToProviderSymbol,
+) {
+	p.endpoints.Name = name
+	p.logger = zerolog.Nop()
+	p.tickers = map[string]types.TickerPrice{}
+	p.websocket = &WebsocketController{}
+	p.streamed = map[string]time.Time{}
+
+	available := map[string]struct{}{}
+	for _, symbol := range availablePairs {
+		available[symbol] = struct{}{}
+	}
+
+	p.setPairs(
+		[]types.CurrencyPair{testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair},
+		available,
+		toProviderSymbol,
+	)
+}
+
+func requireSubscriptionMsgs(t *testing.T, expected string, msgs []interface{}) {
+	bz, err := json.Marshal(msgs)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(bz))
+}
+
+func TestProvider_IsStreaming(t *testing.T) {
+	p := &provider{}
+	initStreamTest(p, ProviderMock, []string{"ATOMUSDT", "USDTBTC"}, nil)
+	now := time.Now()
+
+	require.False(t, p.isStreaming(now))
+	require.Equal(t, []string{"ATOMUSDT", "USDTBTC"}, p.getSymbols(
+		testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair,
+	))
+
+	p.setStreamedTickerPrice("ATOMUSDT", testAtomPriceDec, testAtomVolumeDec, now)
+	require.False(t, p.isStreaming(now))
+
+	// inverted pairs are tracked by the pair
+	p.setStreamedTickerPrice("USDTBTC", testAtomPriceDec, testAtomVolumeDec, now)
+	require.True(t, p.isStreaming(time.Now()))
+	require.False(t, p.isStreaming(time.Now().Add(staleStreamCutoff+time.Second)))
+
+	p.websocket = nil
+	require.False(t, p.isStreaming(time.Now()))
+}
+
+func TestBinanceProvider_Stream(t *testing.T) {
+	p := &BinanceProvider{}
+	initStreamTest(&p.provider, ProviderBinance, []string{"ATOMUSDT", "BTCUSDT"}, currencyPairToBinanceSymbol)
+
+	requireSubscriptionMsgs(t,
+		`[{"method":"SUBSCRIBE","params":["atomusdt@miniTicker","btcusdt@miniTicker"],"id":1}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"result":null,"id":1}`))
+	p.messageReceived(1, []byte(`{"e":"24hrMiniTicker","E":1672515782136,"s":"ATOMUSDT","c":"12.3456","v":"7654321.98765"}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testAtomPriceDec, p.tickers["ATOMUSDT"].Price)
+	require.Equal(t, testAtomVolumeDec, p.tickers["ATOMUSDT"].Volume)
+	require.Equal(t, time.UnixMilli(1672515782136), p.tickers["ATOMUSDT"].Time)
+	require.Contains(t, p.streamed, "ATOMUSDT")
+}
+
+func TestBinanceProvider_SubscribeCurrencyPairs(t *testing.T) {
+	p := &BinanceProvider{}
+	initStreamTest(&p.provider, ProviderBinance, []string{"ATOMUSDT", "BTCUSDT", "OSMOUSDT"}, currencyPairToBinanceSymbol)
+
+	osmoUsdt := types.CurrencyPair{Base: "OSMO", Quote: "USDT"}
+
+	var msgs []interface{}
+	p.websocket.subscribeHandler = func(pairs ...types.CurrencyPair) []interface{} {
+		msgs = p.getSubscriptionMsgs(pairs...)
+		// nothing to send without a connection
+		return nil
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- p.SubscribeCurrencyPairs(testAtomUsdtCurrencyPair, osmoUsdt)
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("subscribing new pairs did not return")
+	}
+
+	requireSubscriptionMsgs(t,
+		`[{"method":"SUBSCRIBE","params":["osmousdt@miniTicker"],"id":1}]`,
+		msgs,
+	)
+
+	p.messageReceived(1, []byte(`{"e":"24hrMiniTicker","E":1672515782136,"s":"OSMOUSDT","c":"12.3456","v":"7654321.98765"}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testAtomPriceDec, p.tickers["OSMOUSDT"].Price)
+	require.Contains(t, p.streamed, "OSMOUSDT")
+}
+
+func TestOkxProvider_Stream(t *testing.T) {
+	p := &OkxProvider{}
+	initStreamTest(&p.provider, ProviderOkx, []string{"ATOM-USDT", "BTC-USDT"}, currencyPairToOkxSymbol)
+
+	requireSubscriptionMsgs(t,
+		`[{"op":"subscribe","args":[{"channel":"tickers","instId":"ATOM-USDT"},{"channel":"tickers","instId":"BTC-USDT"}]}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"event":"subscribe","arg":{"channel":"tickers","instId":"ATOM-USDT"}}`))
+	p.messageReceived(1, []byte(`{"arg":{"channel":"tickers","instId":"ATOM-USDT"},"data":[{"instId":"ATOM-USDT","last":"12.3456","vol24h":"7654321.98765","ts":"1672515782136"}]}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testAtomPriceDec, p.tickers["ATOMUSDT"].Price)
+	require.Equal(t, testAtomVolumeDec, p.tickers["ATOMUSDT"].Volume)
+	require.Equal(t, time.UnixMilli(1672515782136), p.tickers["ATOMUSDT"].Time)
+	require.Contains(t, p.streamed, "ATOMUSDT")
+}
+
+func TestBybitProvider_Stream(t *testing.T) {
+	p := &BybitProvider{}
+	initStreamTest(&p.provider, ProviderBybit, []string{"ATOMUSDT", "BTCUSDT"}, currencyPairToBybitSymbol)
+
+	requireSubscriptionMsgs(t,
+		`[{"op":"subscribe","args":["tickers.ATOMUSDT","tickers.BTCUSDT"]}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"success":true,"ret_msg":"pong","op":"ping"}`))
+	p.messageReceived(1, []byte(`{"topic":"tickers.BTCUSDT","ts":1672515782136,"type":"snapshot","data":{"symbol":"BTCUSDT","lastPrice":"12345.6789","volume24h":"7654.32198765"}}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testBtcPriceDec, p.tickers["BTCUSDT"].Price)
+	require.Equal(t, testBtcVolumeDec, p.tickers["BTCUSDT"].Volume)
+	require.Equal(t, time.UnixMilli(1672515782136), p.tickers["BTCUSDT"].Time)
+	require.Contains(t, p.streamed, "BTCUSDT")
+}
+
+func TestBybitProvider_SubscriptionMsgs(t *testing.T) {
+	p := &BybitProvider{}
+	p.logger = zerolog.Nop()
+
+	pairs := []types.CurrencyPair{}
+	available := map[string]struct{}{}
+	for _, base := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"} {
+		pairs = append(pairs, types.CurrencyPair{Base: base, Quote: "USDT"})
+		available[base+"USDT"] = struct{}{}
+	}
+	p.setPairs(pairs, available, currencyPairToBybitSymbol)
+
+	msgs := p.getSubscriptionMsgs(pairs...)
+	require.Len(t, msgs, 2)
+	require.Len(t, msgs[0].(BybitSubscriptionMsg).Args, bybitMaxSubscriptionArgs)
+	require.Len(t, msgs[1].(BybitSubscriptionMsg).Args, 2)
+}
+
+func TestKrakenProvider_Stream(t *testing.T) {
+	p := &KrakenProvider{}
+	initStreamTest(&p.provider, ProviderKraken, []string{"ATOMUSDT", "XBTUSDT"}, currencyPairToKrakenSymbol)
+	p.websocketNames = map[string]string{"ATOMUSDT": "ATOM/USDT", "XBTUSDT": "XBT/USDT"}
+	p.restSymbols = map[string]string{"ATOM/USDT": "ATOMUSDT", "XBT/USDT": "XBTUSDT"}
+
+	requireSubscriptionMsgs(t,
+		`[{"event":"subscribe","pair":["ATOM/USDT","XBT/USDT"],"subscription":{"name":"ticker"}}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"event":"heartbeat"}`))
+	p.messageReceived(1, []byte(`[340,{"c":["12345.6789","0.1"],"v":["10.0","7654.32198765"]},"ticker","XBT/USDT"]`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testBtcPriceDec, p.tickers["BTCUSDT"].Price)
+	require.Equal(t, testBtcVolumeDec, p.tickers["BTCUSDT"].Volume)
+	require.Contains(t, p.streamed, "BTCUSDT")
+}
+
+func TestCoinbaseProvider_Stream(t *testing.T) {
+	p := &CoinbaseProvider{}
+	initStreamTest(&p.provider, ProviderCoinbase, []string{"ATOM-USDT", "BTC-USDT"}, currencyPairToCoinbaseSymbol)
+
+	requireSubscriptionMsgs(t,
+		`[{"type":"subscribe","product_ids":["ATOM-USDT","BTC-USDT"],"channels":["ticker"]}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"type":"subscriptions","channels":[]}`))
+	p.messageReceived(1, []byte(`{"type":"ticker","product_id":"ATOM-USDT","price":"12.3456","volume_24h":"7654321.98765","time":"2022-10-19T23:28:22.061769Z"}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testAtomPriceDec, p.tickers["ATOMUSDT"].Price)
+	require.Equal(t, testAtomVolumeDec, p.tickers["ATOMUSDT"].Volume)
+	require.Equal(t, time.Date(2022, 10, 19, 23, 28, 22, 61769000, time.UTC), p.tickers["ATOMUSDT"].Time)
+	require.Contains(t, p.streamed, "ATOMUSDT")
+}
+
+func TestKucoinProvider_Stream(t *testing.T) {
+	p := &KucoinProvider{}
+	initStreamTest(&p.provider, ProviderKucoin, []string{"ATOM-USDT", "BTC-USDT"}, currencyPairToKucoinSymbol)
+
+	requireSubscriptionMsgs(t,
+		`[{"id":1,"type":"subscribe","topic":"/market/snapshot:ATOM-USDT,BTC-USDT","response":true}]`,
+		p.getSubscriptionMsgs(testAtomUsdtCurrencyPair, testBtcUsdtCurrencyPair),
+	)
+
+	p.messageReceived(1, []byte(`{"id":"hQvf8jkno","type":"welcome"}`))
+	p.messageReceived(1, []byte(`{"type":"message","topic":"/market/snapshot:ATOM-USDT","subject":"trade.snapshot","data":{"sequence":"1545896669291","data":{"symbol":"ATOM-USDT","lastTradedPrice":12.3456,"vol":7654321.98765,"datetime":1672515782136}}}`))
+
+	require.Len(t, p.tickers, 1)
+	require.Equal(t, testAtomPriceDec, p.tickers["ATOMUSDT"].Price)
+	require.Equal(t, testAtomVolumeDec, p.tickers["ATOMUSDT"].Volume)
+	require.Equal(t, time.UnixMilli(1672515782136), p.tickers["ATOMUSDT"].Time)
+	require.Contains(t, p.streamed, "ATOMUSDT")
+}
+
+func TestKucoinProvider_getWebsocketURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/api/v1/bullet-public", req.URL.String())
+		rw.Write([]byte(`{"code":"200000","data":{"token":"abc","instanceServers":[{"endpoint":"wss://ws-api-spot.kucoin.com/","pingInterval":18000}]}}`))
+	}))
+	defer server.Close()
+
+	p := &KucoinProvider{}
+	p.ctx = context.Background()
+	p.logger = zerolog.Nop()
+	p.http = newDefaultHTTPClient()
+	p.endpoints.Urls = []string{server.URL}
+	p.httpBase = server.URL
+
+	websocketURL, err := p.getWebsocketURL()
+	require.NoError(t, err)
+	require.Equal(t, "ws-api-spot.kucoin.com", websocketURL.Host)
+	require.Equal(t, "abc", websocketURL.Query().Get("token"))
+	require.NotEmpty(t, websocketURL.Query().Get("connectId"))
+}
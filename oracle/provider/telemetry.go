@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"strconv"
+
 	"github.com/armon/go-metrics"
 	"github.com/cosmos/cosmos-sdk/telemetry"
 )
@@ -36,6 +38,14 @@ func messageTypeLabel(mt MessageType) metrics.Label {
 	}
 }
 
+// hostLabel returns a label based on the http host.
+func hostLabel(host string) metrics.Label {
+	return metrics.Label{
+		Name:  "host",
+		Value: host,
+	}
+}
+
 // telemetryWebsocketReconnect gives an standard way to add
 // `price_feeder_websocket_reconnect` metric.
 func telemetryWebsocketReconnect(n Name) {
@@ -123,3 +133,39 @@ func TelemetryEvmMethod(chain, provider, method string) {
 		labels,
 	)
 }
+
+// telemetryHostTokens sets the `price_feeder_http_tokens{host="x"}` metric.
+func telemetryHostTokens(host string, tokens float64) {
+	telemetry.SetGaugeWithLabels(
+		[]string{"http", "tokens"},
+		float32(tokens),
+		[]metrics.Label{hostLabel(host)},
+	)
+}
+
+// telemetryHostPaused sets the `price_feeder_http_paused{host="x"}` metric.
+func telemetryHostPaused(host string, paused bool) {
+	var value float32
+	if paused {
+		value = 1
+	}
+
+	telemetry.SetGaugeWithLabels(
+		[]string{"http", "paused"},
+		value,
+		[]metrics.Label{hostLabel(host)},
+	)
+}
+
+// telemetryHostRateLimited adds to the
+// `price_feeder_http_ratelimited{host="x", code="x"}` metric.
+func telemetryHostRateLimited(host string, code int) {
+	telemetry.IncrCounterWithLabels(
+		[]string{"http", "ratelimited"},
+		1,
+		[]metrics.Label{
+			hostLabel(host),
+			telemetry.NewLabel("code", strconv.Itoa(code)),
+		},
+	)
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -43,6 +44,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderUniswapV3,
+		Defaults: uniswapv3DefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewUniswapV3Provider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewUniswapV3Provider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -65,7 +83,7 @@ func NewUniswapV3Provider(
 	// get token decimals
 	provider.setDecimals()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -31,6 +32,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderUnstake,
+		Defaults: unstakeDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewUnstakeProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewUnstakeProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -62,7 +80,7 @@ func NewUnstakeProvider(
 		provider.decimals[symbol] = uint64(decimals)
 	}
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
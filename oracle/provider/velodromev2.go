@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -34,6 +35,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderVelodromeV2,
+		Defaults: velodromev2DefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewVelodromeV2Provider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassDEX, Contracts: true},
+	})
+}
+
 func NewVelodromeV2Provider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -56,7 +74,7 @@ func NewVelodromeV2Provider(
 	// get token decimals
 	provider.setDecimals()
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -31,6 +32,7 @@ type VolumeHandler struct {
 	period   int64
 	missing  []uint64
 	cleanup  *sql.Stmt
+	wg       *sync.WaitGroup
 }
 
 func NewVolumeHandler(
@@ -54,6 +56,7 @@ func NewVolumeHandler(
 		volumes:  []Volume{},
 		period:   period,
 		missing:  []uint64{},
+		wg:       &sync.WaitGroup{},
 	}
 
 	err := handler.init()
@@ -268,7 +271,10 @@ func (h *VolumeHandler) Add(volumes []Volume) {
 	stopTime := h.volumes[len(h.volumes)-1].Time
 	startTime := stopTime - h.period
 
+	h.wg.Add(1)
 	go func() {
+		defer h.wg.Done()
+
 		err := h.persist(volumes)
 		if err != nil {
 			h.logger.Error().Msg("error writing volumes to database")
@@ -567,6 +573,14 @@ func (h *VolumeHandler) persist(volumes []Volume) error {
 	return nil
 }
 
+// Wait blocks until all pending writes to the database are done.
+func (h *VolumeHandler) Wait() {
+	if h.wg == nil {
+		return
+	}
+	h.wg.Wait()
+}
+
 func (h *VolumeHandler) GetMissing(amount int) []uint64 {
 	if len(h.missing) >= amount {
 		return h.missing[len(h.missing)-amount:]
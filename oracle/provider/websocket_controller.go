@@ -28,6 +28,10 @@ type (
 
 	SubscribeHandler func(...types.CurrencyPair) []interface{}
 
+	// URLHandler returns the url to connect to, for providers handing out
+	// websocket urls with short-lived tokens.
+	URLHandler func() (url.URL, error)
+
 	// WebsocketController defines a provider agnostic websocket handler
 	// that manages reconnecting, subscribing, and receiving messages
 	WebsocketController struct {
@@ -39,6 +43,7 @@ type (
 		pairs 				[]types.CurrencyPair
 		messageHandler      MessageHandler
 		subscribeHandler	SubscribeHandler
+		urlHandler          URLHandler
 		pingDuration        time.Duration
 		pingMessage         string
 		pingMessageType     uint
@@ -47,6 +52,8 @@ type (
 		mtx              sync.Mutex
 		client           *websocket.Conn
 		reconnectCounter uint
+
+		wg sync.WaitGroup
 	}
 )
 
@@ -78,15 +85,40 @@ func NewWebsocketController(
 	}
 }
 
-// Start will continuously loop and attempt connecting to the websocket
+// SetURLHandler sets the handler called for the url before every connect,
+// it must be set before the controller is started.
+func (wsc *WebsocketController) SetURLHandler(handler URLHandler) {
+	wsc.urlHandler = handler
+}
+
+// Start connects to the websocket in a new go routine, see connectLoop.
+func (wsc *WebsocketController) Start() {
+	wsc.wg.Add(1)
+	go func() {
+		defer wsc.wg.Done()
+		wsc.connectLoop()
+	}()
+}
+
+// Wait blocks until all go routines of the controller have stopped, which
+// happens once the parent context is cancelled.
+func (wsc *WebsocketController) Wait() {
+	wsc.wg.Wait()
+}
+
+// connectLoop will continuously loop and attempt connecting to the websocket
 // until a successful connection is made. It then starts the ping
 // service and read listener in new go routines and sends subscription
 // messages  using the passed in subscription messages
-func (wsc *WebsocketController) Start() {
+func (wsc *WebsocketController) connectLoop() {
 	connectTicker := time.NewTicker(time.Millisecond)
 	defer connectTicker.Stop()
 
 	for {
+		if wsc.parentCtx.Err() != nil {
+			return
+		}
+
 		if err := wsc.connect(); err != nil {
 			wsc.logger.Err(err).Send()
 			select {
@@ -98,8 +130,30 @@ func (wsc *WebsocketController) Start() {
 			}
 		}
 
-		go wsc.readWebSocket()
-		go wsc.pingLoop()
+		wsc.mtx.Lock()
+		conn, ctx := wsc.client, wsc.websocketCtx
+		wsc.mtx.Unlock()
+
+		wsc.wg.Add(3)
+		go func() {
+			defer wsc.wg.Done()
+			wsc.readWebSocket(ctx, conn)
+		}()
+		go func() {
+			defer wsc.wg.Done()
+			wsc.pingLoop(ctx)
+		}()
+		go func() {
+			defer wsc.wg.Done()
+			// unblocks the read listener on shutdown
+			<-ctx.Done()
+			wsc.mtx.Lock()
+			if wsc.client == conn {
+				wsc.client = nil
+			}
+			wsc.mtx.Unlock()
+			conn.Close()
+		}()
 
 		if err := wsc.subscribe(wsc.subscribeHandler(wsc.pairs...)); err != nil {
 			wsc.logger.Err(err).Send()
@@ -115,15 +169,26 @@ func (wsc *WebsocketController) connect() error {
 	wsc.mtx.Lock()
 	defer wsc.mtx.Unlock()
 
+	websocketURL := wsc.websocketURL
+	if wsc.urlHandler != nil {
+		handlerURL, err := wsc.urlHandler()
+		if err != nil {
+			return fmt.Errorf(types.ErrWebsocketDial.Error(), wsc.providerName, err)
+		}
+		websocketURL = handlerURL
+	}
+
 	wsc.logger.Debug().Msg("connecting to websocket")
-	conn, resp, err := websocket.DefaultDialer.Dial(wsc.websocketURL.String(), nil)
+	conn, resp, err := websocket.DefaultDialer.Dial(websocketURL.String(), nil)
 	if err != nil {
 		return fmt.Errorf(types.ErrWebsocketDial.Error(), wsc.providerName, err)
 	}
 	defer resp.Body.Close()
 	wsc.client = conn
 	wsc.websocketCtx, wsc.websocketCancelFunc = context.WithCancel(wsc.parentCtx)
-	wsc.client.SetPingHandler(wsc.pingHandler)
+	wsc.client.SetPingHandler(func(appData string) error {
+		return wsc.pingHandler(conn, appData)
+	})
 	wsc.reconnectCounter = 0
 	return nil
 }
@@ -178,7 +243,7 @@ func (wsc *WebsocketController) SendJSON(msg interface{}) error {
 }
 
 // ping sends a ping to the server every defaultPingDuration
-func (wsc *WebsocketController) pingLoop() {
+func (wsc *WebsocketController) pingLoop(ctx context.Context) {
 	if wsc.pingDuration == disabledPingDuration {
 		return // disable ping loop if disabledPingDuration
 	}
@@ -191,7 +256,7 @@ func (wsc *WebsocketController) pingLoop() {
 			return
 		}
 		select {
-		case <-wsc.websocketCtx.Done():
+		case <-ctx.Done():
 			return
 		case <-pingTicker.C:
 			continue
@@ -224,18 +289,21 @@ func (wsc *WebsocketController) ping() error {
 // terminates and starts the reconnect process.
 // Some providers (Binance) will only allow a valid connection for 24 hours
 // so we manually disconnect and reconnect every 23 hours (defaultMaxConnectionTime)
-func (wsc *WebsocketController) readWebSocket() {
+func (wsc *WebsocketController) readWebSocket(ctx context.Context, conn *websocket.Conn) {
 	reconnectTicker := time.NewTicker(defaultMaxConnectionTime)
 	defer reconnectTicker.Stop()
 
 	for {
 		select {
-		case <-wsc.websocketCtx.Done():
-			wsc.close()
+		case <-ctx.Done():
 			return
 		case <-time.After(defaultReadNewWSMessage):
-			messageType, bz, err := wsc.client.ReadMessage()
+			messageType, bz, err := conn.ReadMessage()
 			if err != nil {
+				if ctx.Err() != nil {
+					// connection closed on purpose
+					return
+				}
 				wsc.logger.Err(fmt.Errorf(types.ErrWebsocketRead.Error(), wsc.providerName, err)).Send()
 				wsc.reconnect()
 				return
@@ -264,6 +332,10 @@ func (wsc *WebsocketController) close() {
 	wsc.mtx.Lock()
 	defer wsc.mtx.Unlock()
 
+	if wsc.client == nil {
+		return
+	}
+
 	wsc.logger.Debug().Msg("closing websocket")
 	wsc.websocketCancelFunc()
 	if err := wsc.client.Close(); err != nil {
@@ -275,14 +347,16 @@ func (wsc *WebsocketController) close() {
 // reconnect closes the current websocket and starts a new connection process
 func (wsc *WebsocketController) reconnect() {
 	wsc.close()
-	go wsc.Start()
+	wsc.Start()
 	telemetryWebsocketReconnect(wsc.providerName)
 }
 
 // pingHandler is called by the websocket library whenever a ping message is received
-// and responds with a pong message to the server
-func (wsc *WebsocketController) pingHandler(appData string) error {
-	if err := wsc.client.WriteMessage(websocket.PongMessage, []byte("pong")); err != nil {
+// and responds with a pong message echoing the ping's payload, as required by
+// Binance. Control messages may be written concurrently to other messages.
+func (wsc *WebsocketController) pingHandler(conn *websocket.Conn, appData string) error {
+	deadline := time.Now().Add(defaultPingDuration)
+	if err := conn.WriteControl(websocket.PongMessage, []byte(appData), deadline); err != nil {
 		wsc.logger.Error().Err(err).Msg("error sending pong")
 	}
 	return nil
@@ -103,6 +103,67 @@ type (
 	}
 )
 
+func init() {
+	constructor := func(
+		db *sql.DB,
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewWhitewhaleProvider(db, ctx, logger, endpoint, pairs...)
+	}
+
+	Register(Registration{
+		Name:         ProviderWhitewhaleCmdx,
+		Defaults:     whitewhaleCmdxDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleHuahua,
+		Defaults:     whitewhaleHuahuaDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleInj,
+		Defaults:     whitewhaleInjDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleJuno,
+		Defaults:     whitewhaleJunoDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleLuna,
+		Defaults:     whitewhaleLunaDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleLunc,
+		Defaults:     whitewhaleLuncDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleSei,
+		Defaults:     whitewhaleSeiDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+	Register(Registration{
+		Name:         ProviderWhitewhaleWhale,
+		Defaults:     whitewhaleWhaleDefaultEndpoints,
+		Constructor:  constructor,
+		Capabilities: Capabilities{Class: SourceClassDEX, Volume: true, Contracts: true},
+	})
+}
+
 func NewWhitewhaleProvider(
 	db *sql.DB,
 	ctx context.Context,
@@ -132,7 +193,7 @@ func NewWhitewhaleProvider(
 		provider.denoms[asset.Denom] = symbol
 	}
 
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -315,7 +376,9 @@ func (p *WhitewhaleProvider) updateVolumes() {
 
 	for _, height := range missing {
 		volume, err := p.getVolume(height)
-		time.Sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause))
+		if !p.sleep(time.Millisecond * time.Duration(p.endpoints.VolumePause)) {
+			break
+		}
 		if err != nil {
 			p.error(err)
 			continue
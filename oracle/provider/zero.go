@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"price-feeder/oracle/types"
@@ -27,6 +28,23 @@ type (
 	}
 )
 
+func init() {
+	Register(Registration{
+		Name:     ProviderZero,
+		Defaults: zeroDefaultEndpoints,
+		Constructor: func(
+			db *sql.DB,
+			ctx context.Context,
+			logger zerolog.Logger,
+			endpoint Endpoint,
+			pairs ...types.CurrencyPair,
+		) (Provider, error) {
+			return NewZeroProvider(ctx, logger, endpoint, pairs...)
+		},
+		Capabilities: Capabilities{Class: SourceClassCEX},
+	})
+}
+
 func NewZeroProvider(
 	ctx context.Context,
 	logger zerolog.Logger,
@@ -42,7 +60,7 @@ func NewZeroProvider(
 		nil,
 		nil,
 	)
-	go startPolling(provider, provider.endpoints.PollInterval, logger)
+	provider.startPolling(provider, provider.endpoints.PollInterval, logger)
 	return provider, nil
 }
 
@@ -0,0 +1,200 @@
+package oracle
+
+import (
+	"sort"
+	"time"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetProviderScores returns the reputation of all scored providers, sorted
+// by denom and provider. If a denom is given, only its scores are returned.
+func (o *Oracle) GetProviderScores(denom string) []types.ProviderScore {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	scores := []types.ProviderScore{}
+	for scoredDenom, providerScores := range o.scores {
+		if denom != "" && scoredDenom != denom {
+			continue
+		}
+		for _, score := range providerScores {
+			scores = append(scores, score)
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Denom != scores[j].Denom {
+			return scores[i].Denom < scores[j].Denom
+		}
+		return scores[i].Provider < scores[j].Provider
+	})
+
+	return scores
+}
+
+// recordDeviations stores how far the USD price of each provider deviated
+// from the final price of its denom. Tickers dropped by the deviation filter
+// or quarantined are recorded as well, so their providers can recover.
+func (o *Oracle) recordDeviations(
+	prices map[string]sdk.Dec,
+	audits map[string]types.PriceAudit,
+	now time.Time,
+) {
+	for denom, price := range prices {
+		if !price.IsPositive() {
+			continue
+		}
+
+		for _, ticker := range audits[denom].Tickers {
+			if ticker.Dropped == types.AuditDroppedDuplicate {
+				continue
+			}
+
+			deviation := ticker.USDPrice.Sub(price).Abs().Quo(price).MulInt64(100)
+
+			// errors are logged by the history
+			_ = o.history.AddProviderDeviation(denom, ticker.Provider, now, deviation)
+		}
+	}
+}
+
+// computeScores scores all providers of the configured denoms by their
+// deviations within the reputation window.
+func (o *Oracle) computeScores(now time.Time) map[string]map[string]types.ProviderScore {
+	denoms := map[string]struct{}{}
+	for _, pairs := range o.providerPairs {
+		for _, pair := range pairs {
+			denoms[pair.Base] = struct{}{}
+		}
+	}
+
+	scores := map[string]map[string]types.ProviderScore{}
+	for denom := range denoms {
+		deviations, err := o.history.GetProviderDeviations(denom, now.Add(-o.reputation.Window))
+		if err != nil {
+			continue
+		}
+
+		for providerName, providerDeviations := range deviations {
+			score := scoreProvider(denom, providerName, providerDeviations, o.reputation)
+
+			if _, found := scores[denom]; !found {
+				scores[denom] = map[string]types.ProviderScore{}
+			}
+			scores[denom][providerName] = score
+
+			labels := []metrics.Label{
+				telemetry.NewLabel("denom", denom),
+				telemetry.NewLabel("provider", providerName),
+			}
+
+			telemetry.SetGaugeWithLabels(
+				[]string{"provider", "reputation"},
+				float32(score.Score.MustFloat64()),
+				labels,
+			)
+
+			quarantined := float32(0)
+			if score.Quarantined {
+				quarantined = 1
+			}
+			telemetry.SetGaugeWithLabels(
+				[]string{"provider", "quarantined"},
+				quarantined,
+				labels,
+			)
+		}
+	}
+
+	return scores
+}
+
+// scoreProvider computes the reputation of a provider from its deviations.
+// Each miss adds its deviation in multiples of the tolerance to a penalty,
+// so the score 1 / (1 + penalty / samples) falls with both the frequency
+// and the size of the misses.
+func scoreProvider(
+	denom string,
+	providerName string,
+	deviations []sdk.Dec,
+	reputation types.Reputation,
+) types.ProviderScore {
+	score := types.ProviderScore{
+		Denom:         denom,
+		Provider:      providerName,
+		Score:         sdk.OneDec(),
+		Samples:       len(deviations),
+		MeanDeviation: sdk.ZeroDec(),
+	}
+
+	if len(deviations) == 0 {
+		return score
+	}
+
+	penalty := sdk.ZeroDec()
+	for _, deviation := range deviations {
+		score.MeanDeviation = score.MeanDeviation.Add(deviation)
+
+		if deviation.GT(reputation.Tolerance) {
+			score.Misses++
+			penalty = penalty.Add(deviation.Quo(reputation.Tolerance))
+		}
+	}
+
+	samples := sdk.NewDec(int64(len(deviations)))
+	score.MeanDeviation = score.MeanDeviation.Quo(samples)
+
+	if len(deviations) < reputation.MinSamples {
+		return score
+	}
+
+	score.Score = sdk.OneDec().Quo(sdk.OneDec().Add(penalty.Quo(samples)))
+	score.Quarantined = score.Score.LT(reputation.Quarantine)
+
+	return score
+}
+
+// applyScores multiplies the volumes of the tickers with the scores of their
+// providers and removes the tickers of quarantined providers, unless all
+// providers are quarantined. It returns whether the quarantine has been
+// skipped for that reason.
+func applyScores(
+	tickers map[provider.Name]types.TickerPrice,
+	scores map[string]types.ProviderScore,
+) (map[provider.Name]types.TickerPrice, bool) {
+	scored := make(map[provider.Name]types.TickerPrice, len(tickers))
+	quarantined := 0
+
+	for providerName, ticker := range tickers {
+		score, found := scores[providerName.String()]
+		if !found {
+			scored[providerName] = ticker
+			continue
+		}
+
+		if score.Quarantined {
+			quarantined++
+			continue
+		}
+
+		ticker.Volume = ticker.Volume.Mul(score.Score)
+		scored[providerName] = ticker
+	}
+
+	if quarantined == 0 || len(scored) > 0 {
+		return scored, false
+	}
+
+	for providerName, ticker := range tickers {
+		ticker.Volume = ticker.Volume.Mul(scores[providerName.String()].Score)
+		scored[providerName] = ticker
+	}
+
+	return scored, true
+}
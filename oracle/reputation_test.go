@@ -0,0 +1,140 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	"price-feeder/oracle/history"
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreProvider(t *testing.T) {
+	reputation := types.Reputation{
+		Window:     time.Hour,
+		Tolerance:  sdk.OneDec(),
+		Quarantine: sdk.MustNewDecFromStr("0.2"),
+		MinSamples: 4,
+	}
+
+	decs := func(values ...string) []sdk.Dec {
+		deviations := []sdk.Dec{}
+		for _, value := range values {
+			deviations = append(deviations, sdk.MustNewDecFromStr(value))
+		}
+		return deviations
+	}
+
+	// not enough samples
+	score := scoreProvider("ATOM", "binance", decs("0.5", "3", "0.1"), reputation)
+	require.Equal(t, sdk.OneDec(), score.Score)
+	require.Equal(t, 3, score.Samples)
+	require.Equal(t, 1, score.Misses)
+	require.False(t, score.Quarantined)
+
+	// 1 / (1 + (2 + 4) / 4)
+	score = scoreProvider("ATOM", "binance", decs("0.5", "0.5", "2", "4"), reputation)
+	require.Equal(t, sdk.MustNewDecFromStr("0.4"), score.Score)
+	require.Equal(t, 2, score.Misses)
+	require.Equal(t, sdk.MustNewDecFromStr("1.75"), score.MeanDeviation)
+	require.False(t, score.Quarantined)
+
+	// 1 / (1 + 20 / 4)
+	score = scoreProvider("ATOM", "binance", decs("5", "5", "5", "5"), reputation)
+	require.Equal(t, sdk.MustNewDecFromStr("0.166666666666666667"), score.Score)
+	require.True(t, score.Quarantined)
+
+	score = scoreProvider("ATOM", "binance", decs("0.1", "0.2", "0.3", "1"), reputation)
+	require.Equal(t, sdk.OneDec(), score.Score)
+	require.Zero(t, score.Misses)
+}
+
+func TestApplyScores(t *testing.T) {
+	tickers := map[provider.Name]types.TickerPrice{
+		provider.ProviderBinance: testTicker("10", "100"),
+		provider.ProviderKraken:  testTicker("10", "100"),
+		provider.ProviderOkx:     testTicker("10", "100"),
+	}
+
+	scores := map[string]types.ProviderScore{
+		"binance": {Score: sdk.MustNewDecFromStr("0.5")},
+		"kraken":  {Score: sdk.MustNewDecFromStr("0.1"), Quarantined: true},
+	}
+
+	scored, skipped := applyScores(tickers, scores)
+	require.False(t, skipped)
+	require.Len(t, scored, 2)
+	require.Equal(t, sdk.NewDec(50), scored[provider.ProviderBinance].Volume)
+	require.Equal(t, sdk.NewDec(100), scored[provider.ProviderOkx].Volume)
+
+	// the quarantine is skipped if all providers are quarantined
+	scored, skipped = applyScores(
+		map[provider.Name]types.TickerPrice{
+			provider.ProviderKraken: testTicker("10", "100"),
+		},
+		scores,
+	)
+	require.True(t, skipped)
+	require.Equal(t, sdk.NewDec(10), scored[provider.ProviderKraken].Volume)
+}
+
+func TestProviderReputation(t *testing.T) {
+	priceHistory, err := history.NewPriceHistory(":memory:", zerolog.Nop())
+	require.NoError(t, err)
+
+	atomUsd := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+
+	oracle := &Oracle{
+		logger:  zerolog.Nop(),
+		history: priceHistory,
+		providerPairs: map[provider.Name][]types.CurrencyPair{
+			provider.ProviderBinance: {atomUsd},
+			provider.ProviderKraken:  {atomUsd},
+		},
+		reputation: types.Reputation{
+			Window:     time.Hour,
+			Tolerance:  sdk.OneDec(),
+			Quarantine: sdk.MustNewDecFromStr("0.5"),
+			MinSamples: 2,
+		},
+	}
+
+	audits := map[string]types.PriceAudit{
+		"ATOM": {
+			Tickers: []types.AuditTicker{
+				{Provider: "binance", USDPrice: sdk.MustNewDecFromStr("10.05")},
+				{Provider: "kraken", USDPrice: sdk.MustNewDecFromStr("11")},
+				{Provider: "okx", USDPrice: sdk.MustNewDecFromStr("20"), Dropped: types.AuditDroppedDuplicate},
+			},
+		},
+	}
+
+	now := time.Now()
+	prices := map[string]sdk.Dec{"ATOM": sdk.NewDec(10)}
+
+	oracle.recordDeviations(prices, audits, now.Add(-2*time.Hour))
+	oracle.recordDeviations(prices, audits, now.Add(-2*time.Second))
+	oracle.recordDeviations(prices, audits, now.Add(-time.Second))
+
+	oracle.scores = oracle.computeScores(now)
+
+	scores := oracle.GetProviderScores("")
+	require.Len(t, scores, 2)
+
+	require.Equal(t, "binance", scores[0].Provider)
+	require.Equal(t, sdk.OneDec(), scores[0].Score)
+	require.Equal(t, 2, scores[0].Samples)
+	require.False(t, scores[0].Quarantined)
+
+	// 1 / (1 + 20 / 2)
+	require.Equal(t, "kraken", scores[1].Provider)
+	require.Equal(t, sdk.MustNewDecFromStr("0.090909090909090909"), scores[1].Score)
+	require.Equal(t, 2, scores[1].Misses)
+	require.True(t, scores[1].Quarantined)
+
+	require.Empty(t, oracle.GetProviderScores("KUJI"))
+}
@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"fmt"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// broadcastTx broadcasts the given messages via the oracle client. In dry run
+// mode the messages are only validated and never signed or broadcasted.
+func (v *Voter) broadcastTx(nextBlockHeight, timeoutHeight int64, msgs ...sdk.Msg) error {
+	if !v.oracleClient.DryRun {
+		return v.oracleClient.BroadcastTx(nextBlockHeight, timeoutHeight, msgs...)
+	}
+
+	for _, msg := range msgs {
+		if err := msg.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid %s: %w", sdk.MsgTypeURL(msg), err)
+		}
+	}
+
+	v.logger.Info().
+		Int("msgs", len(msgs)).
+		Msg("dry run, skipping broadcast")
+
+	return nil
+}
+
+// reportDryRunDeviation logs and exports how far the price we would have
+// voted for a denom deviated from its tallied on-chain exchange rate.
+func (v *Voter) reportDryRunDeviation(symbol string, deviation sdk.Dec, votePeriod int64) {
+	v.logger.Info().
+		Str("denom", symbol).
+		Str("deviation", deviation.String()).
+		Int64("vote_period", votePeriod).
+		Msg("dry run vote deviation")
+
+	telemetry.SetGaugeWithLabels(
+		[]string{"dry_run", "deviation"},
+		float32(deviation.MustFloat64()),
+		[]metrics.Label{telemetry.NewLabel("denom", symbol)},
+	)
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Reasons why a ticker has not been used for the price of a denom.
+const (
+	AuditDroppedDuplicate  = "duplicate"
+	AuditDroppedDeviation  = "deviation"
+	AuditDroppedSpread     = "max_spread"
+	AuditDroppedQuarantine = "quarantine"
+	AuditDroppedHealth     = "health"
+)
+
+type (
+	// PriceAudits holds the audit trails of all denoms of one price update.
+	PriceAudits struct {
+		Time   time.Time             `json:"time"`
+		Audits map[string]PriceAudit `json:"audits"`
+	}
+
+	// PriceAudit explains how the price of a denom has been computed: the
+	// tickers of all providers converted to USD, the conversions used for
+	// tickers not quoted in USD and the tickers that have been dropped.
+	// Price is nil and Error is set, if no price could be computed. Peg is
+	// set for stablecoins with a peg policy and Fallback, if the last known
+	// good price has been used in place of the price that failed.
+	PriceAudit struct {
+		Denom       string            `json:"denom"`
+		Price       *sdk.Dec          `json:"price,omitempty"`
+		Tickers     []AuditTicker     `json:"tickers"`
+		Conversions []AuditConversion `json:"conversions,omitempty"`
+		Peg         *AuditPeg         `json:"peg,omitempty"`
+		Fallback    *FallbackPrice    `json:"fallback,omitempty"`
+		Warnings    []string          `json:"warnings,omitempty"`
+		Error       string            `json:"error,omitempty"`
+	}
+
+	// AuditTicker is a ticker of a single provider as it went into the price
+	// of a denom. Price and Volume are the values reported by the provider,
+	// Weight is the volume set by the provider weights. USDVolume is the
+	// volume valued at the USD price and CappedVolume the USD volume left
+	// after applying the volume cap of the denom. Score is the reputation of
+	// the provider its USD volume has been multiplied with.
+	AuditTicker struct {
+		Provider     string   `json:"provider"`
+		Symbol       string   `json:"symbol"`
+		Price        sdk.Dec  `json:"price"`
+		Volume       sdk.Dec  `json:"volume"`
+		Weight       *sdk.Dec `json:"weight,omitempty"`
+		QuoteRate    *sdk.Dec `json:"quote_rate,omitempty"`
+		USDPrice     sdk.Dec  `json:"usd_price"`
+		USDVolume    sdk.Dec  `json:"usd_volume"`
+		CappedVolume *sdk.Dec `json:"capped_volume,omitempty"`
+		Score        *sdk.Dec `json:"score,omitempty"`
+		Dropped      string   `json:"dropped,omitempty"`
+	}
+
+	// AuditConversion is the USD rate of a quote denom used to convert the
+	// tickers of a symbol, the paths of the quote to USD it is combined from
+	// and the providers of the first conversion of those paths that have been
+	// used or dropped. Peg is set, if the quote is a pegged stablecoin.
+	AuditConversion struct {
+		Symbol    string      `json:"symbol"`
+		Quote     string      `json:"quote"`
+		Rate      sdk.Dec     `json:"rate"`
+		Paths     []AuditPath `json:"paths"`
+		Providers []string    `json:"providers"`
+		Dropped   []string    `json:"dropped,omitempty"`
+		Peg       *AuditPeg   `json:"peg,omitempty"`
+	}
+
+	// AuditPath is a path of conversions from a quote to USD, e.g.
+	// [ATOM, OSMO, USDC, USD]. Sources is the lowest number of providers of
+	// all conversions and liquidity the USD volume of the first conversion.
+	AuditPath struct {
+		Denoms    []string `json:"denoms"`
+		Rate      sdk.Dec  `json:"rate"`
+		Sources   int      `json:"sources"`
+		Liquidity sdk.Dec  `json:"liquidity"`
+	}
+)
+
+// Filter returns the audits of the given denom only.
+func (a PriceAudits) Filter(denom string) PriceAudits {
+	filtered := PriceAudits{
+		Time:   a.Time,
+		Audits: map[string]PriceAudit{},
+	}
+
+	if audit, found := a.Audits[denom]; found {
+		filtered.Audits[denom] = audit
+	}
+
+	return filtered
+}
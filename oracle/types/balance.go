@@ -0,0 +1,29 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// FeederBalance defines the funds available to pay the fees of the feeder
+// and the estimated number of days they last.
+type FeederBalance struct {
+	Feeder     string `json:"feeder"`
+	FeeGranter string `json:"fee_granter,omitempty"`
+	Denom      string `json:"denom"`
+	// Balance is the balance of the fee payer, which is the fee granter if
+	// configured.
+	Balance sdk.Int `json:"balance"`
+	// Allowance is the remaining spend limit of the fee allowance, it's nil
+	// if there's no fee granter or no spend limit. For periodic allowances
+	// it's limited to what can be spent in the current period, which ends
+	// at AllowancePeriodReset.
+	Allowance            *sdk.Int   `json:"allowance,omitempty"`
+	AllowanceExpiration  *time.Time `json:"allowance_expiration,omitempty"`
+	AllowancePeriodReset *time.Time `json:"allowance_period_reset,omitempty"`
+	// FeesPerDay is estimated from the fees paid within the last day.
+	FeesPerDay    *sdk.Dec  `json:"fees_per_day,omitempty"`
+	RemainingDays *float64  `json:"remaining_days,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
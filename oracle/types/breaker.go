@@ -0,0 +1,29 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// BreakerActionWithhold doesn't vote on a denom while its breaker is
+	// tripped.
+	BreakerActionWithhold = "withhold"
+	// BreakerActionLastPrice votes the last accepted price of a denom while
+	// its breaker is tripped.
+	BreakerActionLastPrice = "last_price"
+)
+
+// CircuitBreaker trips, if the price of a denom moves more than MaxChange
+// percent within one price update. The move is accepted once it has lasted
+// for Confirmations consecutive price updates or is confirmed by providers
+// of different source classes. Price updates less than ConfirmationInterval
+// after the last confirmation don't count, so the confirmations span about
+// as many vote periods, no matter how often the prices are updated.
+type CircuitBreaker struct {
+	MaxChange            sdk.Dec
+	Confirmations        int
+	ConfirmationInterval time.Duration
+	Action               string
+}
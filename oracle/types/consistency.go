@@ -0,0 +1,42 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// ConsistencyActionLog only logs inconsistent cross rates.
+	ConsistencyActionLog = "log"
+	// ConsistencyActionDrop drops the pair or withholds the denom most
+	// likely causing an inconsistent cross rate.
+	ConsistencyActionDrop = "drop"
+)
+
+type (
+	// Consistency checks that the cross rate of every pair not quoted in USD
+	// agrees with the rate implied by the USD prices of its base and quote
+	// within Tolerance percent.
+	Consistency struct {
+		Tolerance sdk.Dec
+		Action    string
+	}
+
+	// Inconsistency is a pair whose observed cross rate deviates from the
+	// implied rate. Culprit is the pair or denom most likely at fault and
+	// Provider the provider of the culprit deviating the most.
+	Inconsistency struct {
+		Symbol    string
+		Base      string
+		Quote     string
+		Observed  sdk.Dec
+		Implied   sdk.Dec
+		Deviation sdk.Dec
+		Culprit   string
+		Provider  string
+	}
+)
+
+// Enabled returns whether cross rates are checked at all.
+func (c Consistency) Enabled() bool {
+	return !c.Tolerance.IsNil() && c.Tolerance.IsPositive()
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// DeviationStdDev filters prices more than threshold standard
+	// deviations away from the mean.
+	DeviationStdDev = "stddev"
+	// DeviationMAD filters prices more than threshold median absolute
+	// deviations away from the median.
+	DeviationMAD = "mad"
+	// DeviationPercent filters prices more than threshold percent away from
+	// the median.
+	DeviationPercent = "percent"
+)
+
+// Deviation defines how deviating provider prices of a denom are filtered.
+// If there are only two prices and MaxSpread is set, both prices are
+// accepted as long as they are within MaxSpread percent of each other.
+type Deviation struct {
+	Method    string
+	Threshold sdk.Dec
+	MaxSpread sdk.Dec
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type (
+	// Fallback allows voting the last successfully computed price of a denom
+	// while no price can be computed, as long as it is younger than MaxAge.
+	// An alert is raised once the price has been used for AlertAfter
+	// consecutive price updates.
+	Fallback struct {
+		MaxAge     time.Duration
+		AlertAfter int
+	}
+
+	// FallbackPrice is the last successfully computed price of a denom. Time
+	// is when it has been computed and Uses the number of consecutive price
+	// updates it has been used in since.
+	FallbackPrice struct {
+		Denom string    `json:"denom"`
+		Price sdk.Dec   `json:"price"`
+		Time  time.Time `json:"time"`
+		Uses  int       `json:"uses"`
+	}
+)
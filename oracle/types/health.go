@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+)
+
+// States of the health of a provider.
+const (
+	// HealthHealthy providers poll successfully.
+	HealthHealthy = "healthy"
+	// HealthDegraded providers failed at least DegradeAfter consecutive
+	// polls, but are still used.
+	HealthDegraded = "degraded"
+	// HealthQuarantined providers failed for QuarantineAfter. They are polled
+	// once per ProbeInterval and not used for prices.
+	HealthQuarantined = "quarantined"
+	// HealthRecovering providers succeeded a probe while quarantined. They
+	// are used again and become healthy after succeeding for RecoverAfter.
+	HealthRecovering = "recovering"
+)
+
+type (
+	// Health defines when providers are considered degraded, quarantined
+	// and recovered. A poll fails, if the provider returns an error, times
+	// out or more than half of its pairs are missing, stale or dropped by
+	// the deviation filter.
+	Health struct {
+		DegradeAfter    int
+		QuarantineAfter time.Duration
+		RecoverAfter    time.Duration
+		ProbeInterval   time.Duration
+	}
+
+	// ProviderHealth is the health of a provider. Since is when it entered
+	// its state, Failures the number of consecutive failed polls and
+	// LastError the reason of the last one.
+	ProviderHealth struct {
+		Provider  string    `json:"provider"`
+		State     string    `json:"state"`
+		Since     time.Time `json:"since"`
+		Failures  int       `json:"failures"`
+		LastError string    `json:"last_error,omitempty"`
+	}
+)
+
+// Enabled returns whether the health of providers is tracked at all.
+func (h Health) Enabled() bool {
+	return h.DegradeAfter > 0
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"time"
+)
+
+// MissCounter defines the miss counter of a validator within the current
+// slash window, together with the projected slashing risk.
+type MissCounter struct {
+	Validator string `json:"validator"`
+	Height    int64  `json:"height"`
+	// Misses is the number of missed votes in the current slash window.
+	Misses uint64 `json:"misses"`
+	// VotePeriods is the number of vote periods per slash window.
+	VotePeriods uint64 `json:"vote_periods"`
+	// ElapsedVotePeriods is the number of vote periods elapsed in the current
+	// slash window.
+	ElapsedVotePeriods uint64 `json:"elapsed_vote_periods"`
+	// MaxMisses is the maximum number of misses per slash window, before the
+	// validator gets slashed.
+	MaxMisses uint64 `json:"max_misses"`
+	// ProjectedMisses is the number of misses at the end of the slash window,
+	// if votes keep getting missed at the current rate.
+	ProjectedMisses uint64 `json:"projected_misses"`
+	// SlashingRisk is true, if the projected misses exceed the maximum misses.
+	SlashingRisk bool      `json:"slashing_risk"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// PegModeFixed treats a stablecoin as exactly 1 USD.
+	PegModeFixed = "fixed"
+	// PegModeMarket uses the market price of a stablecoin.
+	PegModeMarket = "market"
+	// PegModeBand uses the market price of a stablecoin, unless it is within
+	// the band around 1 USD.
+	PegModeBand = "band"
+
+	// PegConversionMarket converts prices quoted in a depegged stablecoin
+	// with its market price.
+	PegConversionMarket = "market"
+	// PegConversionPeg keeps converting prices quoted in a depegged
+	// stablecoin at 1 USD.
+	PegConversionPeg = "peg"
+)
+
+type (
+	// Peg is the policy of a stablecoin pegged to 1 USD. Band is the
+	// deviation in percent, within which the band mode falls back to the
+	// peg. A stablecoin is depegged, once its market price deviates more
+	// than DepegThreshold percent from the peg. Its price then follows the
+	// market in every mode, while DepegConversion decides which rate prices
+	// quoted in the stablecoin are converted with.
+	Peg struct {
+		Mode            string
+		Band            sdk.Dec
+		DepegThreshold  sdk.Dec
+		DepegConversion string
+	}
+
+	// AuditPeg records how the peg policy of a stablecoin has been applied.
+	// MarketPrice is nil, if no market price was available.
+	AuditPeg struct {
+		Mode        string   `json:"mode"`
+		MarketPrice *sdk.Dec `json:"market_price,omitempty"`
+		Depegged    bool     `json:"depegged"`
+	}
+)
+
+// Deviation returns how far the market price deviates from the peg in
+// percent.
+func (p Peg) Deviation(market sdk.Dec) sdk.Dec {
+	return market.Sub(sdk.OneDec()).Abs().MulInt64(100)
+}
+
+// Depegged returns whether the market price deviates more than the depeg
+// threshold from the peg. Depegs are not detected without a threshold.
+func (p Peg) Depegged(market sdk.Dec) bool {
+	if p.DepegThreshold.IsNil() || !p.DepegThreshold.IsPositive() {
+		return false
+	}
+
+	return p.Deviation(market).GT(p.DepegThreshold)
+}
+
+// Price returns the USD price of the stablecoin given its market price.
+func (p Peg) Price(market sdk.Dec) sdk.Dec {
+	if p.Depegged(market) {
+		return market
+	}
+
+	switch p.Mode {
+	case PegModeFixed:
+		return sdk.OneDec()
+	case PegModeBand:
+		if !p.Band.IsNil() && p.Deviation(market).LTE(p.Band) {
+			return sdk.OneDec()
+		}
+	}
+
+	return market
+}
+
+// ConversionRate returns the USD rate prices quoted in the stablecoin are
+// converted with given its market price.
+func (p Peg) ConversionRate(market sdk.Dec) sdk.Dec {
+	if p.Depegged(market) {
+		if p.DepegConversion == PegConversionMarket {
+			return market
+		}
+		return sdk.OneDec()
+	}
+
+	return p.Price(market)
+}
+
+// Audit returns the audit of the policy applied to the market price.
+func (p Peg) Audit(market sdk.Dec) AuditPeg {
+	return AuditPeg{
+		Mode:        p.Mode,
+		MarketPrice: &market,
+		Depegged:    p.Depegged(market),
+	}
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type (
+	// Reputation scores providers by how often and how far their prices
+	// deviated from the final price of a denom within Window. Deviations of
+	// more than Tolerance percent count as misses. Providers with less than
+	// MinSamples deviations aren't scored yet, providers scoring below
+	// Quarantine aren't used at all.
+	Reputation struct {
+		Window     time.Duration
+		Tolerance  sdk.Dec
+		Quarantine sdk.Dec
+		MinSamples int
+	}
+
+	// ProviderScore is the reputation of a provider for a denom. The score
+	// ranges from 0 to 1 and is multiplied with the volume of the provider.
+	ProviderScore struct {
+		Denom         string  `json:"denom"`
+		Provider      string  `json:"provider"`
+		Score         sdk.Dec `json:"score"`
+		Samples       int     `json:"samples"`
+		Misses        int     `json:"misses"`
+		MeanDeviation sdk.Dec `json:"mean_deviation"`
+		Quarantined   bool    `json:"quarantined"`
+	}
+)
+
+// Enabled returns whether providers are scored at all.
+func (r Reputation) Enabled() bool {
+	return r.Window > 0
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// VolumeCap limits the share of the total USD volume of a denom, that a
+// single provider can have in volume weighted aggregations. MaxShare is the
+// limit in percent of all providers, Providers overrides it for single
+// providers. A nil MaxShare doesn't limit the providers not overridden.
+type VolumeCap struct {
+	MaxShare  sdk.Dec
+	Providers map[string]sdk.Dec
+}
+
+// Limit returns the maximum share of the provider in percent and whether
+// it is limited at all.
+func (c VolumeCap) Limit(provider string) (sdk.Dec, bool) {
+	if share, found := c.Providers[provider]; found {
+		return share, true
+	}
+
+	if c.MaxShare.IsNil() {
+		return sdk.Dec{}, false
+	}
+
+	return c.MaxShare, true
+}
@@ -2,7 +2,9 @@ package oracle
 
 import (
 	"fmt"
+	"sort"
 
+	"price-feeder/oracle/aggregator"
 	"price-feeder/oracle/provider"
 	"price-feeder/oracle/types"
 
@@ -12,35 +14,8 @@ import (
 // ComputeVWAP computes the volume weighted average price for all tickers.
 // If all tickers report a volume of 0, treat all volumes as 1 and
 // effectively return the average price instead.
-// Ref: https://en.wikipedia.org/wiki/Volume-weighted_average_price
 func ComputeVWAP(tickers []types.TickerPrice) (sdk.Dec, error) {
-	if len(tickers) == 0 {
-		return sdk.Dec{}, fmt.Errorf("no tickers supplied")
-	}
-
-	volumeSum := sdk.ZeroDec()
-
-	for _, tp := range tickers {
-		volumeSum = volumeSum.Add(tp.Volume)
-	}
-
-	weightedPrice := sdk.ZeroDec()
-
-	for _, tp := range tickers {
-		volume := tp.Volume
-		if volumeSum.Equal(sdk.ZeroDec()) {
-			volume = sdk.NewDec(1)
-		}
-
-		// weightedPrice = Σ {P * V} for all TickerPrice
-		weightedPrice = weightedPrice.Add(tp.Price.Mul(volume))
-	}
-
-	if volumeSum.Equal(sdk.ZeroDec()) {
-		volumeSum = sdk.NewDec(int64(len(tickers)))
-	}
-
-	return weightedPrice.Quo(volumeSum), nil
+	return aggregator.ComputeVWAP(tickers)
 }
 
 // StandardDeviation returns standard deviation and mean of assets.
@@ -77,6 +52,53 @@ func StandardDeviation(prices []sdk.Dec) (sdk.Dec, sdk.Dec, error) {
 	return deviation, mean, nil
 }
 
+// Median returns the median of the given prices.
+func Median(prices []sdk.Dec) (sdk.Dec, error) {
+	if len(prices) == 0 {
+		return sdk.Dec{}, fmt.Errorf("no values to calculate median")
+	}
+
+	sorted := make([]sdk.Dec, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LT(sorted[j])
+	})
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], nil
+	}
+
+	return sorted[middle-1].Add(sorted[middle]).QuoInt64(2), nil
+}
+
+// MedianAbsoluteDeviation returns the median absolute deviation and the
+// median of the given prices. Like the standard deviation it needs at
+// least 3 prices to be meaningful.
+func MedianAbsoluteDeviation(prices []sdk.Dec) (sdk.Dec, sdk.Dec, error) {
+	if len(prices) < 3 {
+		err := fmt.Errorf("not enough values to calculate deviation")
+		return sdk.Dec{}, sdk.Dec{}, err
+	}
+
+	median, err := Median(prices)
+	if err != nil {
+		return sdk.Dec{}, sdk.Dec{}, err
+	}
+
+	deviations := make([]sdk.Dec, len(prices))
+	for i, price := range prices {
+		deviations[i] = price.Sub(median).Abs()
+	}
+
+	mad, err := Median(deviations)
+	if err != nil {
+		return sdk.Dec{}, sdk.Dec{}, err
+	}
+
+	return mad, median, nil
+}
+
 func SetWeight(
 	rates map[provider.Name]types.TickerPrice,
 	weight ProviderWeight,
@@ -137,3 +137,24 @@ func TestStandardDeviation(t *testing.T) {
 		})
 	}
 }
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	_, _, err := oracle.MedianAbsoluteDeviation([]sdk.Dec{
+		sdk.MustNewDecFromStr("1"),
+		sdk.MustNewDecFromStr("2"),
+	})
+	require.Error(t, err)
+
+	mad, median, err := oracle.MedianAbsoluteDeviation([]sdk.Dec{
+		sdk.MustNewDecFromStr("4"),
+		sdk.MustNewDecFromStr("1"),
+		sdk.MustNewDecFromStr("2"),
+		sdk.MustNewDecFromStr("2"),
+		sdk.MustNewDecFromStr("100"),
+		sdk.MustNewDecFromStr("3"),
+	})
+	require.NoError(t, err)
+	// median: (2 + 3) / 2, deviations: 1.5 1.5 0.5 0.5 97.5 0.5
+	require.Equal(t, sdk.MustNewDecFromStr("2.5"), median)
+	require.Equal(t, sdk.MustNewDecFromStr("1"), mad)
+}
@@ -0,0 +1,104 @@
+package oracle
+
+import (
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// capVolumes limits the volume of each provider to its maximum share of the
+// total volume. The volume exceeding the limits is redistributed to the
+// remaining providers in proportion to their volumes, which may push them
+// over their limits in turn. If all providers are capped, their volumes
+// are proportional to their limits. It returns the capped tickers and the
+// providers whose volume has been limited.
+func capVolumes(
+	tickers map[provider.Name]types.TickerPrice,
+	volumeCap types.VolumeCap,
+) (map[provider.Name]types.TickerPrice, map[provider.Name]struct{}) {
+	capped := map[provider.Name]struct{}{}
+
+	total := sdk.ZeroDec()
+	for _, ticker := range tickers {
+		total = total.Add(ticker.Volume)
+	}
+
+	if !total.IsPositive() {
+		return tickers, capped
+	}
+
+	limits := map[provider.Name]sdk.Dec{}
+	for providerName := range tickers {
+		share, found := volumeCap.Limit(providerName.String())
+		if found {
+			limits[providerName] = total.Mul(share).QuoInt64(100)
+		}
+	}
+
+	// scaled returns the volume of an uncapped provider, scaled so the
+	// total volume is kept
+	scaled := func() func(sdk.Dec) sdk.Dec {
+		cappedSum := sdk.ZeroDec()
+		uncappedSum := sdk.ZeroDec()
+		for providerName, ticker := range tickers {
+			if _, found := capped[providerName]; found {
+				cappedSum = cappedSum.Add(limits[providerName])
+			} else {
+				uncappedSum = uncappedSum.Add(ticker.Volume)
+			}
+		}
+
+		return func(volume sdk.Dec) sdk.Dec {
+			if !uncappedSum.IsPositive() || cappedSum.GTE(total) {
+				return sdk.ZeroDec()
+			}
+			return volume.Mul(total.Sub(cappedSum)).Quo(uncappedSum)
+		}
+	}
+
+	for range tickers {
+		scale := scaled()
+
+		changed := false
+		for providerName, limit := range limits {
+			if _, found := capped[providerName]; found {
+				continue
+			}
+
+			if scale(tickers[providerName].Volume).GT(limit) {
+				capped[providerName] = struct{}{}
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	if len(capped) == 0 {
+		return tickers, capped
+	}
+
+	scale := scaled()
+
+	cappedTickers := make(map[provider.Name]types.TickerPrice, len(tickers))
+	for providerName, ticker := range tickers {
+		if _, found := capped[providerName]; found {
+			ticker.Volume = limits[providerName]
+		} else {
+			ticker.Volume = scale(ticker.Volume)
+		}
+		cappedTickers[providerName] = ticker
+	}
+
+	return cappedTickers, capped
+}
+
+// notionalVolume returns the ticker with its base volume converted to the
+// notional traded in its quote, which is USD for tickers converted to USD.
+func notionalVolume(ticker types.TickerPrice) types.TickerPrice {
+	ticker.Volume = ticker.Volume.Mul(ticker.Price)
+	return ticker
+}
@@ -0,0 +1,120 @@
+package oracle
+
+import (
+	"testing"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapVolumes(t *testing.T) {
+	testCases := map[string]struct {
+		volumes   map[provider.Name]string
+		volumeCap types.VolumeCap
+		expected  map[provider.Name]string
+		capped    []provider.Name
+	}{
+		"below cap": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "40",
+				provider.ProviderKraken:  "30",
+				provider.ProviderOkx:     "30",
+			},
+			volumeCap: types.VolumeCap{MaxShare: sdk.NewDec(50)},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "40",
+				provider.ProviderKraken:  "30",
+				provider.ProviderOkx:     "30",
+			},
+		},
+		"excess redistributed": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "80",
+				provider.ProviderKraken:  "10",
+				provider.ProviderOkx:     "10",
+			},
+			volumeCap: types.VolumeCap{MaxShare: sdk.NewDec(50)},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "50",
+				provider.ProviderKraken:  "25",
+				provider.ProviderOkx:     "25",
+			},
+			capped: []provider.Name{provider.ProviderBinance},
+		},
+		"redistribution exceeds cap": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "70",
+				provider.ProviderKraken:  "25",
+				provider.ProviderOkx:     "5",
+			},
+			volumeCap: types.VolumeCap{MaxShare: sdk.NewDec(40)},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "40",
+				provider.ProviderKraken:  "40",
+				provider.ProviderOkx:     "20",
+			},
+			capped: []provider.Name{provider.ProviderBinance, provider.ProviderKraken},
+		},
+		"provider cap": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "50",
+				provider.ProviderKraken:  "50",
+			},
+			volumeCap: types.VolumeCap{
+				Providers: map[string]sdk.Dec{"binance": sdk.NewDec(10)},
+			},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "10",
+				provider.ProviderKraken:  "90",
+			},
+			capped: []provider.Name{provider.ProviderBinance},
+		},
+		"all capped": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "60",
+				provider.ProviderKraken:  "40",
+			},
+			volumeCap: types.VolumeCap{MaxShare: sdk.NewDec(25)},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "25",
+				provider.ProviderKraken:  "25",
+			},
+			capped: []provider.Name{provider.ProviderBinance, provider.ProviderKraken},
+		},
+		"no volume": {
+			volumes: map[provider.Name]string{
+				provider.ProviderBinance: "0",
+				provider.ProviderKraken:  "0",
+			},
+			volumeCap: types.VolumeCap{MaxShare: sdk.NewDec(25)},
+			expected: map[provider.Name]string{
+				provider.ProviderBinance: "0",
+				provider.ProviderKraken:  "0",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tickers := map[provider.Name]types.TickerPrice{}
+			for providerName, volume := range tc.volumes {
+				tickers[providerName] = testTicker("1", volume)
+			}
+
+			capped, cappedProviders := capVolumes(tickers, tc.volumeCap)
+
+			require.Len(t, capped, len(tc.expected))
+			for providerName, volume := range tc.expected {
+				require.Equal(t, sdk.MustNewDecFromStr(volume), capped[providerName].Volume, providerName)
+			}
+
+			require.Len(t, cappedProviders, len(tc.capped))
+			for _, providerName := range tc.capped {
+				require.Contains(t, cappedProviders, providerName)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+
+	"price-feeder/config"
+	"price-feeder/oracle/client"
+	"price-feeder/oracle/history"
+	"price-feeder/oracle/types"
+
+	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// Voter submits the prices of the oracle on behalf of a single validator. Each
+// voter has its own oracle client and prevote state, so several validators,
+// even on different chains, can be fed by the same oracle.
+type Voter struct {
+	logger         zerolog.Logger
+	oracle         *Oracle
+	oracleClient   client.OracleClient
+	healthchecks   map[string]http.Client
+	missMonitor    MissMonitor
+	balanceMonitor BalanceMonitor
+
+	previousPrevote    *PreviousPrevote
+	previousVotePeriod float64
+	paramCache         ParamCache
+	votes              map[int64]*VoteSnapshot
+
+	// wg tracks the monitoring routines
+	wg sync.WaitGroup
+
+	mtx         sync.RWMutex
+	missCounter *types.MissCounter
+	balance     *types.FeederBalance
+}
+
+// NewVoter creates a new voter for the given oracle client and registers it
+// with the oracle.
+func (o *Oracle) NewVoter(
+	oc client.OracleClient,
+	healthchecksConfig []config.Healthchecks,
+	missMonitor MissMonitor,
+	balanceMonitor BalanceMonitor,
+) *Voter {
+	logger := o.logger.With().
+		Str("chain_id", oc.ChainID).
+		Str("validator", oc.ValidatorAddrString).
+		Logger()
+
+	healthchecks := make(map[string]http.Client, len(healthchecksConfig))
+	for _, healthcheck := range healthchecksConfig {
+		timeout, err := time.ParseDuration(healthcheck.Timeout)
+		if err != nil {
+			logger.Warn().
+				Str("timeout", healthcheck.Timeout).
+				Msg("failed to parse healthcheck timeout, skipping configuration")
+		} else {
+			healthchecks[healthcheck.URL] = http.Client{
+				Timeout: timeout,
+			}
+		}
+	}
+
+	v := &Voter{
+		logger:          logger,
+		oracle:          o,
+		oracleClient:    oc,
+		healthchecks:    healthchecks,
+		missMonitor:     missMonitor,
+		balanceMonitor:  balanceMonitor,
+		previousPrevote: nil,
+		paramCache:      ParamCache{},
+		votes:           make(map[int64]*VoteSnapshot),
+	}
+
+	o.voters = append(o.voters, v)
+
+	return v
+}
+
+// Start starts the vote loop of the voter in a blocking fashion.
+func (v *Voter) Start(ctx context.Context) error {
+	v.loadPreviousPrevote()
+
+	defer v.wg.Wait()
+
+	if v.missMonitor.Interval > 0 {
+		v.wg.Add(1)
+		go func() {
+			defer v.wg.Done()
+			v.monitorMissCounter(ctx)
+		}()
+	}
+
+	if v.balanceMonitor.Interval > 0 {
+		v.wg.Add(1)
+		go func() {
+			defer v.wg.Done()
+			v.monitorBalance(ctx)
+		}()
+	}
+
+	var lastHeight int64
+
+	for {
+		height, err := v.oracleClient.ChainHeight.WaitForNewHeight(
+			lastHeight,
+			newBlockTimeout,
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		default:
+			if err != nil {
+				v.logger.Warn().
+					Err(err).
+					Int64("height", lastHeight).
+					Msg("waiting for new block")
+				continue
+			}
+
+			lastHeight = height
+
+			v.logger.Debug().
+				Int64("height", height).
+				Msg("starting oracle tick")
+
+			startTime := time.Now()
+
+			if err := v.tick(ctx); err != nil {
+				telemetry.IncrCounter(1, "failure", "tick")
+				v.logger.Err(err).Msg("oracle tick failed")
+			}
+
+			telemetry.MeasureSince(startTime, "runtime", "tick")
+			telemetry.IncrCounter(1, "new", "tick")
+		}
+	}
+}
+
+// GetParamCache returns the last updated parameters of the x/oracle module
+// if the current ParamCache is outdated, we will query it again.
+func (v *Voter) GetParamCache(ctx context.Context, currentBlockHeigh int64) (oracletypes.Params, error) {
+	if !v.paramCache.IsOutdated(currentBlockHeigh) {
+		return *v.paramCache.params, nil
+	}
+
+	params, err := v.GetParams(ctx)
+	if err != nil {
+		return oracletypes.Params{}, err
+	}
+
+	v.checkWhitelist(v.paramCache.params, params)
+	v.paramCache.Update(currentBlockHeigh, params)
+	return params, nil
+}
+
+// GetParams returns the current on-chain parameters of the x/oracle module.
+func (v *Voter) GetParams(ctx context.Context) (oracletypes.Params, error) {
+	grpcConn, err := v.dialGRPC()
+	if err != nil {
+		return oracletypes.Params{}, err
+	}
+
+	defer grpcConn.Close()
+	queryClient := oracletypes.NewQueryClient(grpcConn)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queryResponse, err := queryClient.Params(ctx, &oracletypes.QueryParamsRequest{})
+	if err != nil {
+		return oracletypes.Params{}, fmt.Errorf("failed to get x/oracle params: %w", err)
+	}
+
+	return queryResponse.Params, nil
+}
+
+// GetExchangeRates returns the current on-chain exchange rates of the
+// x/oracle module.
+func (v *Voter) GetExchangeRates(ctx context.Context) (sdk.DecCoins, error) {
+	grpcConn, err := v.dialGRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	defer grpcConn.Close()
+	queryClient := oracletypes.NewQueryClient(grpcConn)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queryResponse, err := queryClient.ExchangeRates(ctx, &oracletypes.QueryExchangeRatesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get x/oracle exchange rates: %w", err)
+	}
+
+	return queryResponse.ExchangeRates, nil
+}
+
+func (v *Voter) dialGRPC() (*grpc.ClientConn, error) {
+	grpcConn, err := grpc.Dial(
+		v.oracleClient.GRPCEndpoint,
+		// the Cosmos SDK doesn't support any transport security mechanism
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(dialerFunc),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Cosmos gRPC service: %w", err)
+	}
+
+	return grpcConn, nil
+}
+
+func (v *Voter) tick(ctx context.Context) error {
+	v.logger.Info().Msg("executing oracle tick")
+
+	// Create and start all provider routines immediately
+	v.oracle.initProviders(ctx)
+
+	blockHeight, err := v.oracleClient.ChainHeight.GetChainHeight()
+	if err != nil {
+		return err
+	}
+	if blockHeight < 1 {
+		return fmt.Errorf("expected positive block height")
+	}
+
+	oracleParams, err := v.GetParamCache(ctx, blockHeight)
+	if err != nil {
+		return err
+	}
+
+	// Get oracle vote period, next block height, current vote period, and index
+	// in the vote period.
+	oracleVotePeriod := int64(oracleParams.VotePeriod)
+	nextBlockHeight := blockHeight + 1
+	currentVotePeriod := math.Floor(float64(nextBlockHeight) / float64(oracleVotePeriod))
+	indexInVotePeriod := nextBlockHeight % oracleVotePeriod
+
+	v.logger.Debug().
+		Int64("vote_period", oracleVotePeriod).
+		Float64("previous_vote_period", v.previousVotePeriod).
+		Float64("current_vote_period", currentVotePeriod).
+		Int64("indexInVotePeriod", indexInVotePeriod).
+		Msg("")
+
+	// Skip until new voting period. Specifically, skip when:
+	// index [0, oracleVotePeriod - 1] > oracleVotePeriod - 2 OR index is 0
+	if (v.previousVotePeriod != 0 && currentVotePeriod == v.previousVotePeriod) ||
+		(indexInVotePeriod > 0 && oracleVotePeriod-indexInVotePeriod > 4) {
+		// oracleVotePeriod-indexInVotePeriod < 2 || (indexInVotePeriod > 0 && indexInVotePeriod < int64(float64(oracleVotePeriod)*0.75)) {
+		v.logger.Info().
+			Msg("skipping until next voting period")
+
+		return nil
+	}
+
+	if err := v.oracle.syncPrices(ctx); err != nil {
+		return err
+	}
+
+	v.checkVotes(ctx, int64(currentVotePeriod), oracleParams.RewardBand)
+
+	// If we're past the voting period we needed to hit, reset and submit another
+	// prevote.
+	if v.previousVotePeriod != 0 && currentVotePeriod-v.previousVotePeriod != 1 {
+		v.logger.Info().
+			Msg("missing vote during voting period")
+		telemetry.IncrCounter(1, "vote", "failure", "missed")
+
+		v.resetPreviousPrevote()
+	}
+
+	salt, err := GenerateSalt(32)
+	if err != nil {
+		return err
+	}
+
+	valAddr, err := sdk.ValAddressFromBech32(v.oracleClient.ValidatorAddrString)
+	if err != nil {
+		return err
+	}
+
+	// only whitelisted denoms are voted on
+	prices := FilterWhitelist(v.oracle.GetPrices(), oracleParams.Whitelist)
+
+	exchangeRatesStr := GenerateExchangeRatesString(prices)
+	hash := oracletypes.GetAggregateVoteHash(salt, exchangeRatesStr, valAddr)
+	preVoteMsg := &oracletypes.MsgAggregateExchangeRatePrevote{
+		Hash:      hash.String(), // hash of prices from the oracle
+		Feeder:    v.oracleClient.OracleAddrString,
+		Validator: valAddr.String(),
+	}
+
+	isPrevoteOnlyTx := v.previousPrevote == nil
+	if isPrevoteOnlyTx {
+		// This timeout could be as small as oracleVotePeriod-indexInVotePeriod,
+		// but we give it some extra time just in case.
+		//
+		// Ref : https://github.com/terra-money/oracle-feeder/blob/baef2a4a02f57a2ffeaa207932b2e03d7fb0fb25/feeder/src/vote.ts#L222
+		v.logger.Info().
+			Str("hash", hash.String()).
+			Str("validator", preVoteMsg.Validator).
+			Str("feeder", preVoteMsg.Feeder).
+			Msg("broadcasting pre-vote")
+		if err := v.broadcastTx(nextBlockHeight, oracleVotePeriod*2, preVoteMsg); err != nil {
+			return err
+		}
+	} else {
+		// otherwise, we're in the next voting period and thus we vote. The
+		// prevote for the current period is sent along in the same tx, so
+		// we get a vote in every voting period. The vote must come first,
+		// as it removes the previous prevote on-chain.
+		voteMsg := &oracletypes.MsgAggregateExchangeRateVote{
+			Salt:          v.previousPrevote.Salt,
+			ExchangeRates: v.previousPrevote.ExchangeRates,
+			Feeder:        v.oracleClient.OracleAddrString,
+			Validator:     valAddr.String(),
+		}
+
+		v.logger.Info().
+			Str("exchange_rates", voteMsg.ExchangeRates).
+			Str("hash", hash.String()).
+			Str("validator", voteMsg.Validator).
+			Str("feeder", voteMsg.Feeder).
+			Msg("broadcasting vote and pre-vote")
+		if err := v.broadcastTx(
+			nextBlockHeight,
+			oracleVotePeriod-indexInVotePeriod,
+			voteMsg,
+			preVoteMsg,
+		); err != nil {
+			return err
+		}
+
+		if !v.oracleClient.DryRun {
+			v.healthchecksPing()
+		}
+
+		v.markVoteRevealed(int64(currentVotePeriod) - 1)
+	}
+
+	currentHeight, err := v.oracleClient.ChainHeight.GetChainHeight()
+	if err != nil {
+		return err
+	}
+
+	v.recordVote(int64(currentVotePeriod), prices)
+
+	v.setPreviousPrevote(
+		&PreviousPrevote{
+			Salt:              salt,
+			ExchangeRates:     exchangeRatesStr,
+			SubmitBlockHeight: currentHeight,
+		},
+		math.Floor(float64(currentHeight)/float64(oracleVotePeriod)),
+	)
+
+	return nil
+}
+
+// loadPreviousPrevote restores the prevote journaled in the history db, so
+// it can still be revealed after a restart. Whether the prevote is still
+// valid is decided in the next tick, based on the current vote period.
+func (v *Voter) loadPreviousPrevote() {
+	if v.oracleClient.DryRun {
+		return
+	}
+
+	prevote, found, err := v.oracle.history.GetPrevote(
+		v.oracleClient.ChainID,
+		v.oracleClient.ValidatorAddrString,
+	)
+	if err != nil {
+		v.logger.Err(err).Msg("failed to load previous prevote")
+		return
+	}
+
+	if !found {
+		return
+	}
+
+	v.previousPrevote = &PreviousPrevote{
+		Salt:              prevote.Salt,
+		ExchangeRates:     prevote.ExchangeRates,
+		SubmitBlockHeight: prevote.SubmitBlockHeight,
+	}
+	v.previousVotePeriod = float64(prevote.VotePeriod)
+
+	v.logger.Info().
+		Int64("submit_height", prevote.SubmitBlockHeight).
+		Int64("vote_period", prevote.VotePeriod).
+		Msg("restored previous prevote")
+}
+
+// setPreviousPrevote sets the prevote to be revealed in the next vote period
+// and journals it in the history db.
+func (v *Voter) setPreviousPrevote(prevote *PreviousPrevote, votePeriod float64) {
+	v.previousPrevote = prevote
+	v.previousVotePeriod = votePeriod
+
+	if v.oracleClient.DryRun {
+		return
+	}
+
+	// failing to journal the prevote only matters on restarts, the error
+	// is already logged by the history db
+	_ = v.oracle.history.SetPrevote(history.Prevote{
+		ChainID:           v.oracleClient.ChainID,
+		Validator:         v.oracleClient.ValidatorAddrString,
+		Salt:              prevote.Salt,
+		ExchangeRates:     prevote.ExchangeRates,
+		SubmitBlockHeight: prevote.SubmitBlockHeight,
+		VotePeriod:        int64(votePeriod),
+	})
+}
+
+// resetPreviousPrevote discards the previous prevote, because its vote period
+// has passed without it being revealed.
+func (v *Voter) resetPreviousPrevote() {
+	v.previousPrevote = nil
+	v.previousVotePeriod = 0
+
+	if v.oracleClient.DryRun {
+		return
+	}
+
+	_ = v.oracle.history.DeletePrevote(
+		v.oracleClient.ChainID,
+		v.oracleClient.ValidatorAddrString,
+	)
+}
+
+func (v *Voter) healthchecksPing() {
+	for url, client := range v.healthchecks {
+		v.logger.Info().Msg("updating healthcheck status")
+		_, err := client.Get(url)
+		if err != nil {
+			v.logger.Warn().Msg("healthcheck ping failed")
+		}
+	}
+}
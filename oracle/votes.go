@@ -0,0 +1,225 @@
+package oracle
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"price-feeder/config"
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+)
+
+// VoteSnapshot holds the prices we prevoted in a vote period, together with
+// the provider prices they have been computed from.
+type VoteSnapshot struct {
+	Prices         sdk.DecCoins
+	ProviderPrices provider.AggregatedProviderPrices
+	Revealed       bool
+}
+
+// ProviderDeviation defines the price of a single provider converted to USD
+// and its deviation from the on-chain exchange rate.
+type ProviderDeviation struct {
+	Provider  provider.Name
+	Pair      string
+	Price     sdk.Dec
+	Deviation sdk.Dec
+}
+
+// recordVote stores the prices prevoted in the given vote period.
+func (v *Voter) recordVote(votePeriod int64, prices sdk.DecCoins) {
+	v.votes[votePeriod] = &VoteSnapshot{
+		Prices:         prices,
+		ProviderPrices: v.oracle.getProviderPrices(),
+	}
+}
+
+// markVoteRevealed marks the prevote of the given vote period as revealed.
+func (v *Voter) markVoteRevealed(votePeriod int64) {
+	if vote, ok := v.votes[votePeriod]; ok {
+		vote.Revealed = true
+	}
+}
+
+// checkVotes compares the prices we voted with the current on-chain exchange
+// rates. A prevote submitted in vote period P gets revealed in P+1 and
+// tallied at the end of P+1, so the rates on chain during vote period P+2 are
+// the ones to compare the prices of period P against. Every denom outside of
+// the reward band is reported with a breakdown of the provider prices.
+func (v *Voter) checkVotes(ctx context.Context, votePeriod int64, rewardBand sdk.Dec) {
+	for period := range v.votes {
+		if period < votePeriod-2 {
+			delete(v.votes, period)
+		}
+	}
+
+	vote, ok := v.votes[votePeriod-2]
+	if !ok || !vote.Revealed {
+		return
+	}
+
+	rates, err := v.GetExchangeRates(ctx)
+	if err != nil {
+		v.logger.Warn().Err(err).Msg("failed to get exchange rates to check votes")
+		return
+	}
+
+	deviations := ComputeVoteDeviations(vote.Prices, rates)
+
+	onChain := make(map[string]sdk.Dec, len(rates))
+	for _, rate := range rates {
+		symbol := strings.ToUpper(rate.Denom)
+		onChain[symbol] = rate.Amount
+
+		if _, ok := deviations[symbol]; !ok {
+			v.logger.Warn().
+				Str("denom", symbol).
+				Msg("vote is missing on-chain denom")
+		}
+	}
+
+	// rates within weighted median * reward band / 2 are always rewarded
+	maxDeviation := rewardBand.QuoInt64(2)
+
+	for symbol, deviation := range deviations {
+		if deviation.IsNil() {
+			v.logger.Warn().
+				Str("denom", symbol).
+				Msg("vote has no on-chain exchange rate")
+			continue
+		}
+
+		labels := []metrics.Label{telemetry.NewLabel("denom", symbol)}
+
+		telemetry.SetGaugeWithLabels(
+			[]string{"vote", "deviation"},
+			float32(deviation.MustFloat64()),
+			labels,
+		)
+
+		if v.oracleClient.DryRun {
+			v.reportDryRunDeviation(symbol, deviation, votePeriod-2)
+		} else {
+			v.logger.Debug().
+				Str("denom", symbol).
+				Str("deviation", deviation.String()).
+				Int64("vote_period", votePeriod-2).
+				Msg("vote deviation")
+		}
+
+		if deviation.LTE(maxDeviation) {
+			continue
+		}
+
+		telemetry.IncrCounterWithLabels([]string{"vote", "outside_reward_band"}, 1, labels)
+
+		rate := onChain[symbol]
+
+		v.logger.Warn().
+			Str("denom", symbol).
+			Str("voted", vote.Prices.AmountOf(symbol).String()).
+			Str("on_chain", rate.String()).
+			Str("deviation", deviation.String()).
+			Str("reward_band", rewardBand.String()).
+			Int64("vote_period", votePeriod-2).
+			Msg("vote outside of reward band")
+
+		breakdown := ComputeProviderDeviations(
+			symbol, rate, vote.Prices, vote.ProviderPrices, v.oracle.providerPairs,
+		)
+		for _, providerDeviation := range breakdown {
+			v.logger.Warn().
+				Str("denom", symbol).
+				Str("provider", providerDeviation.Provider.String()).
+				Str("pair", providerDeviation.Pair).
+				Str("price", providerDeviation.Price.String()).
+				Str("deviation", providerDeviation.Deviation.String()).
+				Msg("provider price of vote outside of reward band")
+		}
+	}
+}
+
+// ComputeVoteDeviations returns the relative deviation of the given prices
+// from the given on-chain exchange rates per (upper case) denom. If there is
+// no on-chain rate for a price, its deviation is nil.
+func ComputeVoteDeviations(prices, rates sdk.DecCoins) map[string]sdk.Dec {
+	onChain := make(map[string]sdk.Dec, len(rates))
+	for _, rate := range rates {
+		onChain[strings.ToUpper(rate.Denom)] = rate.Amount
+	}
+
+	deviations := make(map[string]sdk.Dec, len(prices))
+	for _, price := range prices {
+		symbol := strings.ToUpper(price.Denom)
+
+		rate, ok := onChain[symbol]
+		if !ok || !rate.IsPositive() {
+			deviations[symbol] = sdk.Dec{}
+			continue
+		}
+
+		deviations[symbol] = relativeDeviation(price.Amount, rate)
+	}
+
+	return deviations
+}
+
+// ComputeProviderDeviations converts all provider prices of the given denom
+// to USD, using the voted prices of the quotes, and calculates their
+// deviation from the on-chain rate.
+func ComputeProviderDeviations(
+	denom string,
+	rate sdk.Dec,
+	prices sdk.DecCoins,
+	providerPrices provider.AggregatedProviderPrices,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+) []ProviderDeviation {
+	deviations := []ProviderDeviation{}
+
+	for providerName, pairs := range providerPairs {
+		for _, pair := range pairs {
+			if strings.ToUpper(pair.Base) != denom {
+				continue
+			}
+
+			ticker, ok := providerPrices[providerName][pair.String()]
+			if !ok {
+				continue
+			}
+
+			price := ticker.Price
+			if strings.ToUpper(pair.Quote) != config.DenomUSD {
+				quote := prices.AmountOf(strings.ToUpper(pair.Quote))
+				if !quote.IsPositive() {
+					continue
+				}
+				price = price.Mul(quote)
+			}
+
+			deviations = append(deviations, ProviderDeviation{
+				Provider:  providerName,
+				Pair:      pair.String(),
+				Price:     price,
+				Deviation: relativeDeviation(price, rate),
+			})
+		}
+	}
+
+	sort.Slice(deviations, func(i, j int) bool {
+		if deviations[i].Provider == deviations[j].Provider {
+			return deviations[i].Pair < deviations[j].Pair
+		}
+		return deviations[i].Provider < deviations[j].Provider
+	})
+
+	return deviations
+}
+
+func relativeDeviation(price, rate sdk.Dec) sdk.Dec {
+	return price.Sub(rate).Quo(rate).Abs()
+}
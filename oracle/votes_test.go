@@ -0,0 +1,86 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"price-feeder/oracle/provider"
+	"price-feeder/oracle/types"
+)
+
+func TestComputeVoteDeviations(t *testing.T) {
+	prices := sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10.5")),
+		sdk.NewDecCoinFromDec("KUJI", sdk.MustNewDecFromStr("0.9")),
+		sdk.NewDecCoinFromDec("USK", sdk.MustNewDecFromStr("1")),
+	)
+
+	rates := sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10")),
+		sdk.NewDecCoinFromDec("KUJI", sdk.MustNewDecFromStr("1")),
+	)
+
+	deviations := ComputeVoteDeviations(prices, rates)
+	require.Len(t, deviations, 3)
+	require.Equal(t, sdk.MustNewDecFromStr("0.05"), deviations["ATOM"])
+	require.Equal(t, sdk.MustNewDecFromStr("0.1"), deviations["KUJI"])
+	require.True(t, deviations["USK"].IsNil())
+}
+
+func TestComputeProviderDeviations(t *testing.T) {
+	prices := sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10")),
+		sdk.NewDecCoinFromDec("USDT", sdk.MustNewDecFromStr("0.5")),
+	)
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.ProviderBinance: {
+			{Base: "ATOM", Quote: "USDT"},
+			{Base: "KUJI", Quote: "USDT"},
+		},
+		provider.ProviderCoinbase: {
+			{Base: "ATOM", Quote: "USD"},
+		},
+		provider.ProviderKraken: {
+			{Base: "ATOM", Quote: "EUR"},
+		},
+	}
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.ProviderBinance: {
+			"ATOMUSDT": {Price: sdk.MustNewDecFromStr("22")},
+		},
+		provider.ProviderCoinbase: {
+			"ATOMUSD": {Price: sdk.MustNewDecFromStr("9")},
+		},
+		provider.ProviderKraken: {
+			"ATOMEUR": {Price: sdk.MustNewDecFromStr("9")},
+		},
+	}
+
+	deviations := ComputeProviderDeviations(
+		"ATOM",
+		sdk.MustNewDecFromStr("10"),
+		prices,
+		providerPrices,
+		providerPairs,
+	)
+
+	// kraken is skipped, as there's no price for EUR
+	require.Equal(t, []ProviderDeviation{
+		{
+			Provider:  provider.ProviderBinance,
+			Pair:      "ATOMUSDT",
+			Price:     sdk.MustNewDecFromStr("11"),
+			Deviation: sdk.MustNewDecFromStr("0.1"),
+		},
+		{
+			Provider:  provider.ProviderCoinbase,
+			Pair:      "ATOMUSD",
+			Price:     sdk.MustNewDecFromStr("9"),
+			Deviation: sdk.MustNewDecFromStr("0.1"),
+		},
+	}, deviations)
+}
@@ -0,0 +1,130 @@
+package oracle
+
+import (
+	"sort"
+	"strings"
+
+	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// checkWhitelist reports the changes between the previous and the current
+// whitelist and whether all whitelisted denoms can be priced. It's called
+// every time the param cache is refreshed.
+func (v *Voter) checkWhitelist(previous *oracletypes.Params, params oracletypes.Params) {
+	if previous != nil {
+		added, removed := DiffWhitelist(previous.Whitelist, params.Whitelist)
+
+		for _, symbol := range added {
+			v.logger.Info().
+				Str("denom", symbol).
+				Bool("configured", v.isConfigured(symbol)).
+				Msg("denom added to whitelist")
+		}
+
+		for _, symbol := range removed {
+			v.logger.Info().
+				Str("denom", symbol).
+				Msg("denom removed from whitelist")
+		}
+	}
+
+	prices := make(map[string]struct{})
+	for _, price := range v.oracle.GetPrices() {
+		prices[price.Denom] = struct{}{}
+	}
+
+	whitelist := make(map[string]struct{}, len(params.Whitelist))
+	for _, denom := range params.Whitelist {
+		symbol := strings.ToUpper(denom.Name)
+		whitelist[symbol] = struct{}{}
+
+		if !v.isConfigured(symbol) {
+			v.logger.Error().
+				Str("denom", symbol).
+				Msg("no currency pair configured for whitelisted denom")
+			continue
+		}
+
+		if _, ok := prices[symbol]; !ok {
+			v.logger.Warn().Str("denom", symbol).Msg("price missing for required denom")
+		}
+	}
+
+	for symbol := range prices {
+		if _, ok := whitelist[symbol]; !ok {
+			v.logger.Debug().
+				Str("denom", symbol).
+				Msg("denom not whitelisted, excluded from votes")
+		}
+	}
+}
+
+// isConfigured returns true, if there is a currency pair configured with the
+// given denom as base.
+func (v *Voter) isConfigured(symbol string) bool {
+	for _, pairs := range v.oracle.providerPairs {
+		for _, pair := range pairs {
+			if strings.ToUpper(pair.Base) == symbol {
+				return true
+			}
+		}
+	}
+
+	for _, pairs := range v.oracle.derivativePairs {
+		for _, pair := range pairs {
+			if strings.ToUpper(pair.Base) == symbol {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FilterWhitelist returns only the prices of denoms on the given whitelist.
+func FilterWhitelist(prices sdk.DecCoins, whitelist oracletypes.DenomList) sdk.DecCoins {
+	denoms := make(map[string]struct{}, len(whitelist))
+	for _, denom := range whitelist {
+		denoms[strings.ToUpper(denom.Name)] = struct{}{}
+	}
+
+	filtered := sdk.NewDecCoins()
+	for _, price := range prices {
+		if _, ok := denoms[strings.ToUpper(price.Denom)]; ok {
+			filtered = append(filtered, price)
+		}
+	}
+
+	return filtered
+}
+
+// DiffWhitelist returns the (upper case) denoms added to and removed from
+// the previous whitelist.
+func DiffWhitelist(previous, current oracletypes.DenomList) (added, removed []string) {
+	previousDenoms := make(map[string]struct{}, len(previous))
+	for _, denom := range previous {
+		previousDenoms[strings.ToUpper(denom.Name)] = struct{}{}
+	}
+
+	currentDenoms := make(map[string]struct{}, len(current))
+	for _, denom := range current {
+		symbol := strings.ToUpper(denom.Name)
+		currentDenoms[symbol] = struct{}{}
+
+		if _, ok := previousDenoms[symbol]; !ok {
+			added = append(added, symbol)
+		}
+	}
+
+	for symbol := range previousDenoms {
+		if _, ok := currentDenoms[symbol]; !ok {
+			removed = append(removed, symbol)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
@@ -0,0 +1,53 @@
+package oracle
+
+import (
+	"testing"
+
+	oracletypes "github.com/Team-Kujira/core/x/oracle/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterWhitelist(t *testing.T) {
+	prices := sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10")),
+		sdk.NewDecCoinFromDec("KUJI", sdk.MustNewDecFromStr("1")),
+		sdk.NewDecCoinFromDec("USDT", sdk.MustNewDecFromStr("1")),
+	)
+
+	whitelist := oracletypes.DenomList{
+		{Name: "atom"},
+		{Name: "KUJI"},
+		{Name: "OSMO"},
+	}
+
+	filtered := FilterWhitelist(prices, whitelist)
+	require.Equal(t, sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10")),
+		sdk.NewDecCoinFromDec("KUJI", sdk.MustNewDecFromStr("1")),
+	), filtered)
+
+	require.Empty(t, FilterWhitelist(prices, oracletypes.DenomList{}))
+}
+
+func TestDiffWhitelist(t *testing.T) {
+	previous := oracletypes.DenomList{
+		{Name: "ATOM"},
+		{Name: "KUJI"},
+		{Name: "LUNA"},
+	}
+
+	current := oracletypes.DenomList{
+		{Name: "OSMO"},
+		{Name: "ATOM"},
+		{Name: "DYDX"},
+	}
+
+	added, removed := DiffWhitelist(previous, current)
+	require.Equal(t, []string{"DYDX", "OSMO"}, added)
+	require.Equal(t, []string{"KUJI", "LUNA"}, removed)
+
+	added, removed = DiffWhitelist(current, current)
+	require.Empty(t, added)
+	require.Empty(t, removed)
+}
@@ -4,10 +4,18 @@ import (
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"price-feeder/oracle/types"
 )
 
 // Oracle defines the Oracle interface contract that the v1 router depends on.
 type Oracle interface {
 	GetLastPriceSyncTimestamp() time.Time
 	GetPrices() sdk.DecCoins
+	GetMissCounters() []types.MissCounter
+	GetBalances() []types.FeederBalance
+	GetAudits(denom string) []types.PriceAudits
+	GetProviderScores(denom string) []types.ProviderScore
+	GetFallbackPrices() []types.FallbackPrice
+	GetProviderHealth() []types.ProviderHealth
 }
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"price-feeder/oracle/types"
 )
 
 // Response constants
@@ -22,9 +24,41 @@ type (
 	}
 
 	// PricesResponse defines the response type for getting the latest exchange
-	// rates from the oracle.
+	// rates from the oracle. Fallbacks lists the prices that are last known
+	// good prices, as they could not be computed.
 	PricesResponse struct {
-		Prices map[string]sdk.Dec `json:"prices"`
+		Prices    map[string]sdk.Dec    `json:"prices"`
+		Fallbacks []types.FallbackPrice `json:"fallbacks,omitempty"`
+	}
+
+	// MissCountersResponse defines the response type for getting the
+	// validators' miss counters and slashing risks.
+	MissCountersResponse struct {
+		MissCounters []types.MissCounter `json:"miss_counters"`
+	}
+
+	// BalancesResponse defines the response type for getting the feeders'
+	// balances and the estimated days they last.
+	BalancesResponse struct {
+		Balances []types.FeederBalance `json:"balances"`
+	}
+
+	// AuditsResponse defines the response type for getting the audit trails
+	// of the last price updates.
+	AuditsResponse struct {
+		Audits []types.PriceAudits `json:"audits"`
+	}
+
+	// ReputationResponse defines the response type for getting the
+	// reputation scores of the providers.
+	ReputationResponse struct {
+		Scores []types.ProviderScore `json:"scores"`
+	}
+
+	// ProviderHealthResponse defines the response type for getting the
+	// health states of the providers.
+	ProviderHealthResponse struct {
+		Providers []types.ProviderHealth `json:"providers"`
 	}
 )
 
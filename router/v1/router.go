@@ -70,6 +70,31 @@ func (r *Router) RegisterRoutes(rtr *mux.Router, prefix string) {
 		mChain.ThenFunc(r.pricesHandler()),
 	).Methods(httputil.MethodGET)
 
+	v1Router.Handle(
+		"/miss_counters",
+		mChain.ThenFunc(r.missCountersHandler()),
+	).Methods(httputil.MethodGET)
+
+	v1Router.Handle(
+		"/balances",
+		mChain.ThenFunc(r.balancesHandler()),
+	).Methods(httputil.MethodGET)
+
+	v1Router.Handle(
+		"/audits",
+		mChain.ThenFunc(r.auditsHandler()),
+	).Methods(httputil.MethodGET)
+
+	v1Router.Handle(
+		"/reputation",
+		mChain.ThenFunc(r.reputationHandler()),
+	).Methods(httputil.MethodGET)
+
+	v1Router.Handle(
+		"/provider_health",
+		mChain.ThenFunc(r.providerHealthHandler()),
+	).Methods(httputil.MethodGET)
+
 	if r.cfg.Telemetry.Enabled {
 		v1Router.Handle(
 			"/metrics",
@@ -97,7 +122,62 @@ func (r *Router) pricesHandler() http.HandlerFunc {
 			prices[price.Denom] = price.Amount
 		}
 		resp := PricesResponse{
-			Prices: prices,
+			Prices:    prices,
+			Fallbacks: r.oracle.GetFallbackPrices(),
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (r *Router) missCountersHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resp := MissCountersResponse{
+			MissCounters: r.oracle.GetMissCounters(),
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (r *Router) balancesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resp := BalancesResponse{
+			Balances: r.oracle.GetBalances(),
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (r *Router) auditsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		denom := strings.ToUpper(strings.TrimSpace(req.FormValue("denom")))
+
+		resp := AuditsResponse{
+			Audits: r.oracle.GetAudits(denom),
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (r *Router) reputationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		denom := strings.ToUpper(strings.TrimSpace(req.FormValue("denom")))
+
+		resp := ReputationResponse{
+			Scores: r.oracle.GetProviderScores(denom),
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (r *Router) providerHealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resp := ProviderHealthResponse{
+			Providers: r.oracle.GetProviderHealth(),
 		}
 
 		httputil.RespondWithJSON(w, http.StatusOK, resp)
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"price-feeder/config"
+	"price-feeder/oracle/types"
 	v1 "price-feeder/router/v1"
 
 	"github.com/cosmos/cosmos-sdk/telemetry"
@@ -25,6 +26,99 @@ var (
 		sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("34.84")),
 		sdk.NewDecCoinFromDec("UMEE", sdk.MustNewDecFromStr("4.21")),
 	}
+
+	mockMissCounter = types.MissCounter{
+		Validator:          "kujiravaloper1test",
+		Height:             1000,
+		Misses:             12,
+		VotePeriods:        1000,
+		ElapsedVotePeriods: 100,
+		MaxMisses:          50,
+		ProjectedMisses:    120,
+		SlashingRisk:       true,
+	}
+
+	mockAllowance     = sdk.NewInt(5000000)
+	mockRemainingDays = 12.5
+
+	mockBalance = types.FeederBalance{
+		Feeder:        "kujira1feeder",
+		FeeGranter:    "kujira1granter",
+		Denom:         "ukuji",
+		Balance:       sdk.NewInt(10000000),
+		Allowance:     &mockAllowance,
+		RemainingDays: &mockRemainingDays,
+		UpdatedAt:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	mockAuditPrice = sdk.MustNewDecFromStr("34.84")
+
+	mockFallbackPrices = []types.FallbackPrice{
+		{
+			Denom: "UMEE",
+			Price: sdk.MustNewDecFromStr("4.21"),
+			Time:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Uses:  2,
+		},
+	}
+
+	mockScores = []types.ProviderScore{
+		{
+			Denom:         "ATOM",
+			Provider:      "binance",
+			Score:         sdk.MustNewDecFromStr("0.8"),
+			Samples:       100,
+			Misses:        10,
+			MeanDeviation: sdk.MustNewDecFromStr("0.5"),
+		},
+		{
+			Denom:         "UMEE",
+			Provider:      "kraken",
+			Score:         sdk.MustNewDecFromStr("0.1"),
+			Samples:       100,
+			Misses:        80,
+			MeanDeviation: sdk.MustNewDecFromStr("4.2"),
+			Quarantined:   true,
+		},
+	}
+
+	mockProviderHealth = []types.ProviderHealth{
+		{
+			Provider: "binance",
+			State:    types.HealthHealthy,
+			Since:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Provider:  "kraken",
+			State:     types.HealthQuarantined,
+			Since:     time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC),
+			Failures:  120,
+			LastError: "provider timed out: kraken",
+		},
+	}
+
+	mockAudits = types.PriceAudits{
+		Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Audits: map[string]types.PriceAudit{
+			"ATOM": {
+				Denom: "ATOM",
+				Price: &mockAuditPrice,
+				Tickers: []types.AuditTicker{{
+					Provider:  "binance",
+					Symbol:    "ATOMUSD",
+					Price:     sdk.MustNewDecFromStr("34.84"),
+					Volume:    sdk.MustNewDecFromStr("1000"),
+					USDPrice:  sdk.MustNewDecFromStr("34.84"),
+					USDVolume: sdk.MustNewDecFromStr("34840"),
+				}},
+			},
+			"UMEE": {
+				Denom:   "UMEE",
+				Tickers: []types.AuditTicker{},
+				Error:   "not enough tickers",
+			},
+		},
+	}
 )
 
 type mockOracle struct{}
@@ -37,6 +131,39 @@ func (m mockOracle) GetPrices() sdk.DecCoins {
 	return mockPrices
 }
 
+func (m mockOracle) GetMissCounters() []types.MissCounter {
+	return []types.MissCounter{mockMissCounter}
+}
+
+func (m mockOracle) GetBalances() []types.FeederBalance {
+	return []types.FeederBalance{mockBalance}
+}
+
+func (m mockOracle) GetAudits(denom string) []types.PriceAudits {
+	if denom != "" {
+		return []types.PriceAudits{mockAudits.Filter(denom)}
+	}
+	return []types.PriceAudits{mockAudits}
+}
+
+func (m mockOracle) GetProviderScores(denom string) []types.ProviderScore {
+	scores := []types.ProviderScore{}
+	for _, score := range mockScores {
+		if denom == "" || score.Denom == denom {
+			scores = append(scores, score)
+		}
+	}
+	return scores
+}
+
+func (m mockOracle) GetFallbackPrices() []types.FallbackPrice {
+	return mockFallbackPrices
+}
+
+func (m mockOracle) GetProviderHealth() []types.ProviderHealth {
+	return mockProviderHealth
+}
+
 type mockMetrics struct{}
 
 func (mockMetrics) Gather(format string) (telemetry.GatherResponse, error) {
@@ -102,4 +229,87 @@ func (rts *RouterTestSuite) TestPrices() {
 	rts.Require().Equal(respBody.Prices["ATOM"], mockPrices.AmountOf("ATOM"))
 	rts.Require().Equal(respBody.Prices["UMEE"], mockPrices.AmountOf("UMEE"))
 	rts.Require().Equal(respBody.Prices["FOO"], sdk.Dec{})
+	rts.Require().Equal(mockFallbackPrices, respBody.Fallbacks)
+}
+
+func (rts *RouterTestSuite) TestMissCounters() {
+	req, err := http.NewRequest("GET", "/api/v1/miss_counters", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody v1.MissCountersResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal([]types.MissCounter{mockMissCounter}, respBody.MissCounters)
+}
+
+func (rts *RouterTestSuite) TestBalances() {
+	req, err := http.NewRequest("GET", "/api/v1/balances", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody v1.BalancesResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal([]types.FeederBalance{mockBalance}, respBody.Balances)
+}
+
+func (rts *RouterTestSuite) TestAudits() {
+	req, err := http.NewRequest("GET", "/api/v1/audits", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody v1.AuditsResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal([]types.PriceAudits{mockAudits}, respBody.Audits)
+
+	req, err = http.NewRequest("GET", "/api/v1/audits?denom=atom", nil)
+	rts.Require().NoError(err)
+
+	response = rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	respBody = v1.AuditsResponse{}
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Len(respBody.Audits, 1)
+	rts.Require().Len(respBody.Audits[0].Audits, 1)
+	rts.Require().Equal(mockAudits.Audits["ATOM"], respBody.Audits[0].Audits["ATOM"])
+}
+
+func (rts *RouterTestSuite) TestReputation() {
+	req, err := http.NewRequest("GET", "/api/v1/reputation", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody v1.ReputationResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal(mockScores, respBody.Scores)
+
+	req, err = http.NewRequest("GET", "/api/v1/reputation?denom=umee", nil)
+	rts.Require().NoError(err)
+
+	response = rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	respBody = v1.ReputationResponse{}
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal(mockScores[1:], respBody.Scores)
+}
+
+func (rts *RouterTestSuite) TestProviderHealth() {
+	req, err := http.NewRequest("GET", "/api/v1/provider_health", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody v1.ProviderHealthResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+	rts.Require().Equal(mockProviderHealth, respBody.Providers)
 }